@@ -1,13 +1,20 @@
 package main
 
 import (
+	"bufio"
 	"encoding/hex"
 	"errors"
 	"flag"
 	"fmt"
+	"io"
+	"os"
 	"strconv"
 	"strings"
 	"unicode/utf8"
+
+	"go_tutorials/internal/codepage"
+	"go_tutorials/internal/reverseinput"
+	"go_tutorials/internal/visualiser"
 )
 
 // DecodeCommand handles the `decode` sub-command.
@@ -18,36 +25,139 @@ func NewDecodeCommand() *DecodeCommand {
 	return &DecodeCommand{}
 }
 
+// decodeFlags holds the options of the decode command.
+type decodeFlags struct {
+	hexInput     string
+	binInput     string
+	from         string
+	base64Input  string
+	base32Input  string
+	ascii85Input string
+	qpInput      string
+	literalInput string
+	file         string
+	dump         bool
+}
+
+// flagSet returns the decode command's flag set, storing the options in f.
+func (f *decodeFlags) flagSet() *flag.FlagSet {
+	fs := flag.NewFlagSet("decode", flag.ContinueOnError)
+	fs.StringVar(&f.hexInput, "hex", "", "Hex bytes (space separated or continuous, e.g. '41 73' or '4173')")
+	fs.StringVar(&f.binInput, "bin", "", "Binary bytes (space separated, e.g. '01000001 01110011')")
+	fs.StringVar(&f.from, "from", "", "Decode from a legacy code page instead of UTF-8, e.g. windows-1252")
+	fs.StringVar(&f.base64Input, "base64", "", "Base64 text, standard or URL-safe, padded or not (e.g. 'Y2Fmw6k=')")
+	fs.StringVar(&f.base32Input, "base32", "", "Base32 text (e.g. 'MNQWNQ5J')")
+	fs.StringVar(&f.ascii85Input, "ascii85", "", "Ascii85 text, with or without <~ ~> (e.g. '@prueW;')")
+	fs.StringVar(&f.qpInput, "qp", "", "Quoted-printable text (e.g. 'caf=C3=A9')")
+	fs.StringVar(&f.literalInput, "literal", "", "Byte array literal from C, Go, Python or Java (e.g. '[]byte{0x41, 0x42}' or 'new byte[]{-61, -87}')")
+	fs.StringVar(&f.file, "file", "", "Stream raw bytes from a file instead of a text input ('-' for stdin)")
+	fs.BoolVar(&f.dump, "dump", false, "Read --hex, --file or stdin as a hex dump from xxd, hexdump -C, od or Wireshark")
+	return fs
+}
+
 // Run executes the decode command.
 func (c *DecodeCommand) Run(args []string) error {
-	fs := flag.NewFlagSet("decode", flag.ContinueOnError)
-	hexInput := fs.String("hex", "", "Hex bytes (space separated or continuous, e.g. '41 73' or '4173')")
-	binInput := fs.String("bin", "", "Binary bytes (space separated, e.g. '01000001 01110011')")
+	var flags decodeFlags
+	fs := flags.flagSet()
 	if err := fs.Parse(args); err != nil {
 		return err
 	}
+	if fs.NArg() > 0 {
+		return fmt.Errorf("unexpected argument %q; pass the input with a flag such as --hex, or --dump --hex for a hex dump", fs.Arg(0))
+	}
+
+	show := printDecoded
+	var cp *codepage.CodePage
+	if flags.from != "" {
+		var err error
+		cp, err = codepage.Lookup(flags.from)
+		if err != nil {
+			return err
+		}
+		show = func(bytes []byte) { printDecodedCodePage(bytes, cp) }
+	}
+
+	inputs := []textInput{
+		{"hex", flags.hexInput, parseHexInput},
+		{"bin", flags.binInput, parseBinaryInput},
+		{"base64", flags.base64Input, reverseinput.DecodeBase64},
+		{"base32", flags.base32Input, reverseinput.DecodeBase32},
+		{"ascii85", flags.ascii85Input, reverseinput.DecodeASCII85},
+		{"qp", flags.qpInput, reverseinput.DecodeQuotedPrintable},
+		{"literal", flags.literalInput, reverseinput.ParseByteLiteral},
+	}
+	var given []textInput
+	for _, in := range inputs {
+		if in.value != "" {
+			given = append(given, in)
+		}
+	}
 
 	switch {
-	case *hexInput != "" && *binInput != "":
-		return errors.New("please provide either --hex or --bin, not both")
-	case *hexInput != "":
-		bytes, err := parseHexInput(*hexInput)
+	case flags.dump && len(given) > 0 && given[len(given)-1].flag != "hex":
+		return fmt.Errorf("--dump reads hex dumps; use --hex or --file with it, not --%s", given[len(given)-1].flag)
+	case flags.dump:
+		input, err := readDump(flags.hexInput, flags.file)
 		if err != nil {
 			return err
 		}
-		printDecoded(bytes)
-	case *binInput != "":
-		bytes, err := parseBinaryInput(*binInput)
+		bytes, err := reverseinput.ParseHexDump(input)
+		if err != nil {
+			return pointAt(input, err)
+		}
+		show(bytes)
+	case flags.file != "" && len(given) > 0:
+		return fmt.Errorf("please provide either --file or --%s, not both", given[0].flag)
+	case flags.file == "-":
+		return streamDecoded("stdin", os.Stdin, cp)
+	case flags.file != "":
+		f, err := os.Open(flags.file)
 		if err != nil {
 			return err
 		}
-		printDecoded(bytes)
+		defer f.Close()
+		return streamDecoded(flags.file, f, cp)
+	case len(given) > 1:
+		return fmt.Errorf("please provide either --%s or --%s, not both", given[0].flag, given[1].flag)
+	case len(given) == 1:
+		bytes, err := given[0].parse(given[0].value)
+		if err != nil {
+			return pointAt(given[0].value, err)
+		}
+		show(bytes)
 	default:
-		return errors.New("no input provided; use --hex or --bin")
+		return errors.New("no input provided; use --hex, --bin, --base64, --base32, --ascii85, --qp, --literal, --file or --dump")
 	}
 	return nil
 }
 
+// textInput is one of decode's flags that carries bytes written as text.
+type textInput struct {
+	flag  string
+	value string
+	parse func(string) ([]byte, error)
+}
+
+// readDump returns the hex dump given with --hex, or reads it from path, or
+// from stdin when path is "-" or empty.
+func readDump(hexInput, path string) (string, error) {
+	var (
+		data []byte
+		err  error
+	)
+	switch {
+	case hexInput != "" && path != "":
+		return "", errors.New("please provide either --file or --hex, not both")
+	case hexInput != "":
+		return hexInput, nil
+	case path == "" || path == "-":
+		data, err = io.ReadAll(os.Stdin)
+	default:
+		data, err = os.ReadFile(path)
+	}
+	return string(data), err
+}
+
 // parseHexInput accepts "0x", spaces, or continuous hex bytes and returns raw bytes.
 func parseHexInput(input string) ([]byte, error) {
 	clean := strings.ReplaceAll(input, "0x", "")
@@ -84,11 +194,118 @@ func parseBinaryInput(input string) ([]byte, error) {
 	return bytes, nil
 }
 
-// printDecoded displays the resulting UTF-8 text and warns on invalid sequences.
+// printDecoded displays the resulting UTF-8 text. Invalid sequences are
+// listed with their offsets and reasons alongside the valid runes, and each
+// one is shown as a single U+FFFD in the text.
 func printDecoded(bytes []byte) {
-	if !utf8.Valid(bytes) {
-		fmt.Println("Warning: byte sequence is not valid UTF-8, showing best effort:")
+	results, invalid, err := visualiser.AnalyseBytes(bytes)
+	if err == nil && len(invalid) > 0 {
+		fmt.Printf("Warning: byte sequence is not valid UTF-8 (%d invalid sequence(s)):\n", len(invalid))
+		renderByteBreakdown(results, invalid)
+		fmt.Println("Showing best effort with U+FFFD for invalid sequences:")
 	}
-	fmt.Printf("Decoded UTF-8: %s\n", string(bytes))
+	fmt.Printf("Decoded UTF-8: %s\n", bestEffortText(results, invalid))
 	fmt.Printf("Byte count: %d\n", len(bytes))
 }
+
+// bestEffortText rebuilds the text from AnalyseBytes results, writing one
+// U+FFFD where each invalid sequence was reported.
+func bestEffortText(results []visualiser.Result, invalid []visualiser.InvalidSequence) string {
+	var b strings.Builder
+	for i, res := range results {
+		for len(invalid) > 0 && invalid[0].Index == i {
+			b.WriteRune(utf8.RuneError)
+			invalid = invalid[1:]
+		}
+		b.WriteRune(rune(res.CodePointDec))
+	}
+	for range invalid {
+		b.WriteRune(utf8.RuneError)
+	}
+	return b.String()
+}
+
+// printDecodedCodePage displays bytes decoded from a legacy code page and
+// flags bytes the code page leaves undefined.
+func printDecodedCodePage(bytes []byte, cp *codepage.CodePage) {
+	text, undefined := cp.Decode(bytes)
+	for _, offset := range undefined {
+		fmt.Printf("Warning: byte 0x%02X at offset %d is undefined in %s (shown as U+FFFD)\n", bytes[offset], offset, cp.Name)
+	}
+	fmt.Printf("Decoded %s: %s\n", cp.Name, text)
+	fmt.Printf("Byte count: %d\n", len(bytes))
+}
+
+// streamDecoded copies a file to stdout as decoded text while it is read,
+// keeping memory constant. Malformed UTF-8, or bytes the code page leaves
+// undefined, are shown as U+FFFD and listed after the text.
+func streamDecoded(path string, r io.Reader, cp *codepage.CodePage) error {
+	name := "UTF-8"
+	if cp != nil {
+		name = cp.Name
+	}
+	out := bufio.NewWriter(os.Stdout)
+	fmt.Fprintf(out, "Decoded %s from %s:\n", name, path)
+
+	var (
+		warnings []string
+		problems int
+		total    int
+	)
+	warn := func(format string, args ...any) {
+		if len(warnings) < maxStreamWarnings {
+			warnings = append(warnings, fmt.Sprintf(format, args...))
+		}
+		problems++
+	}
+	if cp == nil {
+		for dr, err := range visualiser.Decode(r) {
+			if err != nil {
+				out.Flush()
+				return fmt.Errorf("reading %s at byte %d: %w", path, dr.Offset, err)
+			}
+			out.WriteRune(dr.Rune)
+			total += len(dr.Bytes)
+			if dr.Reason != "" {
+				warn("Warning: %s at offset %d is not valid UTF-8 (%s)", hexList(dr.Bytes), dr.Offset, dr.Reason)
+			}
+		}
+	} else {
+		buf := make([]byte, 32*1024)
+		for {
+			n, err := r.Read(buf)
+			text, undefined := cp.Decode(buf[:n])
+			out.WriteString(text)
+			for _, offset := range undefined {
+				warn("Warning: byte 0x%02X at offset %d is undefined in %s (shown as U+FFFD)", buf[offset], total+offset, cp.Name)
+			}
+			total += n
+			if err == io.EOF {
+				break
+			}
+			if err != nil {
+				out.Flush()
+				return fmt.Errorf("reading %s at byte %d: %w", path, total, err)
+			}
+		}
+	}
+
+	fmt.Fprintln(out)
+	fmt.Fprintf(out, "Byte count: %d\n", total)
+	for _, w := range warnings {
+		fmt.Fprintln(out, w)
+	}
+	if problems > len(warnings) {
+		fmt.Fprintf(out, "... and %d more\n", problems-len(warnings))
+	}
+	return out.Flush()
+}
+
+// hexList formats bytes as space-separated 0xHH values.
+func hexList(b []byte) string {
+	parts := make([]string, len(b))
+	for i, by := range b {
+		parts[i] = fmt.Sprintf("0x%02X", by)
+	}
+	return strings.Join(parts, " ")
+}
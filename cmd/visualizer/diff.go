@@ -0,0 +1,110 @@
+package main
+
+import (
+	"fmt"
+	"strings"
+
+	"go_tutorials/internal/confusables"
+	"go_tutorials/internal/normalize"
+	"go_tutorials/internal/visualiser"
+)
+
+// maxDiffRunes caps the length of the strings :diff aligns rune by rune.
+const maxDiffRunes = 4096
+
+// diffOp is one row of a rune diff: a rune both strings share, or one that
+// only a or only b has.
+type diffOp struct {
+	kind byte // '=', '-' (only in a) or '+' (only in b)
+	r    rune
+}
+
+// diffRunes aligns a and b along their longest common subsequence of runes.
+func diffRunes(a, b []rune) []diffOp {
+	// lcs[i][j] is the length of the longest common subsequence of a[i:]
+	// and b[j:].
+	lcs := make([][]int, len(a)+1)
+	for i := range lcs {
+		lcs[i] = make([]int, len(b)+1)
+	}
+	for i := len(a) - 1; i >= 0; i-- {
+		for j := len(b) - 1; j >= 0; j-- {
+			if a[i] == b[j] {
+				lcs[i][j] = lcs[i+1][j+1] + 1
+			} else {
+				lcs[i][j] = max(lcs[i+1][j], lcs[i][j+1])
+			}
+		}
+	}
+
+	var ops []diffOp
+	i, j := 0, 0
+	for i < len(a) && j < len(b) {
+		switch {
+		case a[i] == b[j]:
+			ops = append(ops, diffOp{'=', a[i]})
+			i++
+			j++
+		case lcs[i+1][j] >= lcs[i][j+1]:
+			ops = append(ops, diffOp{'-', a[i]})
+			i++
+		default:
+			ops = append(ops, diffOp{'+', b[j]})
+			j++
+		}
+	}
+	for ; i < len(a); i++ {
+		ops = append(ops, diffOp{'-', a[i]})
+	}
+	for ; j < len(b); j++ {
+		ops = append(ops, diffOp{'+', b[j]})
+	}
+	return ops
+}
+
+// renderDiff compares two strings: whether they are the same bytes, the same
+// after normalization or confusable, then the runes that differ.
+func renderDiff(a, b string) error {
+	ra, rb := []rune(a), []rune(b)
+	if len(ra) > maxDiffRunes || len(rb) > maxDiffRunes {
+		return fmt.Errorf("strings longer than %d runes cannot be compared", maxDiffRunes)
+	}
+	fmt.Printf("A: %q (%d runes, %d bytes)\n", a, len(ra), len(a))
+	fmt.Printf("B: %q (%d runes, %d bytes)\n", b, len(rb), len(b))
+	fmt.Printf("Identical bytes:       %s\n", yesNo(a == b))
+	fmt.Printf("Equal after NFC:       %s\n", yesNo(normalize.NFC.Normalize(a) == normalize.NFC.Normalize(b)))
+	fmt.Printf("Equal after NFKC:      %s\n", yesNo(normalize.NFKC.Normalize(a) == normalize.NFKC.Normalize(b)))
+	fmt.Printf("Visually equivalent:   %s\n", yesNo(confusables.Equivalent(a, b)))
+	if a == b {
+		return nil
+	}
+
+	fmt.Println()
+	fmt.Printf("   %-18s  %-18s  %-14s  %s\n", "A", "B", "UTF-8", "Name")
+	fmt.Printf("   %s  %s  %s  %s\n", strings.Repeat("-", 18), strings.Repeat("-", 18), strings.Repeat("-", 14), strings.Repeat("-", 30))
+	for _, op := range diffRunes(ra, rb) {
+		res, _ := visualiser.AnalyseString(string(op.r))
+		cell := fmt.Sprintf("%s %s", res[0].Character, res[0].CodePointHex)
+		left, right := cell, cell
+		switch op.kind {
+		case '-':
+			right = ""
+		case '+':
+			left = ""
+		}
+		fmt.Printf("%c  %s  %s  %-14s  %s\n", op.kind, padWidth(left, 18), padWidth(right, 18), strings.Join(res[0].UTF8BytesHex, " "), res[0].Name)
+	}
+	return nil
+}
+
+// padWidth pads s with spaces to width terminal cells.
+func padWidth(s string, width int) string {
+	return s + strings.Repeat(" ", max(width-visualiser.StringWidth(s), 0))
+}
+
+func yesNo(ok bool) string {
+	if ok {
+		return "yes"
+	}
+	return "no"
+}
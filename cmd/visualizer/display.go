@@ -2,37 +2,460 @@ package main
 
 import (
 	"fmt"
+	"io"
+	"slices"
+	"strconv"
 	"strings"
+	"unicode/utf8"
 
+	"go_tutorials/internal/codepage"
+	"go_tutorials/internal/confusables"
+	"go_tutorials/internal/emoji"
+	"go_tutorials/internal/normalize"
 	"go_tutorials/internal/visualiser"
 )
 
-const (
-	tableHeader  = "Letter           Code Point (dec)  Code Point (hex)  HTML Entity (dec)  HTML Entity (hex)  UTF-8 Hex Bytes        UTF-8 Dec Bytes        Binary Bytes"
-	tableDivider = "--------------  -----------------  ----------------  ------------------  ------------------  --------------------  ---------------------  ------------------------------"
-)
+// column describes one column of the per-character table. key names it in
+// --columns. width is the preset width used when rows are printed as a file
+// is read; otherwise columns are sized to their contents.
+type column struct {
+	key   string
+	title string
+	width int
+	value func(res visualiser.Result) string
+}
+
+// tableOptions selects the optional parts of the per-character table.
+type tableOptions struct {
+	encodings   []visualiser.Encoding
+	codePage    *codepage.CodePage
+	confusables bool
+	positions   bool
+	escapes     []visualiser.Language
+	columns     []string    // --columns keys; empty for the default set
+	counts      map[int]int // occurrences by rune index, set by --unique
+}
+
+// tableColumns returns the table layout for the requested options: the
+// columns named by opts.columns in that order, or by default the columns the
+// other options ask for. The UTF-8 byte columns are shown by default only
+// when UTF-8 is among the encodings.
+func tableColumns(opts tableOptions) ([]column, error) {
+	var all, defaults []column
+	add := func(shown bool, cols ...column) {
+		all = append(all, cols...)
+		if shown {
+			defaults = append(defaults, cols...)
+		}
+	}
+
+	if opts.counts != nil {
+		add(true, column{"count", "Count", 5, func(res visualiser.Result) string { return strconv.Itoa(opts.counts[res.RuneIndex]) }})
+	}
+	add(opts.positions,
+		column{"byte", "Byte", 6, func(res visualiser.Result) string { return strconv.Itoa(res.ByteOffset) }},
+		column{"rune", "Rune", 6, func(res visualiser.Result) string { return strconv.Itoa(res.RuneIndex) }},
+		column{"line", "Line:Col", 9, func(res visualiser.Result) string { return fmt.Sprintf("%d:%d", res.Line, res.ColumnUTF8) }},
+		column{"utf16col", "UTF-16 Col", 10, func(res visualiser.Result) string { return strconv.Itoa(res.ColumnUTF16) }},
+	)
+	add(true,
+		column{"width", "Width", 5, func(res visualiser.Result) string { return strconv.Itoa(visualiser.RuneWidth(rune(res.CodePointDec))) }},
+		column{"dec", "Code Point (dec)", 17, func(res visualiser.Result) string { return strconv.Itoa(res.CodePointDec) }},
+		column{"cp", "Code Point (hex)", 16, func(res visualiser.Result) string { return res.CodePointHex }},
+		column{"html", "HTML Entity (dec)", 18, func(res visualiser.Result) string { return res.HTMLEntityDecimal }},
+		column{"htmlhex", "HTML Entity (hex)", 18, func(res visualiser.Result) string { return res.HTMLEntityHex }},
+	)
+
+	showUTF8, showUnits := false, false
+	for _, enc := range opts.encodings {
+		showUTF8 = showUTF8 || enc == visualiser.UTF8
+		showUnits = showUnits || enc == visualiser.UTF16BE || enc == visualiser.UTF16LE
+	}
+	add(showUTF8,
+		column{"utf8hex", "UTF-8 Hex Bytes", 20, func(res visualiser.Result) string { return strings.Join(res.UTF8BytesHex, " ") }},
+		column{"utf8dec", "UTF-8 Dec Bytes", 21, func(res visualiser.Result) string { return strings.Join(res.UTF8BytesDec, " ") }},
+		column{"binary", "Binary Bytes", 35, func(res visualiser.Result) string { return strings.Join(res.UTF8BytesBinary, " ") }},
+	)
+	add(showUnits,
+		column{"utf16", "UTF-16 Units", 13, func(res visualiser.Result) string { return strings.Join(res.UTF16Units, " ") }},
+		column{"surrogates", "Surrogate Pair", 23, surrogateCell},
+	)
+	for _, enc := range requestedFirst(opts.encodings, visualiser.AllEncodings) {
+		if enc == visualiser.UTF8 {
+			continue
+		}
+		add(slices.Contains(opts.encodings, enc), column{string(enc), enc.Label() + " Bytes", 19, func(res visualiser.Result) string {
+			return strings.Join(res.BytesFor(enc), " ")
+		}})
+	}
+
+	if cp := opts.codePage; cp != nil {
+		add(true, column{"codepage", cp.Name + " Byte", 17, func(res visualiser.Result) string {
+			b, ok := cp.EncodeRune(rune(res.CodePointDec))
+			if !ok {
+				return "unmappable"
+			}
+			return fmt.Sprintf("0x%02X", b)
+		}})
+	}
+
+	add(opts.confusables, column{"looks", "Looks Like", 10, func(res visualiser.Result) string {
+		looks, ok := confusables.LooksLike(rune(res.CodePointDec))
+		if !ok {
+			return ""
+		}
+		return looks
+	}})
+
+	for _, lang := range requestedFirst(opts.escapes, visualiser.AllLanguages) {
+		add(slices.Contains(opts.escapes, lang), column{string(lang), lang.Label() + " Escape", 18, func(res visualiser.Result) string {
+			return res.Escapes[lang]
+		}})
+	}
+
+	add(true,
+		column{"category", "Category", 8, func(res visualiser.Result) string { return res.Category }},
+		column{"script", "Script", 14, func(res visualiser.Result) string { return res.Script }},
+		column{"block", "Block", 28, func(res visualiser.Result) string { return res.Block }},
+		column{"name", "Name", 30, func(res visualiser.Result) string { return res.Name }},
+	)
+
+	if len(opts.columns) == 0 {
+		return defaults, nil
+	}
+	keys := opts.columns
+	if opts.counts != nil && !slices.Contains(keys, "count") {
+		keys = append([]string{"count"}, keys...)
+	}
+	return pickColumns(all, keys)
+}
+
+// requestedFirst lists the requested values in their given order, then the
+// rest of all.
+func requestedFirst[T comparable](requested, all []T) []T {
+	out := slices.Clone(requested)
+	for _, v := range all {
+		if !slices.Contains(requested, v) {
+			out = append(out, v)
+		}
+	}
+	return out
+}
+
+// pickColumns returns the columns named by keys, in that order. "char" names
+// the Letter column, which is always shown first.
+func pickColumns(all []column, keys []string) ([]column, error) {
+	var cols []column
+	for _, key := range keys {
+		key = strings.ToLower(strings.TrimSpace(key))
+		if key == "" || key == "char" {
+			continue
+		}
+		i := slices.IndexFunc(all, func(col column) bool { return col.key == key })
+		if i < 0 {
+			names := []string{"char"}
+			for _, col := range all {
+				names = append(names, col.key)
+			}
+			return nil, fmt.Errorf("unknown column %q (available: %s)", key, strings.Join(names, ", "))
+		}
+		cols = append(cols, all[i])
+	}
+	return cols, nil
+}
+
+func surrogateCell(res visualiser.Result) string {
+	if res.UTF16High == "" {
+		return "-"
+	}
+	return fmt.Sprintf("high %s, low %s", res.UTF16High, res.UTF16Low)
+}
 
 // renderTable prints high-level info followed by the per-character table.
-func renderTable(resolvedText, note string, results []visualiser.Result) {
+func renderTable(resolvedText, note string, results []visualiser.Result, cols []column, style tableStyle, target *rowTarget) {
+	renderHeading(resolvedText, note)
+	rows := make([]tableRow, len(results))
+	for i, res := range results {
+		rows[i] = resultRow(target.label(res, ""), res, cols)
+	}
+	printTable(cols, rows, style)
+}
+
+// renderByteTable prints the per-character table for raw bytes that are not
+// valid UTF-8, with each malformed sequence shown inline where it occurs.
+// invalidCount is the number of malformed sequences in the whole input.
+func renderByteTable(resolvedText, note string, results []visualiser.Result, invalid []visualiser.InvalidSequence, invalidCount int, cols []column, style tableStyle, target *rowTarget) {
+	renderHeading(strings.ToValidUTF8(resolvedText, "\uFFFD"), note)
+	printTable(cols, byteRows(results, invalid, cols, target), style)
+	fmt.Println()
+	fmt.Printf("Warning: %d invalid UTF-8 sequence(s) were not decoded\n", invalidCount)
+}
+
+// byteRows interleaves the rows of decoded runes with those of the malformed
+// sequences found among them.
+func byteRows(results []visualiser.Result, invalid []visualiser.InvalidSequence, cols []column, target *rowTarget) []tableRow {
+	var rows []tableRow
+	next := 0
+	for i := 0; i <= len(results); i++ {
+		for ; next < len(invalid) && invalid[next].Index == i; next++ {
+			rows = append(rows, invalidRow(invalid[next], target))
+		}
+		if i < len(results) {
+			rows = append(rows, resultRow(target.label(results[i], ""), results[i], cols))
+		}
+	}
+	return rows
+}
+
+// renderByteBreakdown lists every decoded rune and malformed sequence of raw
+// bytes with its byte offset.
+func renderByteBreakdown(results []visualiser.Result, invalid []visualiser.InvalidSequence) {
+	offset, next := 0, 0
+	for i := 0; i <= len(results); i++ {
+		for ; next < len(invalid) && invalid[next].Index == i; next++ {
+			inv := invalid[next]
+			fmt.Printf("  offset %-6d %-20s invalid: %s\n", inv.Offset, strings.Join(inv.Bytes, " "), inv.Reason)
+			offset = inv.Offset + len(inv.Bytes)
+		}
+		if i < len(results) {
+			res := results[i]
+			fmt.Printf("  offset %-6d %-20s %s %s\n", offset, strings.Join(res.UTF8BytesHex, " "), res.Character, res.CodePointHex)
+			offset += len(res.UTF8BytesHex)
+		}
+	}
+}
+
+// selectRows returns the results that pass filter in the given order. With
+// counts set, repeated characters collapse into their first occurrence and
+// counts records how often each occurs, by rune index.
+func selectRows(results []visualiser.Result, filter visualiser.Filter, order visualiser.SortOrder, counts map[int]int) []visualiser.Result {
+	view := visualiser.NewView(results, filter, order, counts != nil)
+	rows := make([]visualiser.Result, len(view.Rows))
+	for i, index := range view.Rows {
+		rows[i] = results[index]
+		if counts != nil {
+			counts[rows[i].RuneIndex] = view.Counts[i]
+		}
+	}
+	return rows
+}
+
+// trailingInvalid moves every malformed sequence after the last of n rows,
+// since sorted or filtered rows no longer say where they occurred.
+func trailingInvalid(invalid []visualiser.InvalidSequence, n int) []visualiser.InvalidSequence {
+	out := slices.Clone(invalid)
+	for i := range out {
+		out[i].Index = n
+	}
+	return out
+}
+
+func invalidRow(inv visualiser.InvalidSequence, target *rowTarget) tableRow {
+	return spanRow(target.invalidLabel(inv), fmt.Sprintf("invalid UTF-8 at offset %d: %s (%s)", inv.Offset, strings.Join(inv.Bytes, " "), inv.Reason))
+}
+
+// maxStreamWarnings caps how many warnings a streamed report keeps in memory.
+const maxStreamWarnings = 100
+
+// renderStream prints the per-character table for a file while it is read,
+// so arbitrarily large files are shown without loading them into memory.
+// Columns keep their preset widths, since later rows are not known yet.
+// A non-nil filter hides the runes it rejects; malformed sequences are
+// always shown.
+func renderStream(path string, r io.Reader, cols []column, style tableStyle, filter visualiser.Filter, target *rowTarget) error {
+	fmt.Printf("File: %s\n", path)
+	fmt.Println()
+	layout := fixedLayout(cols, style)
+	layout.printHeader()
+
+	var (
+		warnings        []visualiser.Warning
+		hazards, runes  int
+		invalid, nbytes int
+	)
+	for item, err := range visualiser.Stream(r) {
+		if err != nil {
+			return fmt.Errorf("reading %s at byte %d: %w", path, item.Offset, err)
+		}
+		if inv := item.Invalid; inv != nil {
+			layout.printRow(invalidRow(*inv, target))
+			invalid++
+			nbytes += len(inv.Bytes)
+			continue
+		}
+		res := *item.Result
+		if filter == nil || filter(res) {
+			layout.printRow(resultRow(target.label(res, ""), res, cols))
+		}
+		if res.Hazard != nil {
+			if len(warnings) < maxStreamWarnings {
+				warnings = append(warnings, visualiser.Warning{Index: runes, Result: res})
+			}
+			hazards++
+		}
+		runes++
+		nbytes += len(res.UTF8BytesHex)
+	}
+	layout.printFooter()
+
+	fmt.Println()
+	fmt.Printf("Read %d bytes: %d runes, %d invalid UTF-8 sequence(s)\n", nbytes, runes, invalid)
+	if hazards > 0 {
+		fmt.Println()
+		fmt.Printf("Warnings: %d hidden or control character(s):\n", hazards)
+		for _, w := range warnings {
+			fmt.Printf("  %s\n", w)
+		}
+		if hazards > len(warnings) {
+			fmt.Printf("  ... and %d more\n", hazards-len(warnings))
+		}
+	}
+	return nil
+}
+
+// renderClusterTable prints one parent line per grapheme cluster with its
+// member runes indented beneath it.
+func renderClusterTable(resolvedText, note string, clusters []visualiser.Cluster, cols []column, style tableStyle, target *rowTarget) {
+	renderHeading(resolvedText, note)
+	var rows []tableRow
+	for _, cluster := range clusters {
+		if len(cluster.Runes) == 1 {
+			rows = append(rows, resultRow(target.label(cluster.Runes[0], ""), cluster.Runes[0], cols))
+			continue
+		}
+		byteCount := 0
+		for _, res := range cluster.Runes {
+			byteCount += len(res.UTF8BytesHex)
+		}
+		rows = append(rows, spanRow(cluster.Text, fmt.Sprintf("(grapheme cluster: %d runes, %d bytes)", len(cluster.Runes), byteCount)))
+		for _, res := range cluster.Runes {
+			rows = append(rows, resultRow(target.label(res, "  └ "), res, cols))
+		}
+	}
+	printTable(cols, rows, style)
+}
+
+// renderWarnings lists invisible, control and bidi characters so they cannot
+// hide among the ordinary rows.
+func renderWarnings(results []visualiser.Result) {
+	warnings := visualiser.Warnings(results)
+	if len(warnings) == 0 {
+		return
+	}
+	fmt.Println()
+	fmt.Printf("Warnings: %d hidden or control character(s):\n", len(warnings))
+	for _, w := range warnings {
+		fmt.Printf("  %s\n", w)
+	}
+}
+
+// renderEmoji prints each multi-rune emoji sequence with its name, its
+// ZWJ-joined components and the role of every rune.
+func renderEmoji(text string) {
+	sequences := emoji.Analyse(text, false)
+	if len(sequences) == 0 {
+		return
+	}
+	fmt.Println()
+	fmt.Println("Emoji sequences:")
+	for _, seq := range sequences {
+		fmt.Printf("  %s  %s  [%s]  (from rune %d)\n", seq.Text, seq.Name, strings.Join(seq.Kinds, ", "), seq.RuneIndex)
+		if len(seq.Components) > 0 {
+			fmt.Printf("    = %s\n", strings.Join(seq.Components, " + "))
+		}
+		for _, part := range seq.Parts {
+			fmt.Printf("    └ %-8s %-14s %-36s %s\n", part.CodePoint, part.Character, part.Role, part.Name)
+		}
+	}
+}
+
+// renderCodePageSummary prints the whole string in a legacy code page and
+// counts the characters it cannot represent.
+func renderCodePageSummary(cp *codepage.CodePage, text string) {
+	mappings := cp.Encode(text)
+	parts := make([]string, len(mappings))
+	for i, m := range mappings {
+		if m.OK {
+			parts[i] = fmt.Sprintf("0x%02X", m.Byte)
+		} else {
+			parts[i] = fmt.Sprintf("[%U unmappable]", m.Rune)
+		}
+	}
+	fmt.Println()
+	fmt.Printf("%s bytes: %s\n", cp.Name, strings.Join(parts, " "))
+	if n := codepage.Unmappable(mappings); n > 0 {
+		fmt.Printf("Warning: %d character(s) cannot be represented in %s\n", n, cp.Name)
+	}
+}
+
+// renderEscapes prints the whole string as a literal in each requested
+// syntax, ready to paste into source code.
+func renderEscapes(text string, languages []visualiser.Language) {
+	fmt.Println()
+	fmt.Println("Escaped string:")
+	for _, lang := range languages {
+		fmt.Printf("  %-11s %s\n", lang.Label()+":", visualiser.EscapeString(text, lang))
+	}
+}
+
+// renderTextEncodings prints the bytes of the whole string in each
+// binary-to-text encoding.
+func renderTextEncodings(text string) {
+	if text == "" {
+		return
+	}
+	fmt.Println()
+	fmt.Println("Encoded bytes:")
+	for _, enc := range visualiser.AllTextEncodings {
+		fmt.Printf("  %-17s %s\n", enc.Label()+":", visualiser.EncodeText(text, enc))
+	}
+}
+
+// renderNormalization prints the four Unicode normalization forms of text
+// side by side with their sizes.
+func renderNormalization(text string) {
+	fmt.Println()
+	fmt.Printf("Normalization forms (input: %d runes, %d bytes):\n", utf8.RuneCountInString(text), len(text))
+	fmt.Printf("%-4s  %-5s  %-5s  %-16s  %-20s  %s\n", "Form", "Runes", "Bytes", "Input Normalized", "Text", "Code Points")
+	fmt.Printf("%s  %s  %s  %s  %s  %s\n", strings.Repeat("-", 4), strings.Repeat("-", 5), strings.Repeat("-", 5),
+		strings.Repeat("-", 16), strings.Repeat("-", 20), strings.Repeat("-", 30))
+	for _, cmp := range normalize.Compare(text) {
+		status := "no"
+		if cmp.Normalized {
+			status = "yes"
+		}
+		fmt.Printf("%-4s  %-5d  %-5d  %-16s  %-20s  %s\n", cmp.Form, cmp.Runes, cmp.Bytes, status, cmp.Text, strings.Join(cmp.CodePoints, " "))
+	}
+}
+
+// renderConfusables prints the UTS #39 skeleton, every rune that resembles a
+// Latin character and, when requested, the comparison with a second string.
+func renderConfusables(report confusables.Report) {
+	fmt.Println()
+	fmt.Printf("Skeleton: %q\n", report.Skeleton)
+	if len(report.Flags) == 0 {
+		fmt.Println("No confusable characters found.")
+	} else {
+		fmt.Printf("Warning: %d character(s) look like Latin letters or digits:\n", len(report.Flags))
+		for _, flag := range report.Flags {
+			fmt.Printf("  #%d %s %s (%s) looks like %q (%s)\n", flag.Index+1, flag.Character, flag.CodePoint,
+				flag.Script, flag.LooksLike, flag.LooksLikeName)
+		}
+	}
+	if report.CompareWith != "" {
+		verdict := "no"
+		if report.Equivalent {
+			verdict = "yes"
+		}
+		fmt.Printf("Visually equivalent to %q (skeleton %q): %s\n", report.CompareWith, report.CompareSkeleton, verdict)
+	}
+}
+
+func renderHeading(resolvedText, note string) {
 	fmt.Printf("Name: %s\n", resolvedText)
 	if note != "" {
 		fmt.Printf("  (%s)\n", note)
 	}
 	fmt.Println("This is how a computer represents your name byte-by-byte:")
 	fmt.Println()
-	fmt.Println(tableHeader)
-	fmt.Println(tableDivider)
-
-	for _, res := range results {
-		fmt.Printf("%-14s  %-17d  %-16s  %-18s  %-18s  %-20s  %-21s  %s\n",
-			res.Character,
-			res.CodePointDec,
-			res.CodePointHex,
-			res.HTMLEntityDecimal,
-			res.HTMLEntityHex,
-			strings.Join(res.UTF8BytesHex, " "),
-			strings.Join(res.UTF8BytesDec, " "),
-			strings.Join(res.UTF8BytesBinary, " "),
-		)
-	}
 }
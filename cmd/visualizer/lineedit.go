@@ -0,0 +1,217 @@
+package main
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"slices"
+	"strings"
+	"unicode"
+
+	"go_tutorials/internal/visualiser"
+)
+
+// errInterrupted is returned by readLine when Ctrl-C abandons the line.
+var errInterrupted = errors.New("interrupted")
+
+// maxHistory caps the entries a line editor keeps and loads.
+const maxHistory = 1000
+
+// lineEditor reads lines from a terminal in raw mode. It supports the usual
+// readline keys: arrows, Home/End, Ctrl-A/E/B/F, Backspace/Delete, Ctrl-K/U/W
+// to delete, and Up/Down or Ctrl-P/N to browse the history.
+type lineEditor struct {
+	in      *bufio.Reader
+	out     io.Writer
+	history []string
+}
+
+// readLine shows prompt and returns the line once Enter is pressed. It
+// returns io.EOF for Ctrl-D on an empty line and errInterrupted for Ctrl-C.
+func (e *lineEditor) readLine(prompt string) (string, error) {
+	var (
+		line   []rune
+		pos    int
+		browse = len(e.history) // history entry shown; len(e.history) is the new line
+		draft  []rune           // the new line, kept while browsing
+	)
+	redraw := func() {
+		fmt.Fprintf(e.out, "\r%s%s\x1b[K", prompt, string(line))
+		if tail := visualiser.StringWidth(string(line[pos:])); tail > 0 {
+			fmt.Fprintf(e.out, "\x1b[%dD", tail)
+		}
+	}
+	recall := func(i int) {
+		if browse == len(e.history) {
+			draft = line
+		}
+		browse = i
+		if i == len(e.history) {
+			line = draft
+		} else {
+			line = []rune(e.history[i])
+		}
+		pos = len(line)
+	}
+
+	redraw()
+	for {
+		r, _, err := e.in.ReadRune()
+		if err != nil {
+			return "", err
+		}
+		switch r {
+		case '\r', '\n':
+			fmt.Fprint(e.out, "\r\n")
+			return string(line), nil
+		case 3: // Ctrl-C
+			fmt.Fprint(e.out, "^C\r\n")
+			return "", errInterrupted
+		case 4: // Ctrl-D
+			if len(line) == 0 {
+				fmt.Fprint(e.out, "\r\n")
+				return "", io.EOF
+			}
+			if pos < len(line) {
+				line = slices.Delete(line, pos, pos+1)
+			}
+		case 127, 8: // Backspace, Ctrl-H
+			if pos > 0 {
+				line = slices.Delete(line, pos-1, pos)
+				pos--
+			}
+		case 1: // Ctrl-A
+			pos = 0
+		case 5: // Ctrl-E
+			pos = len(line)
+		case 2: // Ctrl-B
+			pos = max(pos-1, 0)
+		case 6: // Ctrl-F
+			pos = min(pos+1, len(line))
+		case 11: // Ctrl-K
+			line = line[:pos]
+		case 21: // Ctrl-U
+			line = slices.Clone(line[pos:])
+			pos = 0
+		case 23: // Ctrl-W
+			start := pos
+			for start > 0 && unicode.IsSpace(line[start-1]) {
+				start--
+			}
+			for start > 0 && !unicode.IsSpace(line[start-1]) {
+				start--
+			}
+			line = slices.Delete(line, start, pos)
+			pos = start
+		case 16: // Ctrl-P
+			if browse > 0 {
+				recall(browse - 1)
+			}
+		case 14: // Ctrl-N
+			if browse < len(e.history) {
+				recall(browse + 1)
+			}
+		case 27: // Escape sequence
+			switch e.readEscape() {
+			case "A":
+				if browse > 0 {
+					recall(browse - 1)
+				}
+			case "B":
+				if browse < len(e.history) {
+					recall(browse + 1)
+				}
+			case "C":
+				pos = min(pos+1, len(line))
+			case "D":
+				pos = max(pos-1, 0)
+			case "H", "1~", "7~":
+				pos = 0
+			case "F", "4~", "8~":
+				pos = len(line)
+			case "3~":
+				if pos < len(line) {
+					line = slices.Delete(line, pos, pos+1)
+				}
+			}
+		default:
+			if unicode.IsControl(r) || r == unicode.ReplacementChar {
+				continue
+			}
+			line = slices.Insert(line, pos, r)
+			pos++
+		}
+		redraw()
+	}
+}
+
+// readEscape reads the rest of a CSI or SS3 sequence after ESC and returns
+// its parameters and final byte, such as "A" for Up or "3~" for Delete.
+func (e *lineEditor) readEscape() string {
+	kind, err := e.in.ReadByte()
+	if err != nil || (kind != '[' && kind != 'O') {
+		return ""
+	}
+	var seq strings.Builder
+	for {
+		b, err := e.in.ReadByte()
+		if err != nil {
+			return ""
+		}
+		seq.WriteByte(b)
+		if b >= 0x40 && b <= 0x7E {
+			return seq.String()
+		}
+	}
+}
+
+// remember adds line to the history unless it repeats the last entry, and
+// reports whether it was added.
+func (e *lineEditor) remember(line string) bool {
+	if len(e.history) > 0 && e.history[len(e.history)-1] == line {
+		return false
+	}
+	e.history = append(e.history, line)
+	if len(e.history) > maxHistory {
+		e.history = e.history[len(e.history)-maxHistory:]
+	}
+	return true
+}
+
+// loadHistory returns the last maxHistory lines of the history file at
+// path. A missing file is an empty history.
+func loadHistory(path string) ([]string, error) {
+	if path == "" {
+		return nil, nil
+	}
+	data, err := os.ReadFile(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	lines := strings.Split(strings.TrimRight(string(data), "\n"), "\n")
+	if len(lines) == 1 && lines[0] == "" {
+		return nil, nil
+	}
+	return lines[max(len(lines)-maxHistory, 0):], nil
+}
+
+// appendHistory adds line to the history file at path.
+func appendHistory(path, line string) error {
+	if path == "" {
+		return nil
+	}
+	f, err := os.OpenFile(path, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0o600)
+	if err != nil {
+		return err
+	}
+	if _, err := fmt.Fprintln(f, line); err != nil {
+		f.Close()
+		return err
+	}
+	return f.Close()
+}
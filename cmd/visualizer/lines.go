@@ -0,0 +1,150 @@
+package main
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"strconv"
+	"strings"
+
+	"go_tutorials/internal/export"
+	"go_tutorials/internal/visualiser"
+)
+
+// lineReport analyses every line of an input separately for see --lines.
+// Lines are read one at a time, so files of any size use constant memory.
+type lineReport struct {
+	cols    []column
+	style   tableStyle
+	filter  visualiser.Filter
+	order   visualiser.SortOrder
+	counts  map[int]int                          // reused by every line when --unique is set
+	resolve func(string) (string, string, error) // applies --reverse to a line
+	format  export.Format                        // NDJSON, or empty for the sectioned table
+	escapes []visualiser.Language
+}
+
+// lineRecord is one line of the NDJSON report. Items and Invalid keep their
+// positions within the line, except that Line is the input line number.
+type lineRecord struct {
+	Line    int                          `json:"line"`
+	Text    string                       `json:"text"`
+	Items   []visualiser.Result          `json:"items"`
+	Invalid []visualiser.InvalidSequence `json:"invalid,omitempty"`
+	Escaped []export.EscapedString       `json:"escaped,omitempty"`
+}
+
+// lineTotals counts what a --lines report has seen.
+type lineTotals struct {
+	lines, shown, invalid, hidden int
+}
+
+// run reports every line of r. Blank lines, and lines the filter leaves no
+// rows of, are counted but not shown.
+func (l *lineReport) run(r io.Reader) error {
+	var (
+		totals lineTotals
+		enc    = json.NewEncoder(os.Stdout)
+	)
+	reader := bufio.NewReader(r)
+	for {
+		raw, readErr := reader.ReadBytes('\n')
+		if readErr != nil && readErr != io.EOF {
+			return fmt.Errorf("reading line %d: %w", totals.lines+1, readErr)
+		}
+		if len(raw) == 0 && readErr == io.EOF {
+			break
+		}
+		totals.lines++
+		if err := l.line(totals.lines, string(trimLineEnd(raw)), &totals, enc); err != nil {
+			return err
+		}
+		if readErr == io.EOF {
+			break
+		}
+	}
+	if l.format == "" {
+		fmt.Printf("Read %d line(s), showed %d: %d with invalid UTF-8, %d with hidden or control characters\n",
+			totals.lines, totals.shown, totals.invalid, totals.hidden)
+	}
+	return nil
+}
+
+// line analyses and prints line n.
+func (l *lineReport) line(n int, text string, totals *lineTotals, enc *json.Encoder) error {
+	if text == "" {
+		return nil
+	}
+	resolved, note, err := l.resolve(text)
+	if err != nil {
+		return fmt.Errorf("line %d: %w", n, err)
+	}
+	if resolved == "" {
+		return nil
+	}
+	results, invalid, err := visualiser.AnalyseBytes([]byte(resolved))
+	if err != nil {
+		return fmt.Errorf("line %d: %w", n, err)
+	}
+	for i := range results {
+		results[i].Line = n
+	}
+	if len(invalid) > 0 {
+		totals.invalid++
+	}
+	if len(visualiser.Warnings(results)) > 0 {
+		totals.hidden++
+	}
+
+	rows := results
+	if l.filter != nil || l.order != visualiser.SortInput || l.counts != nil {
+		clear(l.counts)
+		rows = selectRows(results, l.filter, l.order, l.counts)
+		invalid = trailingInvalid(invalid, len(rows))
+	}
+	if len(rows) == 0 && len(invalid) == 0 {
+		return nil
+	}
+	totals.shown++
+
+	if l.format == export.NDJSON {
+		valid := strings.ToValidUTF8(resolved, "\uFFFD")
+		return enc.Encode(lineRecord{Line: n, Text: valid, Items: rows, Invalid: invalid, Escaped: export.EscapeAll(valid, l.escapes)})
+	}
+	fmt.Printf("Line %d: %s\n", n, strconv.Quote(resolved))
+	if note != "" {
+		fmt.Printf("  (%s)\n", note)
+	}
+	printTable(l.cols, byteRows(rows, invalid, l.cols, nil), l.style)
+	if len(invalid) > 0 {
+		fmt.Println()
+		fmt.Printf("Warning: %d invalid UTF-8 sequence(s) were not decoded\n", len(invalid))
+	}
+	renderWarnings(results)
+	fmt.Println()
+	return nil
+}
+
+// trimLineEnd drops a trailing LF or CRLF.
+func trimLineEnd(line []byte) []byte {
+	line = bytes.TrimSuffix(line, []byte("\n"))
+	return bytes.TrimSuffix(line, []byte("\r"))
+}
+
+// readStdin returns all of stdin for `see -`, without the final line break
+// that echo and most editors add.
+func readStdin() (string, error) {
+	data, err := io.ReadAll(os.Stdin)
+	if err != nil {
+		return "", fmt.Errorf("reading stdin: %w", err)
+	}
+	input := string(trimLineEnd(data))
+	if input == "" {
+		return "", errors.New("no input on stdin")
+	}
+	return input, nil
+}
@@ -0,0 +1,47 @@
+package main
+
+import (
+	"errors"
+	"flag"
+	"fmt"
+	"strings"
+
+	"go_tutorials/internal/ucd"
+)
+
+// LookupCommand handles the `lookup` sub-command, which searches character
+// names.
+type LookupCommand struct{}
+
+// NewLookupCommand creates a lookup command handler.
+func NewLookupCommand() *LookupCommand {
+	return &LookupCommand{}
+}
+
+// Run executes the lookup command.
+func (c *LookupCommand) Run(args []string) error {
+	fs := flag.NewFlagSet("lookup", flag.ContinueOnError)
+	limit := fs.Int("limit", 20, "Maximum number of matches to list (0 for all)")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	query := strings.TrimSpace(strings.Join(fs.Args(), " "))
+	if query == "" {
+		return errors.New("no search words provided, e.g. lookup arrow")
+	}
+
+	matches := ucd.Search(query, *limit)
+	if len(matches) == 0 {
+		return fmt.Errorf("no characters match %q", query)
+	}
+	fmt.Printf("%-14s  %-9s  %s\n", "Letter", "Code", "Name")
+	fmt.Printf("%s  %s  %s\n", strings.Repeat("-", 14), strings.Repeat("-", 9), strings.Repeat("-", 30))
+	for _, m := range matches {
+		fmt.Printf("%-14s  %-9s  %s\n", fmt.Sprintf("%q", m.Rune), fmt.Sprintf("U+%04X", m.Rune), m.Name)
+	}
+	return nil
+}
+
+func init() {
+	registerCommand("lookup", func() Command { return NewLookupCommand() })
+}
@@ -1,10 +1,19 @@
 package main
 
 import (
+	"bufio"
+	"encoding/json"
 	"io"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
 	"os"
+	"path/filepath"
 	"strings"
 	"testing"
+
+	"go_tutorials/internal/visualiser"
+	"go_tutorials/internal/web"
 )
 
 func captureOutput(t *testing.T, fn func()) string {
@@ -25,6 +34,26 @@ func captureOutput(t *testing.T, fn func()) string {
 	return string(out)
 }
 
+// withStdin runs fn with os.Stdin reading input.
+func withStdin(t *testing.T, input string, fn func()) {
+	t.Helper()
+
+	path := filepath.Join(t.TempDir(), "stdin")
+	if err := os.WriteFile(path, []byte(input), 0o600); err != nil {
+		t.Fatalf("write stdin: %v", err)
+	}
+	f, err := os.Open(path)
+	if err != nil {
+		t.Fatalf("open stdin: %v", err)
+	}
+	defer f.Close()
+	orig := os.Stdin
+	os.Stdin = f
+	defer func() { os.Stdin = orig }()
+
+	fn()
+}
+
 func TestParseHexInput(t *testing.T) {
 	cases := []struct {
 		name   string
@@ -106,3 +135,1003 @@ func TestDispatchUnknownCommand(t *testing.T) {
 		t.Fatalf("expected error for unknown command")
 	}
 }
+
+func TestSeeCommandGraphemes(t *testing.T) {
+	cmd := NewSeeCommand()
+	out := captureOutput(t, func() {
+		if err := cmd.Run([]string{"--graphemes", "--name", "e\u0301!"}); err != nil {
+			t.Fatalf("run error: %v", err)
+		}
+	})
+	if !strings.Contains(out, "grapheme cluster: 2 runes, 3 bytes") {
+		t.Fatalf("expected cluster summary, got %q", out)
+	}
+	if !strings.Contains(out, "  └ 'e'") || !strings.Contains(out, "U+0301") {
+		t.Fatalf("expected nested combining mark row, got %q", out)
+	}
+}
+
+func TestSeeCommandShowsCharacterProperties(t *testing.T) {
+	cmd := NewSeeCommand()
+	out := captureOutput(t, func() {
+		if err := cmd.Run([]string{"--name", "\u200b"}); err != nil {
+			t.Fatalf("run error: %v", err)
+		}
+	})
+	for _, want := range []string{"Cf", "Common", "General Punctuation", "ZERO WIDTH SPACE"} {
+		if !strings.Contains(out, want) {
+			t.Fatalf("expected output to mention %q, got %q", want, out)
+		}
+	}
+}
+
+func TestSeeCommandEncodings(t *testing.T) {
+	cmd := NewSeeCommand()
+	out := captureOutput(t, func() {
+		if err := cmd.Run([]string{"--encodings", "utf16le,utf32be", "--name", "😊"}); err != nil {
+			t.Fatalf("run error: %v", err)
+		}
+	})
+	for _, want := range []string{"UTF-16LE Bytes", "high 0xD83D, low 0xDE0A", "0x3D 0xD8 0x0A 0xDE", "0x00 0x01 0xF6 0x0A"} {
+		if !strings.Contains(out, want) {
+			t.Fatalf("expected output to mention %q, got %q", want, out)
+		}
+	}
+	if strings.Contains(out, "UTF-8 Hex Bytes") {
+		t.Fatalf("expected UTF-8 columns to be hidden, got %q", out)
+	}
+	if err := cmd.Run([]string{"--encodings", "latin1", "--name", "A"}); err == nil {
+		t.Fatalf("expected error for unknown encoding")
+	}
+}
+
+func TestSeeCommandCodePage(t *testing.T) {
+	cmd := NewSeeCommand()
+	out := captureOutput(t, func() {
+		if err := cmd.Run([]string{"--codepage", "windows-1252", "--name", "éж"}); err != nil {
+			t.Fatalf("run error: %v", err)
+		}
+	})
+	for _, want := range []string{"windows-1252 Byte", "0xE9", "unmappable", "windows-1252 bytes: 0xE9 [U+0436 unmappable]"} {
+		if !strings.Contains(out, want) {
+			t.Fatalf("expected output to mention %q, got %q", want, out)
+		}
+	}
+}
+
+func TestDecodeCommandFromCodePage(t *testing.T) {
+	cmd := NewDecodeCommand()
+	out := captureOutput(t, func() {
+		if err := cmd.Run([]string{"--from", "windows-1252", "--hex", "63 61 66 E9 81"}); err != nil {
+			t.Fatalf("run error: %v", err)
+		}
+	})
+	if !strings.Contains(out, "Decoded windows-1252: café") {
+		t.Fatalf("expected decoded text, got %q", out)
+	}
+	if !strings.Contains(out, "byte 0x81 at offset 4 is undefined") {
+		t.Fatalf("expected undefined byte warning, got %q", out)
+	}
+	if err := cmd.Run([]string{"--from", "ebcdic", "--hex", "41"}); err == nil {
+		t.Fatalf("expected error for unknown code page")
+	}
+}
+
+func TestSeeCommandNormalize(t *testing.T) {
+	cmd := NewSeeCommand()
+	out := captureOutput(t, func() {
+		if err := cmd.Run([]string{"--normalize", "--name", "e\u0301"}); err != nil {
+			t.Fatalf("run error: %v", err)
+		}
+	})
+	if !strings.Contains(out, "Normalization forms (input: 2 runes, 3 bytes)") {
+		t.Fatalf("expected normalization summary, got %q", out)
+	}
+	for _, want := range []string{"NFC   1      2      no", "NFD   2      3      yes", "U+0065 U+0301"} {
+		if !strings.Contains(out, want) {
+			t.Fatalf("expected output to contain %q, got %q", want, out)
+		}
+	}
+}
+
+func TestSeeCommandConfusables(t *testing.T) {
+	cmd := NewSeeCommand()
+	out := captureOutput(t, func() {
+		if err := cmd.Run([]string{"--confusable-with", "paypal", "--name", "p\u0430ypal"}); err != nil {
+			t.Fatalf("run error: %v", err)
+		}
+	})
+	for _, want := range []string{
+		"Looks Like",
+		`Skeleton: "paypal"`,
+		"#2 '\u0430' U+0430 (Cyrillic) looks like \"a\" (LATIN SMALL LETTER A)",
+		`Visually equivalent to "paypal" (skeleton "paypal"): yes`,
+	} {
+		if !strings.Contains(out, want) {
+			t.Fatalf("expected output to contain %q, got %q", want, out)
+		}
+	}
+}
+
+func TestSeeCommandWarnings(t *testing.T) {
+	cmd := NewSeeCommand()
+	out := captureOutput(t, func() {
+		if err := cmd.Run([]string{"--name", "a\u202eb"}); err != nil {
+			t.Fatalf("run error: %v", err)
+		}
+	})
+	for _, want := range []string{
+		"Warnings: 1 hidden or control character(s):",
+		"[danger] #2 U+202E RIGHT-TO-LEFT OVERRIDE: bidirectional",
+	} {
+		if !strings.Contains(out, want) {
+			t.Fatalf("expected output to contain %q, got %q", want, out)
+		}
+	}
+	clean := captureOutput(t, func() {
+		if err := cmd.Run([]string{"--name", "ab"}); err != nil {
+			t.Fatalf("run error: %v", err)
+		}
+	})
+	if strings.Contains(clean, "Warnings:") {
+		t.Fatalf("expected no warnings section, got %q", clean)
+	}
+}
+
+func TestDecodeCommandInvalidUTF8(t *testing.T) {
+	cmd := NewDecodeCommand()
+	out := captureOutput(t, func() {
+		if err := cmd.Run([]string{"--hex", "41 C0 AF E2 82"}); err != nil {
+			t.Fatalf("run error: %v", err)
+		}
+	})
+	for _, want := range []string{
+		"not valid UTF-8 (2 invalid sequence(s))",
+		"offset 0      0x41                 'A' U+0041",
+		"offset 1      0xC0 0xAF            invalid: overlong encoding",
+		"offset 3      0xE2 0x82            invalid: truncated sequence",
+	} {
+		if !strings.Contains(out, want) {
+			t.Fatalf("expected output to contain %q, got %q", want, out)
+		}
+	}
+}
+
+func TestDecodeCommandReplacesEachInvalidSequence(t *testing.T) {
+	cmd := NewDecodeCommand()
+	out := captureOutput(t, func() {
+		if err := cmd.Run([]string{"--hex", "41 80 80 42 C0 AF"}); err != nil {
+			t.Fatalf("run error: %v", err)
+		}
+	})
+	// Two stray continuation bytes are two sequences; an overlong pair is one.
+	if want := "Decoded UTF-8: A\uFFFD\uFFFDB\uFFFD\n"; !strings.Contains(out, want) {
+		t.Fatalf("expected output to contain %q, got %q", want, out)
+	}
+}
+
+func TestSeeCommandReverseInvalidBytes(t *testing.T) {
+	cmd := NewSeeCommand()
+	out := captureOutput(t, func() {
+		if err := cmd.Run([]string{"--reverse=bytes", "--name", "0x41 0xED 0xA0 0x80"}); err != nil {
+			t.Fatalf("run error: %v", err)
+		}
+	})
+	for _, want := range []string{
+		"<invalid>  invalid UTF-8 at offset 1: 0xED 0xA0 0x80 (encoded surrogate)",
+		"Warning: 1 invalid UTF-8 sequence(s) were not decoded",
+	} {
+		if !strings.Contains(out, want) {
+			t.Fatalf("expected output to contain %q, got %q", want, out)
+		}
+	}
+	if strings.Contains(out, "U+FFFD") {
+		t.Fatalf("expected no replacement character rows, got %q", out)
+	}
+}
+
+func writeTempFile(t *testing.T, data []byte) string {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), "input.txt")
+	if err := os.WriteFile(path, data, 0o600); err != nil {
+		t.Fatalf("write temp file: %v", err)
+	}
+	return path
+}
+
+func TestSeeCommandFile(t *testing.T) {
+	path := writeTempFile(t, []byte("A😊\xC0\xAF\u202e"))
+	cmd := NewSeeCommand()
+	out := captureOutput(t, func() {
+		if err := cmd.Run([]string{"--file", path}); err != nil {
+			t.Fatalf("run error: %v", err)
+		}
+	})
+	for _, want := range []string{
+		"File: " + path,
+		"U+1F60A",
+		"<invalid>       invalid UTF-8 at offset 5: 0xC0 0xAF (overlong encoding)",
+		"Read 10 bytes: 3 runes, 1 invalid UTF-8 sequence(s)",
+		"[danger] #3 U+202E",
+	} {
+		if !strings.Contains(out, want) {
+			t.Fatalf("expected output to contain %q, got %q", want, out)
+		}
+	}
+	if err := cmd.Run([]string{"--file", path, "--graphemes"}); err == nil {
+		t.Fatalf("expected error combining --file with --graphemes")
+	}
+	if err := cmd.Run([]string{"--file", path, "--name", "x"}); err == nil {
+		t.Fatalf("expected error combining --file with --name")
+	}
+	if err := cmd.Run([]string{"--file", filepath.Join(t.TempDir(), "missing")}); err == nil {
+		t.Fatalf("expected error for missing file")
+	}
+}
+
+func TestDecodeCommandFile(t *testing.T) {
+	path := writeTempFile(t, []byte("caf\xC3\xA9\xFF"))
+	cmd := NewDecodeCommand()
+	out := captureOutput(t, func() {
+		if err := cmd.Run([]string{"--file", path}); err != nil {
+			t.Fatalf("run error: %v", err)
+		}
+	})
+	for _, want := range []string{
+		"café�\n",
+		"Byte count: 6",
+		"Warning: 0xFF at offset 5 is not valid UTF-8 (out-of-range value)",
+	} {
+		if !strings.Contains(out, want) {
+			t.Fatalf("expected output to contain %q, got %q", want, out)
+		}
+	}
+
+	out = captureOutput(t, func() {
+		if err := cmd.Run([]string{"--from", "latin1", "--file", path}); err != nil {
+			t.Fatalf("run error: %v", err)
+		}
+	})
+	if !strings.Contains(out, "Decoded iso-8859-1 from "+path+":\ncafÃ©ÿ\n") {
+		t.Fatalf("unexpected latin1 output: %q", out)
+	}
+}
+
+func TestSeeCommandAtByteAndRune(t *testing.T) {
+	cmd := NewSeeCommand()
+	out := captureOutput(t, func() {
+		if err := cmd.Run([]string{"--at-byte", "3", "--name", "a😊\nb"}); err != nil {
+			t.Fatalf("run error: %v", err)
+		}
+	})
+	for _, want := range []string{
+		"Letter  Byte  Rune  Line:Col  UTF-16 Col  Width",
+		"▶ '😊'",
+		"▶ byte 3 is '😊' U+1F60A (rune 1, bytes 1-4) at line 1, column 2 (UTF-16 column 2)",
+	} {
+		if !strings.Contains(out, want) {
+			t.Fatalf("expected output to contain %q, got %q", want, out)
+		}
+	}
+
+	out = captureOutput(t, func() {
+		if err := cmd.Run([]string{"--at-rune", "3", "--name", "a😊\nb"}); err != nil {
+			t.Fatalf("run error: %v", err)
+		}
+	})
+	if !strings.Contains(out, "▶ rune 3 is 'b' U+0062 (rune 3, bytes 6-6) at line 2, column 1 (UTF-16 column 1)") {
+		t.Fatalf("unexpected --at-rune output: %q", out)
+	}
+
+	out = captureOutput(t, func() {
+		if err := cmd.Run([]string{"--reverse=bytes", "--at-byte", "2", "--name", "0x41 0xC0 0xAF"}); err != nil {
+			t.Fatalf("run error: %v", err)
+		}
+	})
+	if !strings.Contains(out, "▶ byte 2 is part of an invalid UTF-8 sequence at offset 1: 0xC0 0xAF (overlong encoding)") {
+		t.Fatalf("unexpected invalid target output: %q", out)
+	}
+
+	captureOutput(t, func() {
+		if err := cmd.Run([]string{"--at-byte", "9", "--name", "ab"}); err == nil {
+			t.Fatalf("expected error for byte past the end")
+		}
+		if err := cmd.Run([]string{"--at-byte", "0", "--at-rune", "0", "--name", "ab"}); err == nil {
+			t.Fatalf("expected error combining --at-byte and --at-rune")
+		}
+	})
+}
+
+func TestSeeCommandEmoji(t *testing.T) {
+	cmd := NewSeeCommand()
+	out := captureOutput(t, func() {
+		if err := cmd.Run([]string{"--name", "hi \U0001F469\U0001F3FD\u200d\U0001F4BB"}); err != nil {
+			t.Fatalf("run error: %v", err)
+		}
+	})
+	for _, want := range []string{
+		"Emoji sequences:",
+		"woman technologist: medium skin tone  [zwj, skin-tone]  (from rune 3)",
+		"    = woman: medium skin tone + laptop",
+		"└ U+1F3FD  '\U0001F3FD'            skin tone: medium                    EMOJI MODIFIER FITZPATRICK TYPE-4",
+	} {
+		if !strings.Contains(out, want) {
+			t.Fatalf("expected output to contain %q, got %q", want, out)
+		}
+	}
+	plain := captureOutput(t, func() {
+		if err := cmd.Run([]string{"--name", "hi \U0001F600"}); err != nil {
+			t.Fatalf("run error: %v", err)
+		}
+	})
+	if strings.Contains(plain, "Emoji sequences:") {
+		t.Fatalf("expected no emoji section for a single-rune emoji, got %q", plain)
+	}
+}
+
+func TestSeeCommandEscape(t *testing.T) {
+	cmd := NewSeeCommand()
+	out := captureOutput(t, func() {
+		if err := cmd.Run([]string{"--escape", "go,json", "--name", "a\U0001F600"}); err != nil {
+			t.Fatalf("run error: %v", err)
+		}
+	})
+	for _, want := range []string{
+		"Go Escape",
+		"JSON Escape",
+		`\U0001f600`,
+		`\ud83d\ude00`,
+		"Escaped string:",
+		`  Go:         "a\U0001f600"`,
+		`  JSON:       "a\ud83d\ude00"`,
+	} {
+		if !strings.Contains(out, want) {
+			t.Fatalf("expected output to contain %q, got %q", want, out)
+		}
+	}
+	if err := cmd.Run([]string{"--escape", "cobol", "--name", "a"}); err == nil {
+		t.Fatalf("expected error for unknown escape syntax")
+	}
+}
+
+func TestSeeCommandReverseEscaped(t *testing.T) {
+	cmd := NewSeeCommand()
+	out := captureOutput(t, func() {
+		if err := cmd.Run([]string{"--reverse=escaped", "--name", `\xF0\x9F\x98\x8A &eacute; %41`}); err != nil {
+			t.Fatalf("run error: %v", err)
+		}
+	})
+	for _, want := range []string{"(built from escaped text)", "U+1F60A", "U+00E9", "LATIN CAPITAL LETTER A"} {
+		if !strings.Contains(out, want) {
+			t.Fatalf("expected output to contain %q, got %q", want, out)
+		}
+	}
+	if err := cmd.Run([]string{"--reverse=escaped", "--name", `\u{110000}`}); err == nil {
+		t.Fatalf("expected error for out-of-range escape")
+	}
+}
+
+func TestLookupCommand(t *testing.T) {
+	cmd := NewLookupCommand()
+	out := captureOutput(t, func() {
+		if err := cmd.Run([]string{"--limit", "3", "e", "acute"}); err != nil {
+			t.Fatalf("run error: %v", err)
+		}
+	})
+	for _, want := range []string{"U+00E9     LATIN SMALL LETTER E WITH ACUTE", "U+00C9     LATIN CAPITAL LETTER E WITH ACUTE"} {
+		if !strings.Contains(out, want) {
+			t.Fatalf("expected output to contain %q, got %q", want, out)
+		}
+	}
+	if err := cmd.Run(nil); err == nil {
+		t.Fatalf("expected error without search words")
+	}
+	if err := cmd.Run([]string{"zzzzqqqq"}); err == nil {
+		t.Fatalf("expected error when nothing matches")
+	}
+}
+
+func TestSeeCommandReverseNames(t *testing.T) {
+	cmd := NewSeeCommand()
+	out := captureOutput(t, func() {
+		if err := cmd.Run([]string{"--reverse=names", "--name", `LATIN SMALL LETTER E WITH ACUTE; \N{SNOWMAN}`}); err != nil {
+			t.Fatalf("run error: %v", err)
+		}
+	})
+	for _, want := range []string{"(built from character names)", "U+00E9", "U+2603"} {
+		if !strings.Contains(out, want) {
+			t.Fatalf("expected output to contain %q, got %q", want, out)
+		}
+	}
+	err := cmd.Run([]string{"--reverse=names", "--name", "SNOWMEN"})
+	if err == nil || !strings.Contains(err.Error(), "did you mean SNOWMAN") {
+		t.Fatalf("expected a suggestion for a misspelt name, got %v", err)
+	}
+}
+
+func TestSeeCommandCodePointRange(t *testing.T) {
+	cmd := NewSeeCommand()
+	out := captureOutput(t, func() {
+		if err := cmd.Run([]string{"--reverse=codepoints", "--name", "U+0391..+3"}); err != nil {
+			t.Fatalf("run error: %v", err)
+		}
+	})
+	for _, want := range []string{"GREEK CAPITAL LETTER ALPHA", "GREEK CAPITAL LETTER BETA", "GREEK CAPITAL LETTER GAMMA"} {
+		if !strings.Contains(out, want) {
+			t.Fatalf("expected output to contain %q, got %q", want, out)
+		}
+	}
+	if err := cmd.Run([]string{"--reverse=codepoints", "--max-codepoints", "10", "--name", "U+0041..U+005A"}); err == nil {
+		t.Fatalf("expected the cap to reject a 26 code point range")
+	}
+}
+
+func TestSeeCommandReverseUTF16(t *testing.T) {
+	cmd := NewSeeCommand()
+	out := captureOutput(t, func() {
+		if err := cmd.Run([]string{"--reverse=utf16", "--name", `0xFEFF \uD83D\uDE0A 0x0041`}); err != nil {
+			t.Fatalf("run error: %v", err)
+		}
+	})
+	for _, want := range []string{"(built from UTF-16 code units)", "U+1F60A", "LATIN CAPITAL LETTER A"} {
+		if !strings.Contains(out, want) {
+			t.Fatalf("expected output to contain %q, got %q", want, out)
+		}
+	}
+	if strings.Contains(out, "U+FEFF") {
+		t.Fatalf("expected the byte-order mark to be dropped, got %q", out)
+	}
+	err := cmd.Run([]string{"--reverse=utf16", "--name", "0x41 0xD83D"})
+	if err == nil || !strings.Contains(err.Error(), "unpaired high surrogate 0xD83D at unit 1") {
+		t.Fatalf("expected an unpaired surrogate error, got %v", err)
+	}
+}
+
+func TestSeeCommandParseErrorCaret(t *testing.T) {
+	cmd := NewSeeCommand()
+	err := cmd.Run([]string{"--reverse=codepoints", "U+0041 1F60A 67"})
+	if err == nil {
+		t.Fatalf("expected a parse error")
+	}
+	msg := err.Error()
+	for _, want := range []string{"  U+0041 1F60A 67\n", "\n         ^^^^^\n", "expected a code point", "hint: did you mean U+1F60A?"} {
+		if !strings.Contains(msg, want) {
+			t.Fatalf("expected error to contain %q, got %q", want, msg)
+		}
+	}
+	if strings.Contains(msg, "strconv") {
+		t.Fatalf("expected no raw strconv error, got %q", msg)
+	}
+
+	// Wide characters before the token take two cells each.
+	err = cmd.Run([]string{"--reverse=escaped", "\u65e5\u672c\u8a9e\\q"})
+	if err == nil || !strings.Contains(err.Error(), "\n  \u65e5\u672c\u8a9e\\q\n        ^^\n") {
+		t.Fatalf("expected the caret under \\q after the wide characters, got %v", err)
+	}
+}
+
+func TestDecodeCommandDump(t *testing.T) {
+	dump := "00000000  63 61 66 c3 a9 0a                                 |caf...|\n00000006\n"
+	path := filepath.Join(t.TempDir(), "dump.txt")
+	if err := os.WriteFile(path, []byte(dump), 0o600); err != nil {
+		t.Fatalf("write dump: %v", err)
+	}
+	cmd := NewDecodeCommand()
+	for _, args := range [][]string{{"--dump", "--hex", dump}, {"--dump", "--file", path}} {
+		out := captureOutput(t, func() {
+			if err := cmd.Run(args); err != nil {
+				t.Fatalf("run error: %v", err)
+			}
+		})
+		if !strings.Contains(out, "Decoded UTF-8: café") || !strings.Contains(out, "Byte count: 6") {
+			t.Fatalf("expected the dump to decode to café, got %q", out)
+		}
+	}
+
+	err := cmd.Run([]string{"--dump", "--hex", "00000000: 4865 6c6c  Hel\n00000010: 6c6f  lo"})
+	if err == nil || !strings.Contains(err.Error(), "line 1 holds 4 bytes") || !strings.Contains(err.Error(), "^^^^") {
+		t.Fatalf("expected a pointed dump error, got %v", err)
+	}
+	if err := cmd.Run([]string{"--dump", "--bin", "01000001"}); err == nil {
+		t.Fatalf("expected --dump to reject --bin")
+	}
+	// A dump given as an argument is rejected rather than stdin being read.
+	err = cmd.Run([]string{"--dump", dump})
+	if err == nil || !strings.Contains(err.Error(), "unexpected argument") {
+		t.Fatalf("expected a positional dump to be rejected, got %v", err)
+	}
+
+	xxd := "00000000: 3031 3233 3435 3637  01234567\n00000008: 6162 2063 6420 6566  ab cd ef\n"
+	out := captureOutput(t, func() {
+		if err := cmd.Run([]string{"--dump", "--hex", xxd}); err != nil {
+			t.Fatalf("run error: %v", err)
+		}
+	})
+	if !strings.Contains(out, "Decoded UTF-8: 01234567ab cd ef\n") || !strings.Contains(out, "Byte count: 16") {
+		t.Fatalf("expected the xxd -c 8 gutter to be skipped, got %q", out)
+	}
+}
+
+func TestDecodeCommandBinaryToText(t *testing.T) {
+	cmd := NewDecodeCommand()
+	for _, args := range [][]string{
+		{"--base64", "Y2Fmw6k"},
+		{"--base32", "MNQWNQ5J"},
+		{"--ascii85", "<~@prueW;~>"},
+		{"--qp", "caf=C3=A9"},
+	} {
+		out := captureOutput(t, func() {
+			if err := cmd.Run(args); err != nil {
+				t.Fatalf("run %v error: %v", args, err)
+			}
+		})
+		if !strings.Contains(out, "Decoded UTF-8: café") {
+			t.Fatalf("expected %v to decode to café, got %q", args, out)
+		}
+	}
+	if err := cmd.Run([]string{"--base64", "YQ==", "--hex", "61"}); err == nil {
+		t.Fatalf("expected an error when two inputs are given")
+	}
+	err := cmd.Run([]string{"--base64", "a.b"})
+	if err == nil || !strings.Contains(err.Error(), "hint: a JWT") {
+		t.Fatalf("expected a JWT hint, got %v", err)
+	}
+}
+
+func TestSeeCommandTextEncodings(t *testing.T) {
+	cmd := NewSeeCommand()
+	out := captureOutput(t, func() {
+		if err := cmd.Run([]string{"--reverse=base64", "--name", "Y2Fmw6k="}); err != nil {
+			t.Fatalf("run error: %v", err)
+		}
+	})
+	for _, want := range []string{"(decoded from base64)", "LATIN SMALL LETTER E WITH ACUTE", "Encoded bytes:", "Base64 URL:       Y2Fmw6k\n", "Quoted-printable: caf=C3=A9"} {
+		if !strings.Contains(out, want) {
+			t.Fatalf("expected output to contain %q, got %q", want, out)
+		}
+	}
+}
+
+func TestByteLiteralInput(t *testing.T) {
+	out := captureOutput(t, func() {
+		if err := NewDecodeCommand().Run([]string{"--literal", "new byte[]{-61, -87}"}); err != nil {
+			t.Fatalf("run error: %v", err)
+		}
+	})
+	if !strings.Contains(out, "Decoded UTF-8: é") {
+		t.Fatalf("expected signed Java bytes to decode to é, got %q", out)
+	}
+
+	out = captureOutput(t, func() {
+		if err := NewSeeCommand().Run([]string{"--reverse=literal", "--name", `b'\xe2\x82\xac'`}); err != nil {
+			t.Fatalf("run error: %v", err)
+		}
+	})
+	for _, want := range []string{"(built from a byte array literal)", "EURO SIGN"} {
+		if !strings.Contains(out, want) {
+			t.Fatalf("expected output to contain %q, got %q", want, out)
+		}
+	}
+}
+
+func TestSeeCommandFormatMatchesDownload(t *testing.T) {
+	mux := http.NewServeMux()
+	web.NewServer().Routes(mux)
+
+	cases := []struct {
+		args  []string
+		query string
+	}{
+		{[]string{"--name", "café"}, "input=" + url.QueryEscape("café")},
+		{[]string{"--reverse", "bytes", "--name", "0xFF 0x41"}, "mode=bytes&input=" + url.QueryEscape("0xFF 0x41")},
+	}
+	for _, tc := range cases {
+		for _, format := range []string{"csv", "json", "yaml", "ndjson"} {
+			out := captureOutput(t, func() {
+				if err := NewSeeCommand().Run(append([]string{"--format", format}, tc.args...)); err != nil {
+					t.Fatalf("run error: %v", err)
+				}
+			})
+			req := httptest.NewRequest(http.MethodGet, "/api/download?format="+format+"&"+tc.query, nil)
+			w := httptest.NewRecorder()
+			mux.ServeHTTP(w, req)
+			if w.Code != http.StatusOK {
+				t.Fatalf("download %s: status %d", format, w.Code)
+			}
+			if out != w.Body.String() {
+				t.Fatalf("see --format %s %v differs from the download:\n%q\n%q", format, tc.args, out, w.Body.String())
+			}
+		}
+	}
+
+	// Invalid bytes get their own row and do not shift the offsets of the
+	// runes after them.
+	out := captureOutput(t, func() {
+		if err := NewSeeCommand().Run([]string{"--format", "csv", "--encodings", "utf8", "--escape", "go", "--reverse", "bytes", "--name", "0xFF 0x41"}); err != nil {
+			t.Fatalf("run error: %v", err)
+		}
+	})
+	lines := strings.Split(strings.TrimSpace(out), "\n")
+	if len(lines) != 3 || !strings.HasPrefix(lines[1], "<invalid>,,,0xFF,,,,,invalid UTF-8: out-of-range value,,,,0,") ||
+		!strings.HasSuffix(lines[2], `Basic Latin,1,0,1,2,2,\x41`) || strings.Contains(out, "REPLACEMENT CHARACTER") {
+		t.Fatalf("expected an invalid row and true offsets, got %q", out)
+	}
+}
+
+func TestSeeCommandFormat(t *testing.T) {
+	out := captureOutput(t, func() {
+		if err := NewSeeCommand().Run([]string{"--format=ndjson", "--reverse=codepoints", "--name", "U+0041 U+00E9"}); err != nil {
+			t.Fatalf("run error: %v", err)
+		}
+	})
+	lines := strings.Split(strings.TrimSpace(out), "\n")
+	if len(lines) != 2 || !strings.Contains(lines[1], `"Name":"LATIN SMALL LETTER E WITH ACUTE"`) {
+		t.Fatalf("expected one JSON object per rune with no table, got %q", out)
+	}
+
+	out = captureOutput(t, func() {
+		if err := NewSeeCommand().Run([]string{"--format=csv", "--encodings=utf8", "--escape=go", "--name", "A"}); err != nil {
+			t.Fatalf("run error: %v", err)
+		}
+	})
+	if header := strings.SplitN(out, "\n", 2)[0]; !strings.HasSuffix(header, "ColumnUTF16,GoEscape") {
+		t.Fatalf("expected --encodings and --escape to pick the columns, got %q", header)
+	}
+
+	for _, args := range [][]string{
+		{"--format=xml", "--name", "A"},
+		{"--format=json", "--graphemes", "--name", "A"},
+		{"--format=json", "--file", "README.md"},
+	} {
+		if err := NewSeeCommand().Run(args); err == nil {
+			t.Fatalf("expected %v to fail", args)
+		}
+	}
+}
+
+func TestSeeCommandTableLayout(t *testing.T) {
+	cmd := NewSeeCommand()
+	out := captureOutput(t, func() {
+		if err := cmd.Run([]string{"--width", "0", "--name", "a日\u0301😀"}); err != nil {
+			t.Fatalf("run error: %v", err)
+		}
+	})
+	// Every row starts its Code Point (hex) column at the same terminal cell.
+	var starts []int
+	for _, line := range strings.Split(out, "\n") {
+		if i := strings.Index(line, "U+"); i >= 0 && strings.HasPrefix(line, "'") {
+			starts = append(starts, visualiser.StringWidth(line[:i]))
+		}
+	}
+	if len(starts) != 4 || starts[0] != starts[1] || starts[0] != starts[2] || starts[0] != starts[3] {
+		t.Fatalf("expected aligned columns, got starts %v in %q", starts, out)
+	}
+	for _, want := range []string{"Letter  Width  Code Point (dec)", "'日'    2      26085", "'\u0301'      0      769"} {
+		if !strings.Contains(out, want) {
+			t.Fatalf("expected output to contain %q, got %q", want, out)
+		}
+	}
+
+	out = captureOutput(t, func() {
+		if err := cmd.Run([]string{"--box", "--width", "100", "--name", "日"}); err != nil {
+			t.Fatalf("run error: %v", err)
+		}
+	})
+	var table []string
+	for _, line := range strings.Split(out, "\n") {
+		if strings.ContainsAny(line, "┌│├└") {
+			table = append(table, line)
+		}
+	}
+	if len(table) != 5 || !strings.HasPrefix(table[0], "┌") || !strings.HasPrefix(table[4], "└") {
+		t.Fatalf("expected a boxed table, got %q", out)
+	}
+	for _, line := range table {
+		if w := visualiser.StringWidth(line); w != 100 {
+			t.Fatalf("expected every line to fill 100 cells, got %d: %q", w, line)
+		}
+	}
+	if !strings.Contains(table[3], "CJK…") {
+		t.Fatalf("expected long cells to be cut with an ellipsis, got %q", table[3])
+	}
+
+	// Without a width limit, span rows wider than the columns widen the
+	// table instead of pushing out the right border.
+	for _, tc := range []struct {
+		args []string
+		span string
+	}{
+		{[]string{"--graphemes", "--name", "e\u0301x"}, "(grapheme cluster: 2 runes, 3 bytes) │"},
+		{[]string{"--reverse", "bytes", "--name", "0xFF 0x41"}, "invalid UTF-8 at offset 0: 0xFF (out-of-range value) │"},
+	} {
+		out = captureOutput(t, func() {
+			if err := cmd.Run(append([]string{"--box", "--width", "0", "--columns", "cp"}, tc.args...)); err != nil {
+				t.Fatalf("run error: %v", err)
+			}
+		})
+		if !strings.Contains(out, tc.span) {
+			t.Fatalf("expected the span %q inside the box, got %q", tc.span, out)
+		}
+		table = table[:0]
+		for _, line := range strings.Split(out, "\n") {
+			if strings.ContainsAny(line, "┌│├└") {
+				table = append(table, line)
+			}
+		}
+		for _, line := range table {
+			if w, want := visualiser.StringWidth(line), visualiser.StringWidth(table[0]); w != want {
+				t.Fatalf("expected every line of %v to be %d cells, got %d: %q", tc.args, want, w, line)
+			}
+		}
+	}
+}
+
+func TestSeeCommandRowView(t *testing.T) {
+	cmd := NewSeeCommand()
+	out := captureOutput(t, func() {
+		if err := cmd.Run([]string{"--width", "0", "--columns", "char,cp,utf8hex", "--unique", "--sort", "codepoint", "--filter", "non-ascii", "--name", "hé日éé"}); err != nil {
+			t.Fatalf("run error: %v", err)
+		}
+	})
+	for _, want := range []string{"Letter  Count  Code Point (hex)  UTF-8 Hex Bytes", "'é'     3      U+00E9", "'日'    1      U+65E5"} {
+		if !strings.Contains(out, want) {
+			t.Fatalf("expected output to contain %q, got %q", want, out)
+		}
+	}
+	if strings.Contains(out, "'h'") || strings.Contains(out, "Category") || strings.Index(out, "'é'") > strings.Index(out, "'日'") {
+		t.Fatalf("expected only the picked columns and non-ASCII rows by code point, got %q", out)
+	}
+
+	out = captureOutput(t, func() {
+		if err := cmd.Run([]string{"--width", "0", "--sort", "bytes", "--reverse", "bytes", "--name", "0xE6 0x97 0xA5 0xFF 0x41"}); err != nil {
+			t.Fatalf("run error: %v", err)
+		}
+	})
+	a, ideograph, invalid := strings.Index(out, "'A'"), strings.Index(out, "'日'"), strings.Index(out, "<invalid>")
+	if a < 0 || a > ideograph || ideograph > invalid {
+		t.Fatalf("expected sorted rows with invalid bytes last, got %q", out)
+	}
+
+	for _, args := range [][]string{
+		{"--columns", "char,colour", "--name", "A"},
+		{"--sort", "name", "--name", "A"},
+		{"--filter", "emoji", "--name", "A"},
+		{"--graphemes", "--unique", "--name", "A"},
+		{"--at-rune", "0", "--filter", "ascii", "--name", "A"},
+		{"--file", "README.md", "--sort", "codepoint"},
+	} {
+		if err := cmd.Run(args); err == nil {
+			t.Fatalf("expected %v to fail", args)
+		}
+	}
+}
+
+func TestSeeCommandLines(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "export.csv")
+	if err := os.WriteFile(path, []byte("id,name\r\n1,Zo\u00eb\r\n\r\n2,a\xffb\n3,x\u200by"), 0o600); err != nil {
+		t.Fatalf("write file: %v", err)
+	}
+	cmd := NewSeeCommand()
+	out := captureOutput(t, func() {
+		if err := cmd.Run([]string{"--lines", "--file", path, "--filter", "non-ascii", "--columns", "cp,name", "--width", "0"}); err != nil {
+			t.Fatalf("run error: %v", err)
+		}
+	})
+	for _, want := range []string{
+		"Line 2: \"1,Zo\u00eb\"\nLetter  Code Point (hex)  Name",
+		"U+00EB            LATIN SMALL LETTER E WITH DIAERESIS",
+		"Line 4: \"2,a\\xffb\"",
+		"invalid UTF-8 at offset 3: 0xFF",
+		"Line 5: \"3,x\\u200by\"",
+		"Read 5 line(s), showed 3: 1 with invalid UTF-8, 1 with hidden or control characters",
+	} {
+		if !strings.Contains(out, want) {
+			t.Fatalf("expected output to contain %q, got %q", want, out)
+		}
+	}
+	if strings.Contains(out, "Line 1:") || strings.Contains(out, "Line 3:") {
+		t.Fatalf("expected ASCII-only and blank lines to be skipped, got %q", out)
+	}
+
+	out = captureOutput(t, func() {
+		withStdin(t, "A\n\u00e9\u00e9\n", func() {
+			if err := cmd.Run([]string{"--lines", "--format", "ndjson", "--escape", "json", "-"}); err != nil {
+				t.Fatalf("run error: %v", err)
+			}
+		})
+	})
+	lines := strings.Split(strings.TrimSpace(out), "\n")
+	if len(lines) != 2 {
+		t.Fatalf("expected one record per line, got %q", out)
+	}
+	var record lineRecord
+	if err := json.Unmarshal([]byte(lines[1]), &record); err != nil {
+		t.Fatalf("invalid record %q: %v", lines[1], err)
+	}
+	if record.Line != 2 || record.Text != "\u00e9\u00e9" || len(record.Items) != 2 || record.Items[1].Line != 2 || record.Items[1].ByteOffset != 2 {
+		t.Fatalf("expected line 2 with positions inside it, got %+v", record)
+	}
+	if len(record.Escaped) != 1 || record.Escaped[0].Literal != `"\u00e9\u00e9"` {
+		t.Fatalf("expected the line escaped for JSON, got %+v", record.Escaped)
+	}
+
+	out = captureOutput(t, func() {
+		if err := cmd.Run([]string{"--lines", "--reverse", "codepoints", "--name", "U+0041\nU+00E9 U+00E9", "--unique", "--width", "0"}); err != nil {
+			t.Fatalf("run error: %v", err)
+		}
+	})
+	if !strings.Contains(out, "Line 2: \"\u00e9\u00e9\"\n  (built from codepoints)") || !strings.Contains(out, "'\u00e9'     2") {
+		t.Fatalf("expected each line reversed and collapsed on its own, got %q", out)
+	}
+
+	for _, args := range [][]string{
+		{"--lines", "--format", "csv", "--name", "A"},
+		{"--lines", "--graphemes", "--name", "A"},
+		{"--lines", "--at-rune", "0", "--name", "A"},
+		{"--lines", "--reverse", "codepoints", "--name", "U+0041\nnope"},
+	} {
+		if err := cmd.Run(args); err == nil {
+			t.Fatalf("expected %v to fail", args)
+		}
+	}
+}
+
+func TestSeeCommandStdin(t *testing.T) {
+	cmd := NewSeeCommand()
+	out := captureOutput(t, func() {
+		withStdin(t, "U+0041 U+00E9\n", func() {
+			if err := cmd.Run([]string{"--reverse", "codepoints", "-"}); err != nil {
+				t.Fatalf("run error: %v", err)
+			}
+		})
+	})
+	if !strings.Contains(out, "Name: A\u00e9\n") || !strings.Contains(out, "LATIN SMALL LETTER E WITH ACUTE") {
+		t.Fatalf("expected the text read from stdin, got %q", out)
+	}
+
+	out = captureOutput(t, func() {
+		withStdin(t, "h\u00e9", func() {
+			if err := cmd.Run([]string{"--file", "-"}); err != nil {
+				t.Fatalf("run error: %v", err)
+			}
+		})
+	})
+	if !strings.Contains(out, "File: stdin") || !strings.Contains(out, "Read 3 bytes: 2 runes") {
+		t.Fatalf("expected stdin to be streamed, got %q", out)
+	}
+
+	withStdin(t, "\n", func() {
+		if err := cmd.Run([]string{"-"}); err == nil {
+			t.Fatal("expected empty stdin to fail")
+		}
+	})
+}
+
+func TestReplCommand(t *testing.T) {
+	script := strings.Join([]string{
+		"hé",
+		":cp U+0041",
+		":bytes F0 9F 98 8A",
+		":decode 63 61 66 C3 A9",
+		":decode --base64 Y2Fmw6k=",
+		":format ndjson",
+		":cp U+00E9",
+		":format table",
+		":set --columns cp,name --width 0",
+		":text :-)",
+		`:diff café "cafe\u0301"`,
+		":nope",
+		":quit",
+		"never shown",
+	}, "\n")
+	out := captureOutput(t, func() {
+		withStdin(t, script, func() {
+			if err := NewReplCommand().Run([]string{"--history", ""}); err != nil {
+				t.Fatalf("run error: %v", err)
+			}
+		})
+	})
+	for _, want := range []string{
+		"Name: hé\n",
+		"Name: A\n  (built from codepoints)",
+		"Name: \U0001F60A\n  (built from bytes)",
+		"Decoded UTF-8: café\nByte count: 5\nDecoded UTF-8: café\nByte count: 5",
+		"format: ndjson\n{\"Character\":\"'é'\"",
+		"see options: --columns cp,name --width 0",
+		"Letter  Code Point (hex)  Name\n",
+		"'-'     U+002D            HYPHEN-MINUS",
+		"Equal after NFC:       yes",
+		"-  'é' U+00E9",
+		"+                      'e' U+0065",
+	} {
+		if !strings.Contains(out, want) {
+			t.Fatalf("expected output to contain %q, got %q", want, out)
+		}
+	}
+	if strings.Contains(out, "never shown") {
+		t.Fatalf("expected :quit to end the session, got %q", out)
+	}
+}
+
+func TestReplRejectsBadOptions(t *testing.T) {
+	var session replSession
+	for _, line := range []string{
+		":set --bogus",
+		":set --width wide",
+		":set --file -",
+		":set -",
+		":decode --bogus 41",
+		":decode --dump",
+		":decode --file -",
+	} {
+		var err error
+		out := captureOutput(t, func() {
+			_, err = session.eval(line)
+		})
+		if err == nil || out != "" {
+			t.Fatalf("expected %s to be rejected without output, got %v and %q", line, err, out)
+		}
+	}
+	if len(session.options) != 0 {
+		t.Fatalf("expected rejected options not to be kept, got %q", session.options)
+	}
+
+	// Entries after a rejected :set still run with the options set before it.
+	out := captureOutput(t, func() {
+		for _, line := range []string{":set --columns cp --width 0", ":set --bogus", "A"} {
+			session.eval(line)
+		}
+	})
+	if !strings.Contains(out, "Letter  Code Point (hex)\n") || !strings.Contains(out, "U+0041") {
+		t.Fatalf("expected the valid options to apply, got %q", out)
+	}
+}
+
+func TestLineEditor(t *testing.T) {
+	// Type "ac", move left, insert "b", Enter; then recall it with Up, delete
+	// a word with Ctrl-W, type "x" and Enter.
+	keys := "ac\x1b[Db\r" + "\x1b[A\x17x\r" + "\x04"
+	editor := &lineEditor{in: bufio.NewReader(strings.NewReader(keys)), out: io.Discard, history: []string{"old"}}
+	var got []string
+	for {
+		line, err := editor.readLine("> ")
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			t.Fatalf("readLine: %v", err)
+		}
+		got = append(got, line)
+		editor.remember(line)
+	}
+	if len(got) != 2 || got[0] != "abc" || got[1] != "x" {
+		t.Fatalf("expected [abc x], got %q", got)
+	}
+	if len(editor.history) != 3 || editor.history[1] != "abc" {
+		t.Fatalf("expected entries to be added to the history, got %q", editor.history)
+	}
+
+	path := filepath.Join(t.TempDir(), "history")
+	for _, line := range []string{"one", "two"} {
+		if err := appendHistory(path, line); err != nil {
+			t.Fatalf("appendHistory: %v", err)
+		}
+	}
+	if history, err := loadHistory(path); err != nil || len(history) != 2 || history[1] != "two" {
+		t.Fatalf("expected the saved history back, got %q (%v)", history, err)
+	}
+}
+
+func TestSplitArgs(t *testing.T) {
+	got, err := splitArgs(`--escape go 'a b' "\u00e9\t" x`)
+	if err != nil {
+		t.Fatalf("splitArgs: %v", err)
+	}
+	want := []string{"--escape", "go", "a b", "\u00e9\t", "x"}
+	if strings.Join(got, "|") != strings.Join(want, "|") {
+		t.Fatalf("expected %q, got %q", want, got)
+	}
+	if _, err := splitArgs(`"open`); err == nil {
+		t.Fatal("expected an unterminated quote to fail")
+	}
+}
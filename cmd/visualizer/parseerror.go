@@ -0,0 +1,51 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+
+	"go_tutorials/internal/reverseinput"
+	"go_tutorials/internal/visualiser"
+)
+
+// caretError renders a reverseinput.ParseError with the offending line of
+// input and a caret under the bad token.
+type caretError struct {
+	input string
+	err   *reverseinput.ParseError
+}
+
+// pointAt wraps a ParseError so that it prints with a caret pointer; other
+// errors are returned unchanged.
+func pointAt(input string, err error) error {
+	var perr *reverseinput.ParseError
+	if !errors.As(err, &perr) {
+		return err
+	}
+	return &caretError{input: input, err: perr}
+}
+
+func (e *caretError) Error() string {
+	perr := e.err
+	lineStart := strings.LastIndexByte(e.input[:perr.Start], '\n') + 1
+	lineEnd := len(e.input)
+	if i := strings.IndexByte(e.input[perr.Start:], '\n'); i >= 0 {
+		lineEnd = perr.Start + i
+	}
+	line := e.input[lineStart:lineEnd]
+	// Wide characters such as 日 or 😀 take two terminal cells.
+	column := visualiser.StringWidth(e.input[lineStart:perr.Start])
+	width := max(visualiser.StringWidth(e.input[perr.Start:min(perr.End, lineEnd)]), 1)
+
+	var sb strings.Builder
+	sb.WriteString(perr.Err.Error())
+	fmt.Fprintf(&sb, "\n  %s\n  %s%s", line, strings.Repeat(" ", column), strings.Repeat("^", width))
+	fmt.Fprintf(&sb, "\n  expected %s", perr.Expected)
+	if perr.Suggestion != "" {
+		fmt.Fprintf(&sb, "\n  hint: %s", perr.Suggestion)
+	}
+	return sb.String()
+}
+
+func (e *caretError) Unwrap() error { return e.err }
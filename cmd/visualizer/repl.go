@@ -0,0 +1,355 @@
+package main
+
+import (
+	"bufio"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"regexp"
+	"slices"
+	"strconv"
+	"strings"
+
+	"go_tutorials/internal/export"
+)
+
+const replPrompt = "visualizer> "
+
+const replHelp = `Type text to see its characters, or a command:
+  :cp U+0041 U+00E9        build text from code points
+  :bytes F0 9F 98 8A       build text from bytes (bare tokens are hex)
+  :decode 63 61 66 C3 A9   decode hex bytes; also takes decode's flags, e.g. :decode --base64 Y2Fmw6k=
+  :diff café "cafe\u0301"  compare two strings rune by rune ("..." takes Go escapes)
+  :format json             print entries as json, ndjson, csv, tsv, markdown, html, yaml or table
+  :set --escape go --box   apply see options to every entry (:set alone shows them)
+  :reset                   clear :set and :format
+  :text :-)                show text that starts with a colon
+  :help                    show this help
+  :quit                    leave (or press Ctrl-D)
+`
+
+// ReplCommand handles the `repl` sub-command, an interactive session that
+// runs see and decode on every line entered.
+type ReplCommand struct{}
+
+// NewReplCommand creates a repl command handler.
+func NewReplCommand() *ReplCommand {
+	return &ReplCommand{}
+}
+
+// Run executes the repl command. On a terminal, lines are edited in place and
+// kept in a history file; otherwise stdin is read line by line, so a script
+// of entries can be piped in.
+func (c *ReplCommand) Run(args []string) error {
+	fs := flag.NewFlagSet("repl", flag.ContinueOnError)
+	historyFlag := fs.String("history", defaultHistoryPath(), "File that keeps entered lines between sessions (empty to keep none)")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+
+	var (
+		session     replSession
+		editor      *lineEditor
+		interactive = isTerminal(os.Stdin) && isTerminal(os.Stdout)
+		in          = bufio.NewReader(os.Stdin)
+		next        = func() (string, error) { return readPlainLine(in) }
+	)
+	if interactive {
+		history, err := loadHistory(*historyFlag)
+		if err != nil {
+			fmt.Fprintln(os.Stderr, "warning: history not loaded:", err)
+		}
+		editor = &lineEditor{in: in, out: os.Stdout, history: history}
+		next = func() (string, error) {
+			restore, err := enableRawMode(os.Stdin)
+			if err != nil {
+				fmt.Print(replPrompt)
+				return readPlainLine(in)
+			}
+			defer restore()
+			return editor.readLine(replPrompt)
+		}
+		fmt.Println("Type text to see its characters, :help for commands or :quit to leave.")
+	}
+
+	for {
+		line, err := next()
+		if errors.Is(err, errInterrupted) {
+			continue
+		}
+		if err == io.EOF {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+		line = strings.TrimSpace(line)
+		if line == "" {
+			continue
+		}
+		if editor != nil && editor.remember(line) {
+			if err := appendHistory(*historyFlag, line); err != nil {
+				fmt.Fprintln(os.Stderr, "warning: history not saved:", err)
+			}
+		}
+		quit, err := session.eval(line)
+		if err != nil {
+			fmt.Fprintln(os.Stderr, "error:", err)
+		}
+		if quit {
+			return nil
+		}
+	}
+}
+
+// defaultHistoryPath returns ~/.visualizer_history, or "" when there is no
+// home directory.
+func defaultHistoryPath() string {
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return ""
+	}
+	return filepath.Join(home, ".visualizer_history")
+}
+
+// readPlainLine reads one line without editing support.
+func readPlainLine(in *bufio.Reader) (string, error) {
+	line, err := in.ReadString('\n')
+	if err == io.EOF && line != "" {
+		err = nil
+	}
+	return string(trimLineEnd([]byte(line))), err
+}
+
+// replSession is the state a REPL keeps between entries.
+type replSession struct {
+	format  export.Format // empty for the table
+	options []string      // see flags from :set
+}
+
+// eval runs one entry and reports whether the session should end.
+func (s *replSession) eval(line string) (bool, error) {
+	if !strings.HasPrefix(line, ":") {
+		return false, s.see(line)
+	}
+	name, rest, _ := strings.Cut(line[1:], " ")
+	rest = strings.TrimSpace(rest)
+	switch strings.ToLower(name) {
+	case "q", "quit", "exit":
+		return true, nil
+	case "h", "help", "?":
+		fmt.Print(replHelp)
+	case "text":
+		return false, s.see(rest)
+	case "cp":
+		return false, s.see(rest, "--reverse=codepoints", "--name", rest)
+	case "bytes":
+		return false, s.see(rest, "--reverse=bytes", "--name", hexTokens(rest))
+	case "decode":
+		return false, s.decode(rest)
+	case "diff":
+		args, err := splitArgs(rest)
+		if err != nil {
+			return false, err
+		}
+		if len(args) != 2 {
+			return false, errors.New(`:diff needs two strings, e.g. :diff café "cafe\u0301"`)
+		}
+		return false, renderDiff(args[0], args[1])
+	case "format":
+		return false, s.setFormat(rest)
+	case "set":
+		if rest != "" {
+			args, err := splitArgs(rest)
+			if err != nil {
+				return false, err
+			}
+			if err := checkSeeOptions(args); err != nil {
+				return false, err
+			}
+			s.options = args
+		}
+		if len(s.options) == 0 {
+			fmt.Println("see options: none")
+		} else {
+			fmt.Printf("see options: %s\n", strings.Join(s.options, " "))
+		}
+	case "reset":
+		s.options, s.format = nil, ""
+		fmt.Println("see options and format cleared")
+	default:
+		return false, fmt.Errorf("unknown command :%s (type :help for the list, or :text to show text starting with a colon)", name)
+	}
+	return false, nil
+}
+
+// see runs the see command on input with the session's options. args
+// default to the input itself given as --name.
+func (s *replSession) see(input string, args ...string) error {
+	if input == "" {
+		return errors.New("nothing to show; add text after the command")
+	}
+	if len(args) == 0 {
+		args = []string{"--name", input}
+	}
+	all := slices.Clone(s.options)
+	if s.format != "" {
+		all = append(all, "--format", string(s.format))
+	}
+	return NewSeeCommand().Run(append(all, args...))
+}
+
+// decode runs the decode command. Input without flags is taken as hex.
+func (s *replSession) decode(input string) error {
+	if input == "" {
+		return errors.New(":decode needs bytes, e.g. :decode 63 61 66 C3 A9 or :decode --base64 Y2Fmw6k=")
+	}
+	if !strings.HasPrefix(input, "-") {
+		return NewDecodeCommand().Run([]string{"--hex", input})
+	}
+	args, err := splitArgs(input)
+	if err != nil {
+		return err
+	}
+	if err := checkDecodeArgs(args); err != nil {
+		return err
+	}
+	return NewDecodeCommand().Run(args)
+}
+
+// checkSeeOptions parses the options given to :set with see's flags, so a
+// bad option is reported once instead of failing every later entry. Options
+// that read a file or stdin are rejected: each entry is the input, and stdin
+// is where the REPL reads its entries.
+func checkSeeOptions(args []string) error {
+	var flags seeFlags
+	fs := flags.flagSet()
+	fs.SetOutput(io.Discard)
+	if err := fs.Parse(args); err != nil {
+		return fmt.Errorf(":set: %w", err)
+	}
+	switch {
+	case fs.NArg() > 0:
+		return fmt.Errorf(":set takes see options, not %q", fs.Arg(0))
+	case flagSet(fs, "file"):
+		return errors.New(":set cannot take --file; every entry is the input")
+	}
+	return nil
+}
+
+// checkDecodeArgs parses the arguments of :decode with decode's flags and
+// rejects those that would read stdin, which the REPL is reading its entries
+// from.
+func checkDecodeArgs(args []string) error {
+	var flags decodeFlags
+	fs := flags.flagSet()
+	fs.SetOutput(io.Discard)
+	if err := fs.Parse(args); err != nil {
+		return fmt.Errorf(":decode: %w", err)
+	}
+	switch {
+	case flags.file == "-":
+		return errors.New(":decode cannot read stdin; give --file a file name")
+	case flags.dump && flags.hexInput == "" && flags.file == "":
+		return errors.New(":decode --dump needs the dump in --hex or --file, e.g. :decode --dump --hex '00000000: 6361 6665  cafe'")
+	}
+	return nil
+}
+
+// setFormat switches the output of later entries, or prints the current
+// format when name is empty.
+func (s *replSession) setFormat(name string) error {
+	switch {
+	case name == "":
+	case strings.EqualFold(name, "table"):
+		s.format = ""
+	default:
+		f, err := export.ParseFormat(name)
+		if err != nil {
+			return fmt.Errorf("%w, or table", err)
+		}
+		s.format = f
+	}
+	if s.format == "" {
+		fmt.Println("format: table")
+	} else {
+		fmt.Printf("format: %s\n", s.format)
+	}
+	return nil
+}
+
+// bareHexByte matches a byte written as two hex digits without a prefix.
+var bareHexByte = regexp.MustCompile(`^[0-9A-Fa-f]{2}$`)
+
+// hexTokens prefixes bare two-digit tokens such as F0 with 0x, so :bytes
+// reads them as hex; other tokens are kept for the bytes parser.
+func hexTokens(input string) string {
+	fields := strings.Fields(input)
+	for i, field := range fields {
+		if bareHexByte.MatchString(field) {
+			fields[i] = "0x" + field
+		}
+	}
+	return strings.Join(fields, " ")
+}
+
+// splitArgs splits a command line on spaces. Single quotes keep their
+// contents as is, and double quotes also interpret Go escapes such as
+// \u0301.
+func splitArgs(line string) ([]string, error) {
+	var (
+		args    []string
+		current strings.Builder
+		started bool
+	)
+	for i := 0; i < len(line); i++ {
+		switch c := line[i]; {
+		case c == ' ' || c == '\t':
+			if started {
+				args = append(args, current.String())
+				current.Reset()
+				started = false
+			}
+		case c == '\'':
+			end := strings.IndexByte(line[i+1:], '\'')
+			if end < 0 {
+				return nil, fmt.Errorf("unterminated ' in %q", line)
+			}
+			current.WriteString(line[i+1 : i+1+end])
+			i += end + 1
+			started = true
+		case c == '"':
+			end := i + 1
+			for end < len(line) && line[end] != '"' {
+				if line[end] == '\\' {
+					end++
+				}
+				end++
+			}
+			if end >= len(line) {
+				return nil, fmt.Errorf(`unterminated " in %q`, line)
+			}
+			text, err := strconv.Unquote(line[i : end+1])
+			if err != nil {
+				return nil, fmt.Errorf("invalid quoted string %s: %w", line[i:end+1], err)
+			}
+			current.WriteString(text)
+			i = end
+			started = true
+		default:
+			current.WriteByte(c)
+			started = true
+		}
+	}
+	if started {
+		args = append(args, current.String())
+	}
+	return args, nil
+}
+
+func init() {
+	registerCommand("repl", func() Command { return NewReplCommand() })
+}
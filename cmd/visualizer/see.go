@@ -4,8 +4,14 @@ import (
 	"errors"
 	"flag"
 	"fmt"
+	"os"
+	"slices"
 	"strings"
+	"unicode/utf8"
 
+	"go_tutorials/internal/codepage"
+	"go_tutorials/internal/confusables"
+	"go_tutorials/internal/export"
 	"go_tutorials/internal/reverseinput"
 	"go_tutorials/internal/visualiser"
 )
@@ -18,62 +24,342 @@ func NewSeeCommand() *SeeCommand {
 	return &SeeCommand{}
 }
 
+// seeFlags holds the options of the see command.
+type seeFlags struct {
+	name          string
+	reverse       string
+	graphemes     bool
+	normalize     bool
+	codePage      string
+	confusables   bool
+	compare       string
+	encodings     string
+	escape        string
+	file          string
+	lines         bool
+	maxCodePoints int
+	atByte        int
+	atRune        int
+	columns       string
+	sort          string
+	unique        bool
+	filter        string
+	box           bool
+	width         int
+	format        string
+}
+
+// flagSet returns the see command's flag set, storing the options in f.
+func (f *seeFlags) flagSet() *flag.FlagSet {
+	fs := flag.NewFlagSet("see", flag.ContinueOnError)
+	fs.StringVar(&f.name, "name", "", "Name or tokens to visualize")
+	fs.StringVar(&f.reverse, "reverse", "", "Reverse input: 'codepoints', 'bytes', 'utf16', 'escaped', 'names', 'dump', 'base64', 'base32', 'ascii85', 'qp' or 'literal'")
+	fs.BoolVar(&f.graphemes, "graphemes", false, "Group runes into user-perceived characters (grapheme clusters)")
+	fs.BoolVar(&f.normalize, "normalize", false, "Compare the NFC, NFD, NFKC and NFKD forms of the input")
+	fs.StringVar(&f.codePage, "codepage", "", "Also show bytes in a legacy code page, e.g. windows-1252, iso-8859-5, cp437, koi8-r")
+	fs.BoolVar(&f.confusables, "confusables", false, "Flag characters that look like Latin letters or digits (UTS #39 skeletons)")
+	fs.StringVar(&f.compare, "confusable-with", "", "Check whether the input is visually equivalent to this string (implies --confusables)")
+	fs.StringVar(&f.encodings, "encodings", "utf8", "Comma-separated encodings to show: utf8, utf16be, utf16le, utf32be, utf32le")
+	fs.StringVar(&f.escape, "escape", "", "Comma-separated escape syntaxes to show: go, json, js, python, java, c, rust, css, url, xml")
+	fs.StringVar(&f.file, "file", "", "Stream the characters of a UTF-8 file instead of --name ('-' for stdin)")
+	fs.BoolVar(&f.lines, "lines", false, "Analyse every line of the input separately, as a sectioned report or, with --format ndjson, one record per line")
+	fs.IntVar(&f.maxCodePoints, "max-codepoints", reverseinput.DefaultMaxCodePoints, "Cap on the runes code point ranges such as U+0041..U+005A may expand to (0 for no cap)")
+	fs.IntVar(&f.atByte, "at-byte", -1, "Highlight the row containing this 0-based byte offset")
+	fs.IntVar(&f.atRune, "at-rune", -1, "Highlight the row of this 0-based rune index")
+	fs.StringVar(&f.columns, "columns", "", "Comma-separated table columns in order, e.g. char,cp,utf8hex,name (default: the columns the other options ask for)")
+	fs.StringVar(&f.sort, "sort", "input", "Row order: input, codepoint or bytes (UTF-8 length)")
+	fs.BoolVar(&f.unique, "unique", false, "Show each character once, with a Count column")
+	fs.StringVar(&f.filter, "filter", "", "Only show matching rows, e.g. non-ascii, category=Mn or bytes>2; comma-separated conditions must all hold")
+	fs.BoolVar(&f.box, "box", false, "Draw the table with box-drawing borders")
+	fs.IntVar(&f.width, "width", -1, "Fit the table into this many terminal columns (default: the terminal's width; 0 for no limit)")
+	fs.StringVar(&f.format, "format", "table", "Output format: table, json, ndjson, csv, tsv, markdown, html or yaml")
+	return fs
+}
+
 // Run executes the see command using provided CLI args.
 func (c *SeeCommand) Run(args []string) error {
-	fs := flag.NewFlagSet("see", flag.ContinueOnError)
-	nameFlag := fs.String("name", "", "Name or tokens to visualize")
-	reverseFlag := fs.String("reverse", "", "Reverse input: 'codepoints' or 'bytes'")
+	var flags seeFlags
+	fs := flags.flagSet()
 	if err := fs.Parse(args); err != nil {
 		return err
 	}
 
-	input := strings.TrimSpace(*nameFlag)
+	input := strings.TrimSpace(flags.name)
 	if input == "" {
 		input = strings.Join(fs.Args(), " ")
 	}
-	if input == "" {
-		return errors.New("no name provided; use --name or add it after the command")
+	// A lone "-" argument reads the text from stdin.
+	stdin := flags.name == "" && fs.NArg() == 1 && fs.Arg(0) == "-"
+	if flags.file != "" {
+		if input != "" {
+			return errors.New("use either --file or --name, not both")
+		}
+		// These options need the whole input in memory; --lines only holds
+		// one line at a time.
+		if !flags.lines {
+			if err := rejectFlags(fs, "--file", "reverse", "graphemes", "normalize", "confusables", "confusable-with", "sort", "unique"); err != nil {
+				return err
+			}
+		}
+	} else if input == "" {
+		return errors.New("no name provided; use --name, add it after the command, or pass - to read stdin")
+	}
+	if flags.lines {
+		if err := rejectFlags(fs, "--lines", "graphemes", "normalize", "confusable-with", "at-byte", "at-rune"); err != nil {
+			return err
+		}
 	}
 
-	resolved, note, err := c.resolveInput(*reverseFlag, input)
+	var format export.Format
+	if f := strings.ToLower(strings.TrimSpace(flags.format)); f != "table" {
+		parsed, err := export.ParseFormat(f)
+		if err != nil {
+			return fmt.Errorf("--format: %w, or table", err)
+		}
+		format = parsed
+		tableOnly := []string{"graphemes", "normalize", "codepage", "confusables", "confusable-with", "at-byte", "at-rune", "box", "width", "columns", "sort", "unique", "filter"}
+		switch {
+		case flags.lines && format != export.NDJSON:
+			return fmt.Errorf("--lines writes a table or ndjson, not %s", format)
+		case flags.lines:
+			// Every line is its own record, so a filter can pick lines.
+			tableOnly = tableOnly[:len(tableOnly)-1]
+		case flags.file != "":
+			return fmt.Errorf("--format %s cannot be combined with --file", format)
+		}
+		if err := rejectFlags(fs, "--format "+string(format), tableOnly...); err != nil {
+			return err
+		}
+	}
+
+	encodings, err := visualiser.ParseEncodings(strings.Split(flags.encodings, ","))
 	if err != nil {
 		return err
 	}
-
-	results, err := visualiser.AnalyseString(resolved)
+	escapes, err := visualiser.ParseLanguages(strings.Split(flags.escape, ","))
 	if err != nil {
 		return err
 	}
+	target, err := newRowTarget(flags.atByte, flags.atRune)
+	if err != nil {
+		return err
+	}
+	order, err := visualiser.ParseSortOrder(flags.sort)
+	if err != nil {
+		return err
+	}
+	filter, err := visualiser.ParseFilter(flags.filter)
+	if err != nil {
+		return err
+	}
+	// Grapheme clusters and highlighted rows need every rune in input order.
+	if flags.graphemes {
+		if err := rejectFlags(fs, "--graphemes", "sort", "unique", "filter"); err != nil {
+			return err
+		}
+	}
+	if target != nil {
+		if err := rejectFlags(fs, "--at-byte or --at-rune", "unique", "filter"); err != nil {
+			return err
+		}
+	}
+	checkConfusables := flags.confusables || flags.compare != ""
+	opts := tableOptions{encodings: encodings, confusables: checkConfusables, positions: target != nil, escapes: escapes}
+	if flags.codePage != "" {
+		cp, err := codepage.Lookup(flags.codePage)
+		if err != nil {
+			return err
+		}
+		opts.codePage = cp
+	}
+	if flags.columns != "" {
+		opts.columns = strings.Split(flags.columns, ",")
+	}
+	if flags.unique {
+		opts.counts = map[int]int{}
+	}
+	cols, err := tableColumns(opts)
+	if err != nil {
+		return err
+	}
+	reordered := filter != nil || order != visualiser.SortInput || flags.unique
+	style := tableStyle{box: flags.box, maxWidth: flags.width}
+	if style.maxWidth < 0 {
+		style.maxWidth = terminalWidth()
+	}
 
-	renderTable(resolved, note, results)
-	return nil
-}
+	if flags.lines {
+		report := &lineReport{cols: cols, style: style, filter: filter, order: order, counts: opts.counts, format: format, escapes: escapes}
+		report.resolve = func(line string) (string, string, error) {
+			return c.resolveInput(flags.reverse, line, flags.maxCodePoints)
+		}
+		switch {
+		case flags.file == "-" || stdin:
+			return report.run(os.Stdin)
+		case flags.file != "":
+			f, err := os.Open(flags.file)
+			if err != nil {
+				return err
+			}
+			defer f.Close()
+			return report.run(f)
+		}
+		return report.run(strings.NewReader(input))
+	}
 
-func (c *SeeCommand) resolveInput(reverseMode, input string) (string, string, error) {
-	mode := strings.ToLower(strings.TrimSpace(reverseMode))
-	if mode == "" {
-		return input, "", nil
+	if flags.file == "-" {
+		if err := renderStream("stdin", os.Stdin, cols, style, filter, target); err != nil {
+			return err
+		}
+		return target.report()
+	}
+	if flags.file != "" {
+		f, err := os.Open(flags.file)
+		if err != nil {
+			return err
+		}
+		defer f.Close()
+		if err := renderStream(flags.file, f, cols, style, filter, target); err != nil {
+			return err
+		}
+		return target.report()
+	}
+	if stdin {
+		if input, err = readStdin(); err != nil {
+			return err
+		}
 	}
 
-	tokens := reverseinput.Tokenize(input)
-	if len(tokens) == 0 {
-		return "", "", fmt.Errorf("reverse=%s requires values", reverseMode)
+	resolved, note, err := c.resolveInput(flags.reverse, input, flags.maxCodePoints)
+	if err != nil {
+		return err
+	}
+	if format != "" {
+		return writeExport(fs, format, resolved, encodings, escapes)
 	}
 
-	switch mode {
-	case "codepoint", "codepoints", "cp":
-		built, err := reverseinput.BuildStringFromCodePoints(tokens)
+	raw := resolved
+	var results []visualiser.Result
+	if !utf8.ValidString(resolved) {
+		var invalid []visualiser.InvalidSequence
+		results, invalid, err = visualiser.AnalyseBytes([]byte(resolved))
 		if err != nil {
-			return "", "", err
+			return err
+		}
+		rows, rowsInvalid := results, invalid
+		if reordered {
+			rows = selectRows(results, filter, order, opts.counts)
+			rowsInvalid = trailingInvalid(invalid, len(rows))
 		}
-		return built, fmt.Sprintf("built from %s", reverseMode), nil
-	case "byte", "bytes":
-		built, err := reverseinput.BuildStringFromBytes(tokens)
+		renderByteTable(resolved, note, rows, rowsInvalid, len(invalid), cols, style, target)
+		// The summaries below only describe the runes that did decode.
+		resolved = strings.ToValidUTF8(resolved, "")
+	} else if flags.graphemes {
+		clusters, err := visualiser.AnalyseGraphemes(resolved)
 		if err != nil {
-			return "", "", err
+			return err
 		}
-		return built, fmt.Sprintf("built from %s", reverseMode), nil
-	default:
-		return "", "", fmt.Errorf("unknown reverse mode %q (use 'codepoints' or 'bytes')", reverseMode)
+		renderClusterTable(resolved, note, clusters, cols, style, target)
+		for _, cluster := range clusters {
+			results = append(results, cluster.Runes...)
+		}
+	} else {
+		results, err = visualiser.AnalyseString(resolved)
+		if err != nil {
+			return err
+		}
+		rows := results
+		if reordered {
+			rows = selectRows(results, filter, order, opts.counts)
+		}
+		renderTable(resolved, note, rows, cols, style, target)
+	}
+	if err := target.report(); err != nil {
+		return err
+	}
+	renderEmoji(resolved)
+	renderWarnings(results)
+	renderTextEncodings(raw)
+
+	if opts.codePage != nil {
+		renderCodePageSummary(opts.codePage, resolved)
+	}
+	if len(escapes) > 0 && resolved != "" {
+		renderEscapes(resolved, escapes)
+	}
+	if flags.normalize {
+		renderNormalization(resolved)
+	}
+	if checkConfusables {
+		renderConfusables(confusables.NewReport(resolved, flags.compare))
+	}
+	return nil
+}
+
+// writeExport prints resolved in a machine-readable format. Without
+// --encodings or --escape every encoding and escape syntax is included, so
+// the output matches /api/download for the same input byte for byte.
+func writeExport(fs *flag.FlagSet, format export.Format, resolved string, encodings []visualiser.Encoding, escapes []visualiser.Language) error {
+	if !flagSet(fs, "encodings") {
+		encodings = visualiser.AllEncodings
+	}
+	if !flagSet(fs, "escape") {
+		escapes = visualiser.AllLanguages
+	}
+	report, err := export.Analyse(resolved, escapes)
+	if err != nil {
+		return err
+	}
+	return export.Write(os.Stdout, format, report, export.Options{Encodings: encodings, Languages: escapes})
+}
+
+// flagSet reports whether the named flag was given on the command line.
+func flagSet(fs *flag.FlagSet, name string) bool {
+	found := false
+	fs.Visit(func(f *flag.Flag) {
+		found = found || f.Name == name
+	})
+	return found
+}
+
+// rejectFlags reports an error when any of the named flags was set
+// together with the option described by with, such as "--file".
+func rejectFlags(fs *flag.FlagSet, with string, names ...string) error {
+	var err error
+	fs.Visit(func(f *flag.Flag) {
+		if err == nil && slices.Contains(names, f.Name) {
+			err = fmt.Errorf("--%s cannot be combined with %s", f.Name, with)
+		}
+	})
+	return err
+}
+
+// reverseNotes says where the text of each reverse mode came from.
+var reverseNotes = map[reverseinput.Mode]string{
+	reverseinput.ModeCodePoints: "built from codepoints",
+	reverseinput.ModeBytes:      "built from bytes",
+	reverseinput.ModeUTF16:      "built from UTF-16 code units",
+	reverseinput.ModeEscaped:    "built from escaped text",
+	reverseinput.ModeNames:      "built from character names",
+	reverseinput.ModeDump:       "built from a hex dump",
+	reverseinput.ModeBase64:     "decoded from base64",
+	reverseinput.ModeBase32:     "decoded from base32",
+	reverseinput.ModeASCII85:    "decoded from Ascii85",
+	reverseinput.ModeQP:         "decoded from quoted-printable",
+	reverseinput.ModeLiteral:    "built from a byte array literal",
+}
+
+func (c *SeeCommand) resolveInput(reverseMode, input string, maxCodePoints int) (string, string, error) {
+	if strings.TrimSpace(reverseMode) == "" {
+		return input, "", nil
+	}
+	mode, ok := reverseinput.ParseMode(reverseMode)
+	if !ok {
+		return "", "", fmt.Errorf("unknown reverse mode %q (use 'codepoints', 'bytes', 'utf16', 'escaped', 'names', 'dump', 'base64', 'base32', 'ascii85', 'qp' or 'literal')", reverseMode)
+	}
+	built, err := reverseinput.Build(mode, input, maxCodePoints)
+	if err != nil {
+		return "", "", pointAt(input, err)
 	}
+	return built, reverseNotes[mode], nil
 }
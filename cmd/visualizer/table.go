@@ -0,0 +1,214 @@
+package main
+
+import (
+	"fmt"
+	"strings"
+
+	"go_tutorials/internal/visualiser"
+)
+
+// tableStyle controls how the per-character table is drawn.
+type tableStyle struct {
+	box      bool // draw box-drawing borders instead of spaces and dashes
+	maxWidth int  // terminal cells to fit the table into; 0 for no limit
+}
+
+// tableRow is one line of the table: the cells of a rune, or a label
+// followed by free text (span) such as an invalid UTF-8 sequence.
+type tableRow struct {
+	cells []string
+	span  string
+}
+
+func resultRow(label string, res visualiser.Result, cols []column) tableRow {
+	cells := []string{label}
+	for _, col := range cols {
+		cells = append(cells, col.value(res))
+	}
+	return tableRow{cells: cells}
+}
+
+func spanRow(label, text string) tableRow {
+	return tableRow{cells: []string{label}, span: text}
+}
+
+// labelTitle and labelWidth describe the leading column holding each rune.
+const (
+	labelTitle = "Letter"
+	labelWidth = 14
+)
+
+// minCellWidth is the narrowest a column is squeezed to when the table is
+// fitted to the terminal.
+const minCellWidth = 4
+
+// tableLayout holds the width in terminal cells of every column, the label
+// column first. Widths count display cells, so wide CJK characters, emoji
+// and combining marks keep the columns after them aligned.
+type tableLayout struct {
+	titles []string
+	widths []int
+	style  tableStyle
+}
+
+// fixedLayout uses the preset column widths, for tables printed while they
+// are read.
+func fixedLayout(cols []column, style tableStyle) *tableLayout {
+	l := &tableLayout{titles: []string{labelTitle}, widths: []int{labelWidth}, style: style}
+	for _, col := range cols {
+		l.titles = append(l.titles, col.title)
+		l.widths = append(l.widths, max(col.width, visualiser.StringWidth(col.title)))
+	}
+	l.fit()
+	return l
+}
+
+// autoLayout sizes every column to its title and widest cell. In box style
+// the last column also grows until every span fits inside the borders.
+func autoLayout(cols []column, rows []tableRow, style tableStyle) *tableLayout {
+	l := &tableLayout{titles: []string{labelTitle}, style: style}
+	for _, col := range cols {
+		l.titles = append(l.titles, col.title)
+	}
+	for _, title := range l.titles {
+		l.widths = append(l.widths, visualiser.StringWidth(title))
+	}
+	for _, row := range rows {
+		for i, cell := range row.cells {
+			l.widths[i] = max(l.widths[i], visualiser.StringWidth(cell))
+		}
+	}
+	if style.box && len(l.widths) > 1 {
+		for _, row := range rows {
+			if extra := visualiser.StringWidth(row.span) - l.spanWidth(); extra > 0 {
+				l.widths[len(l.widths)-1] += extra
+			}
+		}
+	}
+	l.fit()
+	return l
+}
+
+// width returns the number of cells a full row takes.
+func (l *tableLayout) width() int {
+	total := 0
+	for _, w := range l.widths {
+		total += w
+	}
+	if l.style.box {
+		return total + 3*len(l.widths) + 1
+	}
+	return total + 2*(len(l.widths)-1)
+}
+
+// spanWidth returns the cells a box-style span has: the row's width less the
+// label cell and the borders around it.
+func (l *tableLayout) spanWidth() int {
+	return max(l.width()-l.widths[0]-7, 0)
+}
+
+// fit narrows the widest columns one cell at a time until the table fits
+// into style.maxWidth or every column is down to minCellWidth. The label
+// column is never narrowed.
+func (l *tableLayout) fit() {
+	if l.style.maxWidth <= 0 {
+		return
+	}
+	for excess := l.width() - l.style.maxWidth; excess > 0; excess-- {
+		widest := -1
+		for i := 1; i < len(l.widths); i++ {
+			if w := l.widths[i]; w > minCellWidth && (widest < 0 || w >= l.widths[widest]) {
+				widest = i
+			}
+		}
+		if widest < 0 {
+			return
+		}
+		l.widths[widest]--
+	}
+}
+
+// cell pads s to width cells, cutting it with "…" when the table is fitted
+// to the terminal and s does not fit.
+func (l *tableLayout) cell(s string, width int) string {
+	if l.style.maxWidth > 0 {
+		s = visualiser.TruncateWidth(s, width)
+	}
+	return s + strings.Repeat(" ", max(0, width-visualiser.StringWidth(s)))
+}
+
+// line joins the cells of one row with the style's separators.
+func (l *tableLayout) line(cells []string) string {
+	if l.style.box {
+		return "│ " + strings.Join(cells, " │ ") + " │"
+	}
+	return strings.TrimRight(strings.Join(cells, "  "), " ")
+}
+
+// rule draws a horizontal border from box-drawing pieces.
+func (l *tableLayout) rule(left, middle, right string) string {
+	parts := make([]string, len(l.widths))
+	for i, w := range l.widths {
+		parts[i] = strings.Repeat("─", w+2)
+	}
+	return left + strings.Join(parts, middle) + right
+}
+
+func (l *tableLayout) printHeader() {
+	titles := make([]string, len(l.titles))
+	for i, title := range l.titles {
+		titles[i] = l.cell(title, l.widths[i])
+	}
+	if !l.style.box {
+		fmt.Println(l.line(titles))
+		dividers := make([]string, len(l.widths))
+		for i, w := range l.widths {
+			dividers[i] = strings.Repeat("-", w)
+		}
+		fmt.Println(strings.Join(dividers, "  "))
+		return
+	}
+	fmt.Println(l.rule("┌", "┬", "┐"))
+	fmt.Println(l.line(titles))
+	fmt.Println(l.rule("├", "┼", "┤"))
+}
+
+func (l *tableLayout) printRow(row tableRow) {
+	label := l.cell(row.cells[0], l.widths[0])
+	if row.span == "" {
+		cells := []string{label}
+		for i, value := range row.cells[1:] {
+			cells = append(cells, l.cell(value, l.widths[i+1]))
+		}
+		fmt.Println(l.line(cells))
+		return
+	}
+	switch {
+	case l.style.box:
+		// The text spans every column after the label. It is cut even without
+		// a width limit, so the right border stays in line when the columns
+		// were not sized to it, as in a streamed table.
+		width := l.spanWidth()
+		fmt.Println(l.line([]string{label, l.cell(visualiser.TruncateWidth(row.span, width), width)}))
+	case l.style.maxWidth > 0:
+		fmt.Println(l.line([]string{label, visualiser.TruncateWidth(row.span, l.style.maxWidth-l.widths[0]-2)}))
+	default:
+		fmt.Println(l.line([]string{label, row.span}))
+	}
+}
+
+func (l *tableLayout) printFooter() {
+	if l.style.box {
+		fmt.Println(l.rule("└", "┴", "┘"))
+	}
+}
+
+// printTable sizes the columns to rows and prints the whole table.
+func printTable(cols []column, rows []tableRow, style tableStyle) {
+	l := autoLayout(cols, rows, style)
+	l.printHeader()
+	for _, row := range rows {
+		l.printRow(row)
+	}
+	l.printFooter()
+}
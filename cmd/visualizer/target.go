@@ -0,0 +1,97 @@
+package main
+
+import (
+	"fmt"
+	"strings"
+
+	"go_tutorials/internal/visualiser"
+)
+
+// rowTarget highlights the table row at a byte offset or rune index, as set
+// by --at-byte and --at-rune. A nil target highlights nothing.
+type rowTarget struct {
+	byteOffset int // -1 unless --at-byte was given
+	runeIndex  int // -1 unless --at-rune was given
+
+	hit        *visualiser.Result
+	hitInvalid *visualiser.InvalidSequence
+	bytes      int // bytes seen, for reporting targets past the end
+	runes      int // runes seen, likewise
+}
+
+// newRowTarget returns nil when neither flag is set.
+func newRowTarget(atByte, atRune int) (*rowTarget, error) {
+	switch {
+	case atByte >= 0 && atRune >= 0:
+		return nil, fmt.Errorf("use either --at-byte or --at-rune, not both")
+	case atByte < -1 || atRune < -1:
+		return nil, fmt.Errorf("--at-byte and --at-rune must not be negative")
+	case atByte < 0 && atRune < 0:
+		return nil, nil
+	}
+	return &rowTarget{byteOffset: atByte, runeIndex: atRune}, nil
+}
+
+// label returns the row label for res, marking it when it is the target.
+// prefix indents rows nested under a grapheme cluster.
+func (t *rowTarget) label(res visualiser.Result, prefix string) string {
+	if t == nil {
+		return prefix + res.Character
+	}
+	t.bytes = max(t.bytes, res.ByteOffset+len(res.UTF8BytesHex))
+	t.runes = max(t.runes, res.RuneIndex+1)
+	var match bool
+	if t.byteOffset >= 0 {
+		match = t.byteOffset >= res.ByteOffset && t.byteOffset < res.ByteOffset+len(res.UTF8BytesHex)
+	} else {
+		match = res.RuneIndex == t.runeIndex
+	}
+	if !match {
+		return prefix + res.Character
+	}
+	t.hit = &res
+	return "▶ " + strings.TrimLeft(prefix, " ") + res.Character
+}
+
+// invalidLabel returns the row label for a malformed sequence, marking it
+// when --at-byte points into it.
+func (t *rowTarget) invalidLabel(inv visualiser.InvalidSequence) string {
+	if t == nil {
+		return "<invalid>"
+	}
+	t.bytes = max(t.bytes, inv.Offset+len(inv.Bytes))
+	if t.byteOffset >= inv.Offset && t.byteOffset < inv.Offset+len(inv.Bytes) {
+		t.hitInvalid = &inv
+		return "▶ <invalid>"
+	}
+	return "<invalid>"
+}
+
+// report prints which row matched, or an error when nothing did.
+func (t *rowTarget) report() error {
+	if t == nil {
+		return nil
+	}
+	fmt.Println()
+	switch {
+	case t.hit != nil:
+		res := t.hit
+		what := fmt.Sprintf("rune %d", t.runeIndex)
+		if t.byteOffset >= 0 {
+			what = fmt.Sprintf("byte %d", t.byteOffset)
+		}
+		fmt.Printf("▶ %s is %s %s (rune %d, bytes %d-%d) at line %d, column %d (UTF-16 column %d)\n",
+			what, res.Character, res.CodePointHex, res.RuneIndex, res.ByteOffset, res.ByteOffset+len(res.UTF8BytesHex)-1,
+			res.Line, res.ColumnUTF8, res.ColumnUTF16)
+		return nil
+	case t.hitInvalid != nil:
+		inv := t.hitInvalid
+		fmt.Printf("▶ byte %d is part of an invalid UTF-8 sequence at offset %d: %s (%s)\n",
+			t.byteOffset, inv.Offset, strings.Join(inv.Bytes, " "), inv.Reason)
+		return nil
+	case t.byteOffset >= 0:
+		return fmt.Errorf("byte %d is beyond the end of the input (%d bytes)", t.byteOffset, t.bytes)
+	default:
+		return fmt.Errorf("rune %d is beyond the end of the input (%d runes)", t.runeIndex, t.runes)
+	}
+}
@@ -0,0 +1,26 @@
+package main
+
+import (
+	"os"
+	"strconv"
+)
+
+// terminalWidth returns the width of the terminal stdout is attached to, or
+// 0 when stdout is redirected, so piped tables are never cut. $COLUMNS is
+// used when the terminal does not report its size.
+func terminalWidth() int {
+	if !isTerminal(os.Stdout) {
+		return 0
+	}
+	if width := windowWidth(os.Stdout); width > 0 {
+		return width
+	}
+	width, _ := strconv.Atoi(os.Getenv("COLUMNS"))
+	return max(width, 0)
+}
+
+// isTerminal reports whether f is a character device such as a terminal.
+func isTerminal(f *os.File) bool {
+	info, err := f.Stat()
+	return err == nil && info.Mode()&os.ModeCharDevice != 0
+}
@@ -0,0 +1,18 @@
+//go:build !(darwin || dragonfly || freebsd || linux || netbsd || openbsd)
+
+package main
+
+import (
+	"errors"
+	"os"
+)
+
+// windowWidth is not available here; terminalWidth falls back to $COLUMNS.
+func windowWidth(*os.File) int {
+	return 0
+}
+
+// enableRawMode is not available here; the REPL reads plain lines instead.
+func enableRawMode(*os.File) (func(), error) {
+	return nil, errors.New("raw terminal mode is not supported on this platform")
+}
@@ -8,13 +8,42 @@ func printUsage() {
 Usage:
   go run ./cmd/visualizer see --name "Ada Lovelace"
   go run ./cmd/visualizer see --reverse=codepoints --name "U+0041 0x0042 67"
+  go run ./cmd/visualizer see --reverse=codepoints --name "U+0391..U+03A9 U+1F600..+16"
   go run ./cmd/visualizer see --reverse=bytes --name "0xF0 0x9F 0x98 0x8A"
+  go run ./cmd/visualizer see --reverse=utf16 --name "0xD83D 0xDE0A"
+  go run ./cmd/visualizer see --reverse=names --name "LATIN SMALL LETTER E WITH ACUTE; SNOWMAN"
+  go run ./cmd/visualizer lookup arrow
+  go run ./cmd/visualizer see --reverse=escaped --name 'caf\u00e9 &eacute; &#x1F600;'
+  go run ./cmd/visualizer see --graphemes --name "🇮🇳 👩🏽‍💻"
+  go run ./cmd/visualizer see --name "👩🏽‍💻 #️⃣"
+  go run ./cmd/visualizer see --encodings utf8,utf16le,utf32be --name "A😊"
+  go run ./cmd/visualizer see --escape go,json,c --name "café 😀"
+  go run ./cmd/visualizer see --normalize --name "café ﬁle"
+  go run ./cmd/visualizer see --reverse=codepoints --name "U+0069 U+0066 U+202E U+0078"
+  go run ./cmd/visualizer see --confusable-with paypal --name "pаypаl"
+  go run ./cmd/visualizer see --file server.log
+  echo "U+0041 U+00E9" | go run ./cmd/visualizer see --reverse=codepoints -
+  go run ./cmd/visualizer see --lines --file export.csv --filter non-ascii
+  go run ./cmd/visualizer see --lines --format ndjson - < export.csv
+  go run ./cmd/visualizer see --at-byte 3 --name "a😊b"
+  go run ./cmd/visualizer see --box --width 120 --name "日本語 😀"
+  go run ./cmd/visualizer see --columns char,cp,utf8hex --unique --sort codepoint --filter non-ascii --name "déjà vu"
+  go run ./cmd/visualizer see --format json --name "café" | jq '.items[].Name'
   go run ./cmd/visualizer decode --hex "41 64 61"
+  go run ./cmd/visualizer decode --file server.log
   go run ./cmd/visualizer decode --bin "01000001 01100100 01100001"
+  xxd capture.bin | go run ./cmd/visualizer decode --dump
+  go run ./cmd/visualizer decode --base64 "eyJhbGciOiJIUzI1NiJ9"
+  go run ./cmd/visualizer decode --literal "new byte[]{-61, -87}"
+  go run ./cmd/visualizer see --codepage windows-1252 --name "café €"
+  go run ./cmd/visualizer decode --from windows-1252 --hex "63 61 66 E9"
+  go run ./cmd/visualizer repl
 
 Commands:
   see       Show the hex and binary representation of every letter in a name.
-  decode    Convert hex or binary bytes back into UTF-8 text.
+  decode    Convert hex, binary, base64, hex dump or file bytes back into UTF-8 text.
+  lookup    Search Unicode character names, e.g. lookup arrow.
+  repl      Start an interactive session that shows each line you type.
   serve     Launch the HTTP API server (defaults to :8080).
   help-serve Print usage for the serve command.
 `)
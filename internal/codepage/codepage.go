@@ -0,0 +1,123 @@
+// Package codepage encodes and decodes legacy single-byte character sets
+// (ISO-8859-x, Windows-125x, CP437, KOI8-R) using generated 256-entry tables.
+package codepage
+
+import (
+	"fmt"
+	"strings"
+	"sync"
+	"unicode/utf8"
+)
+
+// undefined marks table slots whose byte has no assigned character.
+const undefined = -1
+
+// CodePage is a single-byte character set backed by a decode table.
+type CodePage struct {
+	Name    string   // canonical name, e.g. windows-1252
+	Aliases []string // alternative names accepted by Lookup
+
+	table      [256]rune
+	encodeOnce sync.Once
+	encode     map[rune]byte
+}
+
+// Mapping reports how one rune maps into a code page.
+type Mapping struct {
+	Rune rune
+	Byte byte
+	OK   bool // false when the code page cannot represent Rune
+}
+
+// Lookup finds a code page by canonical name or alias. Matching ignores case,
+// spaces, dashes and underscores, so "ISO_8859-1" and "latin1" both work.
+func Lookup(name string) (*CodePage, error) {
+	key := normaliseName(name)
+	for _, cp := range codePages {
+		if normaliseName(cp.Name) == key {
+			return cp, nil
+		}
+		for _, alias := range cp.Aliases {
+			if normaliseName(alias) == key {
+				return cp, nil
+			}
+		}
+	}
+	return nil, fmt.Errorf("unknown code page %q (known: %s)", name, strings.Join(Names(), ", "))
+}
+
+// Names lists the canonical names of every supported code page.
+func Names() []string {
+	names := make([]string, len(codePages))
+	for i, cp := range codePages {
+		names[i] = cp.Name
+	}
+	return names
+}
+
+func normaliseName(name string) string {
+	return strings.NewReplacer("-", "", "_", "", " ", "").Replace(strings.ToLower(strings.TrimSpace(name)))
+}
+
+// DecodeByte returns the character assigned to b, or false when the byte is
+// undefined in this code page.
+func (cp *CodePage) DecodeByte(b byte) (rune, bool) {
+	r := cp.table[b]
+	return r, r != undefined
+}
+
+// EncodeRune returns the byte for r, or false when the code page cannot
+// represent it.
+func (cp *CodePage) EncodeRune(r rune) (byte, bool) {
+	cp.encodeOnce.Do(func() {
+		cp.encode = make(map[rune]byte, 256)
+		// Walk backwards so the lowest byte wins for duplicated characters.
+		for i := 255; i >= 0; i-- {
+			if cp.table[i] != undefined {
+				cp.encode[cp.table[i]] = byte(i)
+			}
+		}
+	})
+	b, ok := cp.encode[r]
+	return b, ok
+}
+
+// Encode maps every rune of s into the code page. Runes the code page cannot
+// represent are reported with OK set to false instead of being replaced.
+func (cp *CodePage) Encode(s string) []Mapping {
+	mappings := make([]Mapping, 0, utf8.RuneCountInString(s))
+	for _, r := range s {
+		b, ok := cp.EncodeRune(r)
+		mappings = append(mappings, Mapping{Rune: r, Byte: b, OK: ok})
+	}
+	return mappings
+}
+
+// Decode converts bytes from the code page into a UTF-8 string. Undefined
+// bytes become U+FFFD and their offsets are returned so callers can flag them.
+func (cp *CodePage) Decode(b []byte) (string, []int) {
+	var (
+		sb      strings.Builder
+		offsets []int
+	)
+	for i, by := range b {
+		r, ok := cp.DecodeByte(by)
+		if !ok {
+			r = utf8.RuneError
+			offsets = append(offsets, i)
+		}
+		sb.WriteRune(r)
+	}
+	return sb.String(), offsets
+}
+
+// Unmappable counts the mappings that the code page could not represent.
+func Unmappable(mappings []Mapping) int {
+	count := 0
+	for _, m := range mappings {
+		if !m.OK {
+			count++
+		}
+	}
+	return count
+}
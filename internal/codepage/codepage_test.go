@@ -0,0 +1,69 @@
+package codepage
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestLookup(t *testing.T) {
+	for _, name := range []string{"windows-1252", "CP1252", "Windows_1252"} {
+		cp, err := Lookup(name)
+		if err != nil {
+			t.Fatalf("Lookup(%q) returned error: %v", name, err)
+		}
+		if cp.Name != "windows-1252" {
+			t.Fatalf("Lookup(%q): expected windows-1252, got %s", name, cp.Name)
+		}
+	}
+	if cp, err := Lookup("latin1"); err != nil || cp.Name != "iso-8859-1" {
+		t.Fatalf("expected latin1 alias to resolve to iso-8859-1, got %v, %v", cp, err)
+	}
+	if _, err := Lookup("ebcdic"); err == nil {
+		t.Fatalf("expected error for unknown code page")
+	}
+}
+
+func TestEncodeMarksUnmappable(t *testing.T) {
+	cp, _ := Lookup("windows-1252")
+	mappings := cp.Encode("é€ж")
+	want := []Mapping{
+		{Rune: 'é', Byte: 0xE9, OK: true},
+		{Rune: '€', Byte: 0x80, OK: true},
+		{Rune: 'ж', OK: false},
+	}
+	if !reflect.DeepEqual(mappings, want) {
+		t.Fatalf("want %+v, got %+v", want, mappings)
+	}
+	if n := Unmappable(mappings); n != 1 {
+		t.Fatalf("expected 1 unmappable rune, got %d", n)
+	}
+}
+
+func TestDecode(t *testing.T) {
+	cases := []struct {
+		name  string
+		bytes []byte
+		want  string
+	}{
+		{"koi8-r", []byte{0xF0, 0xD2, 0xC9}, "При"},
+		{"cp437", []byte{0xC9, 0xCD, 0xBB}, "╔═╗"},
+		{"iso-8859-7", []byte{0xE1, 0xE2}, "αβ"},
+		{"windows-1251", []byte{0xC0}, "А"},
+	}
+	for _, tc := range cases {
+		cp, err := Lookup(tc.name)
+		if err != nil {
+			t.Fatalf("Lookup(%q) returned error: %v", tc.name, err)
+		}
+		got, undefined := cp.Decode(tc.bytes)
+		if got != tc.want || len(undefined) != 0 {
+			t.Errorf("%s: want %q, got %q (undefined %v)", tc.name, tc.want, got, undefined)
+		}
+	}
+
+	cp, _ := Lookup("windows-1252")
+	got, undefined := cp.Decode([]byte{0x41, 0x81})
+	if got != "A�" || !reflect.DeepEqual(undefined, []int{1}) {
+		t.Fatalf("expected undefined byte at offset 1, got %q %v", got, undefined)
+	}
+}
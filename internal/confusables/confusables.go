@@ -0,0 +1,167 @@
+// Package confusables detects homoglyphs using UTS #39 skeletons. The
+// embedded table holds the single code point mappings of Unicode's
+// confusables.txt for the Unicode version of package ucd.
+package confusables
+
+import (
+	_ "embed"
+	"fmt"
+	"strconv"
+	"strings"
+	"sync"
+	"unicode"
+
+	"go_tutorials/internal/normalize"
+	"go_tutorials/internal/ucd"
+)
+
+//go:embed confusables.txt
+var confusableData string
+
+var (
+	dataOnce   sync.Once
+	prototypes map[rune]string
+)
+
+// loadData parses confusables.txt. Lines read SOURCE;TARGET where TARGET is
+// one or more space separated code points; lines starting with # are
+// comments.
+func loadData() {
+	prototypes = make(map[rune]string, strings.Count(confusableData, "\n"))
+	for _, line := range strings.Split(confusableData, "\n") {
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		fields := strings.Split(line, ";")
+		if len(fields) != 2 {
+			panic(fmt.Sprintf("confusables: malformed line %q", line))
+		}
+		var target strings.Builder
+		for _, part := range strings.Fields(fields[1]) {
+			target.WriteRune(parseHex(part))
+		}
+		prototypes[parseHex(fields[0])] = target.String()
+	}
+}
+
+func parseHex(s string) rune {
+	v, err := strconv.ParseUint(s, 16, 32)
+	if err != nil {
+		panic(fmt.Sprintf("confusables: malformed code point %q", s))
+	}
+	return rune(v)
+}
+
+// Skeleton returns the UTS #39 skeleton of s: NFD, then every code point
+// replaced by its prototype, then NFD again. Two strings are confusable when
+// their skeletons are equal.
+func Skeleton(s string) string {
+	dataOnce.Do(loadData)
+	var sb strings.Builder
+	for _, r := range normalize.NFD.Normalize(s) {
+		if p, ok := prototypes[r]; ok {
+			sb.WriteString(p)
+			continue
+		}
+		sb.WriteRune(r)
+	}
+	return normalize.NFD.Normalize(sb.String())
+}
+
+// Equivalent reports whether a and b are visually equivalent, that is
+// whether they share a skeleton.
+func Equivalent(a, b string) bool {
+	return Skeleton(a) == Skeleton(b)
+}
+
+// LooksLike returns the Latin text that r can be mistaken for, or false when
+// r is ASCII or its skeleton is not made of Latin letters and digits. ASCII
+// runes still take part in skeletons (so "l" and "I" match) but are never
+// flagged on their own, and neither are lookalikes of punctuation or of
+// letters from other scripts.
+func LooksLike(r rune) (string, bool) {
+	if r < 0x80 {
+		return "", false
+	}
+	s := string(r)
+	skeleton := Skeleton(s)
+	if skeleton == normalize.NFD.Normalize(s) || !latinAlnum(skeleton) {
+		return "", false
+	}
+	return normalize.NFC.Normalize(skeleton), true
+}
+
+// latinAlnum reports whether s holds ASCII letters or digits, possibly with
+// combining marks on them.
+func latinAlnum(s string) bool {
+	letters := 0
+	for _, r := range s {
+		switch {
+		case r < 0x80 && (unicode.IsLetter(r) || unicode.IsDigit(r)):
+			letters++
+		case !unicode.Is(unicode.Mn, r):
+			return false
+		}
+	}
+	return letters > 0
+}
+
+// Flag describes one rune that resembles a Latin character.
+type Flag struct {
+	Index         int    // rune index within the input
+	Character     string // the rune formatted via %q
+	CodePoint     string // U+XXXX
+	Script        string // script of the flagged rune
+	LooksLike     string // the Latin text it resembles
+	LooksLikeName string // names of the runes of LooksLike
+}
+
+// Report is the confusable analysis of an input, optionally compared with a
+// second string.
+type Report struct {
+	Skeleton        string // UTS #39 skeleton of the input
+	Flags           []Flag // runes with a Latin lookalike
+	CompareWith     string // second string, empty when no comparison was asked for
+	CompareSkeleton string // skeleton of CompareWith
+	Equivalent      bool   // true when both skeletons match
+}
+
+// Analyse flags every rune of s that has a Latin lookalike.
+func Analyse(s string) []Flag {
+	flags := []Flag{}
+	index := 0
+	for _, r := range s {
+		if looks, ok := LooksLike(r); ok {
+			flags = append(flags, Flag{
+				Index:         index,
+				Character:     fmt.Sprintf("%q", r),
+				CodePoint:     fmt.Sprintf("U+%04X", r),
+				Script:        ucd.Script(r),
+				LooksLike:     looks,
+				LooksLikeName: names(looks),
+			})
+		}
+		index++
+	}
+	return flags
+}
+
+// NewReport analyses s and, when compareWith is not empty, checks whether
+// the two strings are visually equivalent.
+func NewReport(s, compareWith string) Report {
+	report := Report{Skeleton: Skeleton(s), Flags: Analyse(s)}
+	if compareWith != "" {
+		report.CompareWith = compareWith
+		report.CompareSkeleton = Skeleton(compareWith)
+		report.Equivalent = report.Skeleton == report.CompareSkeleton
+	}
+	return report
+}
+
+func names(s string) string {
+	parts := make([]string, 0, len(s))
+	for _, r := range s {
+		parts = append(parts, ucd.Name(r))
+	}
+	return strings.Join(parts, " + ")
+}
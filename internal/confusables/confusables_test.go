@@ -0,0 +1,73 @@
+package confusables
+
+import "testing"
+
+func TestSkeleton(t *testing.T) {
+	// Expected values are UTS #39 skeletons as ICU's uspoof_getSkeleton
+	// computes them.
+	cases := map[string]string{
+		"paypal":             "paypal",
+		"p\u0430yp\u0430l":   "paypal",
+		"\uff50\uff41\uff59": "pay",
+		"I1|l":               "llll",
+		"microsoft":          "rnicrosoft",
+		"rnicrosoft":         "rnicrosoft",
+		"g00gle":             "gOOgle",
+		"\u0261oogle":        "google",
+		"%":                  "\u00ba/\u2080",
+		"\u03f2\u2c9f\u1d0f": "coo",
+		"\u13a0\u0269\u0d20": "Dio",
+		"\u0441af\u00e9":     "cafe\u0301",
+		"\U0001d41a":         "a",
+	}
+	for input, want := range cases {
+		if got := Skeleton(input); got != want {
+			t.Errorf("Skeleton(%+q): want %+q, got %+q", input, want, got)
+		}
+	}
+}
+
+func TestEquivalent(t *testing.T) {
+	if !Equivalent("p\u0430yp\u0430l", "paypal") {
+		t.Errorf("expected Cyrillic a (U+0430) to be confusable with Latin a")
+	}
+	if !Equivalent("rnicrosoft", "microsoft") {
+		t.Errorf("expected rn to be confusable with m")
+	}
+	if Equivalent("paypal", "paypa1x") {
+		t.Errorf("expected different strings not to be equivalent")
+	}
+}
+
+func TestLooksLike(t *testing.T) {
+	if got, ok := LooksLike('\u0435'); !ok || got != "e" {
+		t.Errorf("LooksLike(U+0435): want e, got %q (%v)", got, ok)
+	}
+	if got, ok := LooksLike('\u0450'); !ok || got != "\u00e8" {
+		t.Errorf("LooksLike(U+0450): want è, got %q (%v)", got, ok)
+	}
+	// Lookalikes of punctuation are in the table but are not flagged.
+	for _, r := range []rune{'a', 'I', '\u00e9', '\u4e2d', '\u2024'} {
+		if got, ok := LooksLike(r); ok {
+			t.Errorf("LooksLike(%U): expected no flag, got %q", r, got)
+		}
+	}
+}
+
+func TestNewReport(t *testing.T) {
+	report := NewReport("p\u0430ypal", "paypal")
+	if !report.Equivalent || report.Skeleton != "paypal" {
+		t.Errorf("unexpected report: %+v", report)
+	}
+	if len(report.Flags) != 1 {
+		t.Fatalf("expected 1 flag, got %d", len(report.Flags))
+	}
+	flag := report.Flags[0]
+	if flag.Index != 1 || flag.CodePoint != "U+0430" || flag.Script != "Cyrillic" ||
+		flag.LooksLike != "a" || flag.LooksLikeName != "LATIN SMALL LETTER A" {
+		t.Errorf("unexpected flag: %+v", flag)
+	}
+	if report := NewReport("paypal", ""); report.CompareWith != "" || report.Equivalent || len(report.Flags) != 0 {
+		t.Errorf("unexpected report without comparison: %+v", report)
+	}
+}
@@ -0,0 +1,265 @@
+// Package emoji breaks emoji sequences (UTS #51) into their parts: ZWJ
+// sequences, skin tone modifiers, regional-indicator flags, keycaps, tag
+// sequences and presentation selectors. Names come from the embedded
+// emoji-test.txt data, with a descriptive fallback for sequences it omits.
+package emoji
+
+import (
+	_ "embed"
+	"fmt"
+	"strings"
+	"sync"
+	"unicode/utf8"
+
+	"go_tutorials/internal/ucd"
+	"go_tutorials/internal/visualiser"
+)
+
+//go:embed sequences.txt
+var sequenceData string
+
+var (
+	namesOnce sync.Once
+	names     map[string]string
+)
+
+// loadNames parses sequences.txt. Lines read SEQUENCE;NAME where SEQUENCE
+// is space separated hex with U+FE0F removed.
+func loadNames() {
+	names = make(map[string]string, strings.Count(sequenceData, "\n"))
+	for _, line := range strings.Split(sequenceData, "\n") {
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		key, name, ok := strings.Cut(line, ";")
+		if !ok {
+			panic(fmt.Sprintf("emoji: malformed line %q", line))
+		}
+		names[key] = name
+	}
+}
+
+// Special code points that shape an emoji sequence.
+const (
+	zwj           = 0x200D
+	textSelector  = 0xFE0E
+	emojiSelector = 0xFE0F
+	keycap        = 0x20E3
+	cancelTag     = 0xE007F
+	regionalA     = 0x1F1E6
+	regionalZ     = 0x1F1FF
+	skinToneFirst = 0x1F3FB
+	skinToneLast  = 0x1F3FF
+	hairFirst     = 0x1F9B0
+	hairLast      = 0x1F9B3
+	tagFirst      = 0xE0020
+	tagLast       = 0xE007E
+)
+
+// Kinds of emoji sequence; one sequence can have several.
+const (
+	KindZWJ               = "zwj"
+	KindSkinTone          = "skin-tone"
+	KindFlag              = "flag"
+	KindKeycap            = "keycap"
+	KindTag               = "tag"
+	KindEmojiPresentation = "emoji-presentation"
+	KindTextPresentation  = "text-presentation"
+)
+
+var skinTones = [...]string{"light", "medium-light", "medium", "medium-dark", "dark"}
+
+var hairStyles = [...]string{"red hair", "curly hair", "bald", "white hair"}
+
+// Part is one rune of an emoji sequence and the job it does there.
+type Part struct {
+	Character string // rune formatted via %q
+	CodePoint string // U+XXXX
+	Name      string // Unicode character name
+	Role      string // e.g. "emoji", "skin tone: medium", "zero width joiner"
+}
+
+// Sequence describes one emoji, possibly made of several runes.
+type Sequence struct {
+	Text       string   // whole sequence formatted via %q
+	Name       string   // CLDR short name, e.g. "woman technologist: medium skin tone"
+	Kinds      []string // zwj, skin-tone, flag, keycap, tag, emoji-presentation, text-presentation
+	RuneIndex  int      // index of the first rune within the input
+	Components []string // names of the ZWJ-joined elements; empty unless Kinds has zwj
+	Parts      []Part   // every rune in order
+}
+
+// Analyse finds the emoji sequences in s. Plain single-rune emoji are
+// skipped unless all is set, since their name already appears per rune.
+func Analyse(s string, all bool) []Sequence {
+	var sequences []Sequence
+	index := 0
+	for _, cluster := range visualiser.Graphemes(s) {
+		if seq, ok := Describe(cluster); ok && (all || len(seq.Parts) > 1) {
+			seq.RuneIndex = index
+			sequences = append(sequences, seq)
+		}
+		index += utf8.RuneCountInString(cluster)
+	}
+	return sequences
+}
+
+// Describe breaks one grapheme cluster into an emoji sequence, or reports
+// false when the cluster is not an emoji.
+func Describe(cluster string) (Sequence, bool) {
+	runes := []rune(cluster)
+	if !isEmoji(runes) {
+		return Sequence{}, false
+	}
+	seq := Sequence{Text: fmt.Sprintf("%q", cluster)}
+	kinds := map[string]bool{}
+	for i, r := range runes {
+		role := roleOf(r, runes, i)
+		switch {
+		case r == zwj:
+			kinds[KindZWJ] = true
+		case r == emojiSelector:
+			kinds[KindEmojiPresentation] = true
+		case r == textSelector:
+			kinds[KindTextPresentation] = true
+		case r == keycap:
+			kinds[KindKeycap] = true
+		case isSkinTone(r):
+			kinds[KindSkinTone] = true
+		case isRegional(r):
+			kinds[KindFlag] = true
+		case isTag(r) || r == cancelTag:
+			kinds[KindTag] = true
+		}
+		seq.Parts = append(seq.Parts, Part{
+			Character: fmt.Sprintf("%q", r),
+			CodePoint: fmt.Sprintf("U+%04X", r),
+			Name:      ucd.Name(r),
+			Role:      role,
+		})
+	}
+	for _, kind := range []string{KindZWJ, KindSkinTone, KindFlag, KindKeycap, KindTag, KindEmojiPresentation, KindTextPresentation} {
+		if kinds[kind] {
+			seq.Kinds = append(seq.Kinds, kind)
+		}
+	}
+	if kinds[KindZWJ] {
+		for _, element := range splitZWJ(runes) {
+			seq.Components = append(seq.Components, nameOf(element))
+		}
+	}
+	seq.Name = nameOf(runes)
+	return seq, true
+}
+
+func isEmoji(runes []rune) bool {
+	for _, r := range runes {
+		if ucd.IsExtendedPictographic(r) || isRegional(r) || r == keycap || r == emojiSelector {
+			return true
+		}
+	}
+	return false
+}
+
+func roleOf(r rune, runes []rune, i int) string {
+	switch {
+	case r == zwj:
+		return "zero width joiner"
+	case r == emojiSelector:
+		return "emoji presentation selector (VS16)"
+	case r == textSelector:
+		return "text presentation selector (VS15)"
+	case r == keycap:
+		return "combining enclosing keycap"
+	case isSkinTone(r):
+		return "skin tone: " + skinTones[r-skinToneFirst]
+	case r >= hairFirst && r <= hairLast:
+		return "hair style: " + hairStyles[r-hairFirst]
+	case isRegional(r):
+		return fmt.Sprintf("regional indicator %c", 'A'+(r-regionalA))
+	case isTag(r):
+		return fmt.Sprintf("tag %q", r-0xE0000)
+	case r == cancelTag:
+		return "cancel tag"
+	case i+1 < len(runes) && strings.ContainsRune(string(runes[i+1:]), keycap):
+		return "keycap base"
+	case ucd.IsExtendedPictographic(r):
+		return "emoji"
+	}
+	return "character"
+}
+
+// nameOf names a sequence from the emoji data, falling back to a name built
+// from its parts.
+func nameOf(runes []rune) string {
+	namesOnce.Do(loadNames)
+	keys := make([]string, 0, len(runes))
+	var base []rune
+	for _, r := range runes {
+		if r == emojiSelector {
+			continue
+		}
+		keys = append(keys, fmt.Sprintf("%04X", r))
+		if r != textSelector {
+			base = append(base, r)
+		}
+	}
+	if name, ok := names[strings.Join(keys, " ")]; ok {
+		return name
+	}
+	// Text-presentation forms share the emoji's name.
+	if name, ok := names[hexKey(base)]; ok {
+		return name
+	}
+
+	switch {
+	case len(base) == 2 && isRegional(base[0]) && isRegional(base[1]):
+		return fmt.Sprintf("flag: %c%c (unrecognised region)", 'A'+(base[0]-regionalA), 'A'+(base[1]-regionalA))
+	case len(base) == 2 && base[1] == keycap:
+		return fmt.Sprintf("keycap: %c", base[0])
+	case len(base) == 2 && isSkinTone(base[1]):
+		return fmt.Sprintf("%s: %s skin tone", nameOf(base[:1]), skinTones[base[1]-skinToneFirst])
+	case strings.ContainsRune(string(base), zwj):
+		elements := splitZWJ(base)
+		parts := make([]string, len(elements))
+		for i, element := range elements {
+			parts[i] = nameOf(element)
+		}
+		return strings.Join(parts, " + ")
+	case len(base) == 1:
+		return strings.ToLower(ucd.Name(base[0]))
+	}
+	parts := make([]string, len(base))
+	for i, r := range base {
+		parts[i] = strings.ToLower(ucd.Name(r))
+	}
+	return strings.Join(parts, " + ")
+}
+
+func hexKey(runes []rune) string {
+	keys := make([]string, len(runes))
+	for i, r := range runes {
+		keys[i] = fmt.Sprintf("%04X", r)
+	}
+	return strings.Join(keys, " ")
+}
+
+func splitZWJ(runes []rune) [][]rune {
+	var (
+		elements [][]rune
+		current  []rune
+	)
+	for _, r := range runes {
+		if r == zwj {
+			elements = append(elements, current)
+			current = nil
+			continue
+		}
+		current = append(current, r)
+	}
+	return append(elements, current)
+}
+
+func isSkinTone(r rune) bool { return r >= skinToneFirst && r <= skinToneLast }
+func isRegional(r rune) bool { return r >= regionalA && r <= regionalZ }
+func isTag(r rune) bool      { return r >= tagFirst && r <= tagLast }
@@ -0,0 +1,80 @@
+package emoji
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestDescribe(t *testing.T) {
+	cases := []struct {
+		input string
+		name  string
+		kinds []string
+		roles []string
+	}{
+		{"\U0001F469\U0001F3FD\u200d\U0001F4BB", "woman technologist: medium skin tone",
+			[]string{KindZWJ, KindSkinTone},
+			[]string{"emoji", "skin tone: medium", "zero width joiner", "emoji"}},
+		{"\U0001F1EE\U0001F1F3", "flag: India", []string{KindFlag},
+			[]string{"regional indicator I", "regional indicator N"}},
+		{"#\ufe0f\u20e3", "keycap: #", []string{KindKeycap, KindEmojiPresentation},
+			[]string{"keycap base", "emoji presentation selector (VS16)", "combining enclosing keycap"}},
+		{"\u2764\ufe0f", "red heart", []string{KindEmojiPresentation},
+			[]string{"emoji", "emoji presentation selector (VS16)"}},
+		{"\u2764\ufe0e", "red heart", []string{KindTextPresentation},
+			[]string{"emoji", "text presentation selector (VS15)"}},
+		{"\U0001F3F4\U000E0067\U000E0062\U000E0073\U000E0063\U000E0074\U000E007F", "flag: Scotland", []string{KindTag},
+			[]string{"emoji", "tag 'g'", "tag 'b'", "tag 's'", "tag 'c'", "tag 't'", "cancel tag"}},
+		{"\U0001F1E6\U0001F1E6", "flag: AA (unrecognised region)", []string{KindFlag},
+			[]string{"regional indicator A", "regional indicator A"}},
+	}
+	for _, tc := range cases {
+		seq, ok := Describe(tc.input)
+		if !ok {
+			t.Errorf("Describe(%+q): expected an emoji", tc.input)
+			continue
+		}
+		if seq.Name != tc.name {
+			t.Errorf("Describe(%+q): want name %q, got %q", tc.input, tc.name, seq.Name)
+		}
+		if !reflect.DeepEqual(seq.Kinds, tc.kinds) {
+			t.Errorf("Describe(%+q): want kinds %v, got %v", tc.input, tc.kinds, seq.Kinds)
+		}
+		var roles []string
+		for _, p := range seq.Parts {
+			roles = append(roles, p.Role)
+		}
+		if !reflect.DeepEqual(roles, tc.roles) {
+			t.Errorf("Describe(%+q): want roles %v, got %v", tc.input, tc.roles, roles)
+		}
+	}
+	for _, input := range []string{"a", "e\u0301", "1"} {
+		if _, ok := Describe(input); ok {
+			t.Errorf("Describe(%+q): expected no emoji", input)
+		}
+	}
+}
+
+func TestDescribeComponents(t *testing.T) {
+	seq, _ := Describe("\U0001F469\U0001F3FD\u200d\U0001F4BB")
+	want := []string{"woman: medium skin tone", "laptop"}
+	if !reflect.DeepEqual(seq.Components, want) {
+		t.Errorf("want components %v, got %v", want, seq.Components)
+	}
+	// An unlisted ZWJ sequence is named from its elements.
+	seq, _ = Describe("\U0001F600\u200d\U0001F4BB")
+	if seq.Name != "grinning face + laptop" {
+		t.Errorf("unexpected fallback name %q", seq.Name)
+	}
+}
+
+func TestAnalyse(t *testing.T) {
+	input := "hi \U0001F600 \U0001F1EE\U0001F1F3!"
+	got := Analyse(input, false)
+	if len(got) != 1 || got[0].Name != "flag: India" || got[0].RuneIndex != 5 {
+		t.Fatalf("unexpected sequences: %+v", got)
+	}
+	if all := Analyse(input, true); len(all) != 2 || all[0].Name != "grinning face" || all[0].RuneIndex != 3 {
+		t.Fatalf("unexpected sequences with all: %+v", all)
+	}
+}
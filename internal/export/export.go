@@ -0,0 +1,437 @@
+// Package export writes visualiser results in machine-readable formats. The
+// command line and the HTTP download endpoint share it, so both produce the
+// same bytes for the same input.
+package export
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"html"
+	"io"
+	"slices"
+	"strconv"
+	"strings"
+	"unicode/utf8"
+
+	"go_tutorials/internal/visualiser"
+)
+
+// Format names an output format.
+type Format string
+
+// Supported formats.
+const (
+	JSON     Format = "json"
+	NDJSON   Format = "ndjson"
+	CSV      Format = "csv"
+	TSV      Format = "tsv"
+	Markdown Format = "markdown"
+	HTML     Format = "html"
+	YAML     Format = "yaml"
+)
+
+// AllFormats lists every format in display order.
+var AllFormats = []Format{JSON, NDJSON, CSV, TSV, Markdown, HTML, YAML}
+
+var formatAliases = map[string]Format{
+	"jsonl": NDJSON,
+	"md":    Markdown,
+	"htm":   HTML,
+	"yml":   YAML,
+}
+
+// ParseFormat resolves a format name or alias such as "md" or "yml".
+func ParseFormat(name string) (Format, error) {
+	key := strings.ToLower(strings.TrimSpace(name))
+	if f, ok := formatAliases[key]; ok {
+		return f, nil
+	}
+	for _, f := range AllFormats {
+		if Format(key) == f {
+			return f, nil
+		}
+	}
+	names := make([]string, len(AllFormats))
+	for i, f := range AllFormats {
+		names[i] = string(f)
+	}
+	return "", fmt.Errorf("unknown format %q (use %s)", name, strings.Join(names, ", "))
+}
+
+// ContentType returns the MIME type of the format.
+func (f Format) ContentType() string {
+	switch f {
+	case JSON:
+		return "application/json"
+	case NDJSON:
+		return "application/x-ndjson"
+	case CSV:
+		return "text/csv"
+	case TSV:
+		return "text/tab-separated-values"
+	case Markdown:
+		return "text/markdown"
+	case HTML:
+		return "text/html; charset=utf-8"
+	case YAML:
+		return "application/yaml"
+	}
+	return "text/plain"
+}
+
+// Extension returns the usual file name extension, without the dot.
+func (f Format) Extension() string {
+	if f == Markdown {
+		return "md"
+	}
+	return string(f)
+}
+
+// Options selects the optional columns of tabular formats.
+type Options struct {
+	Encodings []visualiser.Encoding // extra byte columns; UTF-16 adds its code units
+	Languages []visualiser.Language // one escape column per syntax
+}
+
+// Report is the document the JSON format writes: every rune plus the whole
+// string escaped in each selected syntax and in each binary-to-text
+// encoding. Invalid lists the malformed UTF-8 sequences of input that is not
+// valid UTF-8; Items then hold only the runes that decoded.
+type Report struct {
+	Items   []visualiser.Result          `json:"items"`
+	Invalid []visualiser.InvalidSequence `json:"invalid,omitempty"`
+	Escaped []EscapedString              `json:"escaped,omitempty"`
+	Encoded []EncodedString              `json:"encoded,omitempty"`
+}
+
+// EscapedString is a whole string written as a literal in one syntax.
+type EscapedString struct {
+	Language visualiser.Language `json:"language"`
+	Label    string              `json:"label"`
+	Literal  string              `json:"literal"`
+}
+
+// EncodedString is a whole string in one binary-to-text encoding.
+type EncodedString struct {
+	Encoding visualiser.TextEncoding `json:"encoding"`
+	Label    string                  `json:"label"`
+	Text     string                  `json:"text"`
+}
+
+// NewReport builds the report for text, whose runes are items.
+func NewReport(text string, items []visualiser.Result, languages []visualiser.Language) Report {
+	return Report{Items: items, Escaped: EscapeAll(text, languages), Encoded: EncodeAll(text)}
+}
+
+// Analyse builds the report for text. Text that is not valid UTF-8 is
+// decoded strictly: malformed sequences go to Invalid instead of becoming
+// U+FFFD rows, so byte offsets and columns stay true to the input.
+func Analyse(text string, languages []visualiser.Language) (Report, error) {
+	if utf8.ValidString(text) {
+		items, err := visualiser.AnalyseString(text)
+		if err != nil {
+			return Report{}, err
+		}
+		return NewReport(text, items, languages), nil
+	}
+	items, invalid, err := visualiser.AnalyseBytes([]byte(text))
+	if err != nil {
+		return Report{}, err
+	}
+	report := NewReport(text, items, languages)
+	report.Invalid = invalid
+	return report, nil
+}
+
+// EscapeAll writes text as a literal in each language; nil for empty text.
+func EscapeAll(text string, languages []visualiser.Language) []EscapedString {
+	if text == "" {
+		return nil
+	}
+	out := make([]EscapedString, len(languages))
+	for i, lang := range languages {
+		out[i] = EscapedString{Language: lang, Label: lang.Label(), Literal: visualiser.EscapeString(text, lang)}
+	}
+	return out
+}
+
+// EncodeAll writes text in every binary-to-text encoding; nil for empty
+// text.
+func EncodeAll(text string) []EncodedString {
+	if text == "" {
+		return nil
+	}
+	out := make([]EncodedString, len(visualiser.AllTextEncodings))
+	for i, enc := range visualiser.AllTextEncodings {
+		out[i] = EncodedString{Encoding: enc, Label: enc.Label(), Text: visualiser.EncodeText(text, enc)}
+	}
+	return out
+}
+
+// Write writes report to w in format. Tabular formats (CSV, TSV, Markdown
+// and HTML) have one row per rune with the columns Header lists, and a
+// marked row for each malformed sequence where it occurs. NDJSON likewise
+// puts each sequence's object between the runes around it.
+func Write(w io.Writer, format Format, report Report, opts Options) error {
+	switch format {
+	case JSON:
+		return json.NewEncoder(w).Encode(report)
+	case NDJSON:
+		enc := json.NewEncoder(w)
+		var err error
+		eachRow(report, func(item visualiser.Result) {
+			if err == nil {
+				err = enc.Encode(item)
+			}
+		}, func(inv visualiser.InvalidSequence) {
+			if err == nil {
+				err = enc.Encode(inv)
+			}
+		})
+		return err
+	case CSV, TSV:
+		return writeDelimited(w, format, records(report, opts), opts)
+	case Markdown:
+		return writeMarkdown(w, records(report, opts), opts)
+	case HTML:
+		return writeHTML(w, records(report, opts), opts)
+	case YAML:
+		return writeYAML(w, report, opts)
+	}
+	return fmt.Errorf("unsupported format %q", format)
+}
+
+// eachRow calls item for every rune and invalid for every malformed
+// sequence, in input order.
+func eachRow(report Report, item func(visualiser.Result), invalid func(visualiser.InvalidSequence)) {
+	next := 0
+	for i := 0; i <= len(report.Items); i++ {
+		for ; next < len(report.Invalid) && report.Invalid[next].Index <= i; next++ {
+			invalid(report.Invalid[next])
+		}
+		if i < len(report.Items) {
+			item(report.Items[i])
+		}
+	}
+}
+
+// records returns the tabular rows of report in input order.
+func records(report Report, opts Options) [][]string {
+	var rows [][]string
+	eachRow(report, func(item visualiser.Result) {
+		rows = append(rows, Record(item, opts))
+	}, func(inv visualiser.InvalidSequence) {
+		rows = append(rows, InvalidRecord(inv, opts))
+	})
+	return rows
+}
+
+func writeDelimited(w io.Writer, format Format, rows [][]string, opts Options) error {
+	writer := csv.NewWriter(w)
+	if format == TSV {
+		writer.Comma = '\t'
+	}
+	writer.Write(Header(opts))
+	for _, record := range rows {
+		writer.Write(record)
+	}
+	writer.Flush()
+	return writer.Error()
+}
+
+// markdownCell keeps a value inside its Markdown table cell.
+var markdownCell = strings.NewReplacer(`\`, `\\`, "|", `\|`, "\r", `\r`, "\n", `\n`)
+
+func writeMarkdown(w io.Writer, rows [][]string, opts Options) error {
+	header := Header(opts)
+	var sb strings.Builder
+	sb.WriteString("| " + strings.Join(header, " | ") + " |\n")
+	sb.WriteString("|" + strings.Repeat(" --- |", len(header)) + "\n")
+	for _, record := range rows {
+		for i, value := range record {
+			record[i] = markdownCell.Replace(value)
+		}
+		sb.WriteString("| " + strings.Join(record, " | ") + " |\n")
+	}
+	_, err := io.WriteString(w, sb.String())
+	return err
+}
+
+func writeHTML(w io.Writer, rows [][]string, opts Options) error {
+	var sb strings.Builder
+	sb.WriteString("<table>\n<thead>\n<tr>")
+	for _, title := range Header(opts) {
+		sb.WriteString("<th>" + html.EscapeString(title) + "</th>")
+	}
+	sb.WriteString("</tr>\n</thead>\n<tbody>\n")
+	for _, record := range rows {
+		sb.WriteString("<tr>")
+		for _, value := range record {
+			sb.WriteString("<td>" + html.EscapeString(value) + "</td>")
+		}
+		sb.WriteString("</tr>\n")
+	}
+	sb.WriteString("</tbody>\n</table>\n")
+	_, err := io.WriteString(w, sb.String())
+	return err
+}
+
+// numericColumns are written as YAML integers rather than strings.
+var numericColumns = map[string]bool{
+	"CodePointDec": true, "ByteOffset": true, "RuneIndex": true,
+	"Line": true, "ColumnUTF8": true, "ColumnUTF16": true,
+}
+
+// writeYAML writes the rows as a list of mappings, followed by the invalid
+// sequences and the escaped and encoded strings. Strings are double-quoted JSON strings, which YAML
+// reads unchanged.
+func writeYAML(w io.Writer, report Report, opts Options) error {
+	var sb strings.Builder
+	header := Header(opts)
+	sb.WriteString("items:")
+	if len(report.Items) == 0 {
+		sb.WriteString(" []")
+	}
+	sb.WriteString("\n")
+	for _, item := range report.Items {
+		for i, value := range Record(item, opts) {
+			prefix := "    "
+			if i == 0 {
+				prefix = "  - "
+			}
+			if !numericColumns[header[i]] {
+				value = yamlString(value)
+			}
+			sb.WriteString(prefix + header[i] + ": " + value + "\n")
+		}
+	}
+	if len(report.Invalid) > 0 {
+		sb.WriteString("invalid:\n")
+		for _, inv := range report.Invalid {
+			fmt.Fprintf(&sb, "  - Offset: %d\n    Index: %d\n    Bytes: %s\n    Reason: %s\n",
+				inv.Offset, inv.Index, yamlString(strings.Join(inv.Bytes, " ")), yamlString(string(inv.Reason)))
+		}
+	}
+	if len(report.Escaped) > 0 {
+		sb.WriteString("escaped:\n")
+		for _, e := range report.Escaped {
+			fmt.Fprintf(&sb, "  %s: %s\n", yamlString(string(e.Language)), yamlString(e.Literal))
+		}
+	}
+	if len(report.Encoded) > 0 {
+		sb.WriteString("encoded:\n")
+		for _, e := range report.Encoded {
+			fmt.Fprintf(&sb, "  %s: %s\n", yamlString(string(e.Encoding)), yamlString(e.Text))
+		}
+	}
+	_, err := io.WriteString(w, sb.String())
+	return err
+}
+
+func yamlString(s string) string {
+	quoted, _ := json.Marshal(s)
+	return string(quoted)
+}
+
+// Header lists the tabular columns: the UTF-8 and character property columns
+// always, plus UTF-16 code units and per-encoding bytes for the extra
+// encodings and one escape column per language.
+func Header(opts Options) []string {
+	header := []string{
+		"Character",
+		"CodePointHex",
+		"CodePointDec",
+		"UTF8BytesHex",
+		"UTF8BytesDec",
+		"UTF8BytesBinary",
+		"HTMLEntityDecimal",
+		"HTMLEntityHex",
+		"Name",
+		"Category",
+		"Script",
+		"Block",
+		"ByteOffset",
+		"RuneIndex",
+		"Line",
+		"ColumnUTF8",
+		"ColumnUTF16",
+	}
+	if hasUTF16(opts.Encodings) {
+		header = append(header, "UTF16Units", "UTF16High", "UTF16Low")
+	}
+	for _, enc := range opts.Encodings {
+		if enc != visualiser.UTF8 {
+			header = append(header, strings.ToUpper(string(enc))+"Bytes")
+		}
+	}
+	for _, lang := range opts.Languages {
+		header = append(header, EscapeColumn(lang))
+	}
+	return header
+}
+
+// Record returns the values of item in Header order.
+func Record(item visualiser.Result, opts Options) []string {
+	record := []string{
+		item.Character,
+		item.CodePointHex,
+		fmt.Sprintf("%d", item.CodePointDec),
+		strings.Join(item.UTF8BytesHex, " "),
+		strings.Join(item.UTF8BytesDec, " "),
+		strings.Join(item.UTF8BytesBinary, " "),
+		item.HTMLEntityDecimal,
+		item.HTMLEntityHex,
+		item.Name,
+		item.Category,
+		item.Script,
+		item.Block,
+		fmt.Sprintf("%d", item.ByteOffset),
+		fmt.Sprintf("%d", item.RuneIndex),
+		fmt.Sprintf("%d", item.Line),
+		fmt.Sprintf("%d", item.ColumnUTF8),
+		fmt.Sprintf("%d", item.ColumnUTF16),
+	}
+	if hasUTF16(opts.Encodings) {
+		record = append(record, strings.Join(item.UTF16Units, " "), item.UTF16High, item.UTF16Low)
+	}
+	for _, enc := range opts.Encodings {
+		if enc != visualiser.UTF8 {
+			record = append(record, strings.Join(item.BytesFor(enc), " "))
+		}
+	}
+	for _, lang := range opts.Languages {
+		record = append(record, item.Escapes[lang])
+	}
+	return record
+}
+
+// InvalidRecord returns the row of a malformed sequence in Header order: the
+// Character column reads "<invalid>" and Name gives the reason, alongside
+// the offending bytes and their offset.
+func InvalidRecord(inv visualiser.InvalidSequence, opts Options) []string {
+	header := Header(opts)
+	record := make([]string, len(header))
+	set := func(column, value string) { record[slices.Index(header, column)] = value }
+	set("Character", "<invalid>")
+	set("UTF8BytesHex", strings.Join(inv.Bytes, " "))
+	set("Name", "invalid UTF-8: "+string(inv.Reason))
+	set("ByteOffset", strconv.Itoa(inv.Offset))
+	return record
+}
+
+// EscapeColumn names the column of one escape syntax, e.g. "CEscape" for
+// C/C++.
+func EscapeColumn(lang visualiser.Language) string {
+	label, _, _ := strings.Cut(lang.Label(), "/")
+	return label + "Escape"
+}
+
+func hasUTF16(encodings []visualiser.Encoding) bool {
+	for _, enc := range encodings {
+		if enc == visualiser.UTF16BE || enc == visualiser.UTF16LE {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,134 @@
+package export
+
+import (
+	"bytes"
+	"encoding/json"
+	"strings"
+	"testing"
+
+	"go_tutorials/internal/visualiser"
+)
+
+func report(t *testing.T, text string, languages []visualiser.Language) Report {
+	t.Helper()
+	items, err := visualiser.AnalyseString(text)
+	if err != nil {
+		t.Fatalf("AnalyseString: %v", err)
+	}
+	return NewReport(text, items, languages)
+}
+
+func write(t *testing.T, format Format, r Report, opts Options) string {
+	t.Helper()
+	var buf bytes.Buffer
+	if err := Write(&buf, format, r, opts); err != nil {
+		t.Fatalf("Write(%s): %v", format, err)
+	}
+	return buf.String()
+}
+
+func TestParseFormat(t *testing.T) {
+	cases := map[string]Format{"json": JSON, "JSONL": NDJSON, " md ": Markdown, "htm": HTML, "yml": YAML, "tsv": TSV}
+	for name, want := range cases {
+		got, err := ParseFormat(name)
+		if err != nil || got != want {
+			t.Errorf("ParseFormat(%q) = %q, %v; want %q", name, got, err, want)
+		}
+	}
+	if _, err := ParseFormat("xml"); err == nil || !strings.Contains(err.Error(), "yaml") {
+		t.Fatalf("expected unknown format error listing formats, got %v", err)
+	}
+	if Markdown.Extension() != "md" || NDJSON.ContentType() != "application/x-ndjson" {
+		t.Fatalf("unexpected markdown extension or ndjson content type")
+	}
+}
+
+func TestWriteJSONAndNDJSON(t *testing.T) {
+	r := report(t, "Go", []visualiser.Language{visualiser.LangGo})
+
+	var doc struct {
+		Items   []map[string]any `json:"items"`
+		Escaped []EscapedString  `json:"escaped"`
+		Encoded []EncodedString  `json:"encoded"`
+	}
+	if err := json.Unmarshal([]byte(write(t, JSON, r, Options{})), &doc); err != nil {
+		t.Fatalf("invalid JSON: %v", err)
+	}
+	if len(doc.Items) != 2 || len(doc.Escaped) != 1 || len(doc.Encoded) != len(visualiser.AllTextEncodings) {
+		t.Fatalf("unexpected JSON document: %+v", doc)
+	}
+
+	lines := strings.Split(strings.TrimSuffix(write(t, NDJSON, r, Options{}), "\n"), "\n")
+	if len(lines) != 2 {
+		t.Fatalf("expected one NDJSON line per rune, got %q", lines)
+	}
+	for _, line := range lines {
+		if !json.Valid([]byte(line)) {
+			t.Fatalf("invalid NDJSON line %q", line)
+		}
+	}
+}
+
+func TestWriteTabular(t *testing.T) {
+	r := report(t, "a|b", nil)
+	opts := Options{Encodings: []visualiser.Encoding{visualiser.UTF8, visualiser.UTF16BE}, Languages: []visualiser.Language{visualiser.LangC}}
+
+	tsv := write(t, TSV, r, opts)
+	header := strings.SplitN(tsv, "\n", 2)[0]
+	if !strings.HasPrefix(header, "Character\tCodePointHex\t") || !strings.HasSuffix(header, "UTF16Units\tUTF16High\tUTF16Low\tUTF16BEBytes\tCEscape") {
+		t.Fatalf("unexpected TSV header %q", header)
+	}
+
+	md := write(t, Markdown, r, opts)
+	if !strings.Contains(md, `| '\|' |`) || !strings.Contains(md, "| --- |") {
+		t.Fatalf("expected escaped pipe and separator row in Markdown, got %q", md)
+	}
+
+	page := write(t, HTML, report(t, "<", nil), Options{})
+	if !strings.Contains(page, "<th>Character</th>") || !strings.Contains(page, "<td>&#39;&lt;&#39;</td>") {
+		t.Fatalf("expected escaped HTML cells, got %q", page)
+	}
+}
+
+func TestWriteYAML(t *testing.T) {
+	out := write(t, YAML, report(t, "A\"", []visualiser.Language{visualiser.LangJSON}), Options{})
+	for _, want := range []string{
+		"items:\n  - Character: \"'A'\"\n",
+		"    CodePointDec: 65\n",
+		"    Name: \"QUOTATION MARK\"\n",
+		"escaped:\n  \"json\": ",
+		"encoded:\n  \"base64\": \"QSI=\"\n",
+	} {
+		if !strings.Contains(out, want) {
+			t.Fatalf("expected YAML to contain %q, got %q", want, out)
+		}
+	}
+	if got := write(t, YAML, Report{}, Options{}); got != "items: []\n" {
+		t.Fatalf("unexpected empty YAML %q", got)
+	}
+}
+
+func TestAnalyseInvalidUTF8(t *testing.T) {
+	r, err := Analyse("\xffA\xc3", nil)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(r.Items) != 1 || r.Items[0].ByteOffset != 1 || r.Items[0].ColumnUTF8 != 2 || len(r.Invalid) != 2 {
+		t.Fatalf("expected one rune at offset 1 and two invalid sequences, got %+v", r)
+	}
+	if r.Encoded[0].Text != "/0HD" {
+		t.Fatalf("expected the raw bytes to be encoded, got %q", r.Encoded[0].Text)
+	}
+
+	lines := strings.Split(strings.TrimSpace(write(t, NDJSON, r, Options{})), "\n")
+	if len(lines) != 3 || !strings.Contains(lines[0], `"Reason":"out-of-range value"`) || !strings.Contains(lines[2], `"Reason":"truncated sequence"`) {
+		t.Fatalf("expected the invalid sequences in input order, got %q", lines)
+	}
+	md := write(t, Markdown, r, Options{})
+	if !strings.Contains(md, "| <invalid> |  |  | 0xFF |") || !strings.Contains(md, "invalid UTF-8: truncated sequence") {
+		t.Fatalf("expected marked rows, got %q", md)
+	}
+	if yaml := write(t, YAML, r, Options{}); !strings.Contains(yaml, "invalid:\n  - Offset: 0\n    Index: 0\n    Bytes: \"0xFF\"") {
+		t.Fatalf("expected an invalid section, got %q", yaml)
+	}
+}
@@ -0,0 +1,229 @@
+// Package normalize implements the Unicode normalization forms (UAX #15)
+// on top of embedded decomposition and composition data.
+package normalize
+
+import (
+	_ "embed"
+	"fmt"
+	"strconv"
+	"strings"
+	"sync"
+	"unicode/utf8"
+
+	"go_tutorials/internal/ucd"
+)
+
+//go:embed decompositions.txt
+var decompositionData string
+
+// Form is one of the four Unicode normalization forms.
+type Form int
+
+// Normalization forms in the order they are usually listed.
+const (
+	NFC Form = iota
+	NFD
+	NFKC
+	NFKD
+)
+
+// Forms lists every normalization form.
+var Forms = []Form{NFC, NFD, NFKC, NFKD}
+
+func (f Form) String() string {
+	switch f {
+	case NFC:
+		return "NFC"
+	case NFD:
+		return "NFD"
+	case NFKC:
+		return "NFKC"
+	case NFKD:
+		return "NFKD"
+	}
+	return fmt.Sprintf("Form(%d)", int(f))
+}
+
+func (f Form) compatibility() bool { return f == NFKC || f == NFKD }
+func (f Form) composed() bool      { return f == NFC || f == NFKC }
+
+// Normalize returns s converted to the normalization form.
+func (f Form) Normalize(s string) string {
+	runes := make([]rune, 0, len(s))
+	for _, r := range s {
+		runes = appendDecomposed(runes, r, f.compatibility())
+	}
+	reorder(runes)
+	if f.composed() {
+		runes = compose(runes)
+	}
+	return string(runes)
+}
+
+// IsNormalized reports whether s is already in the normalization form.
+func (f Form) IsNormalized(s string) bool {
+	return utf8.ValidString(s) && f.Normalize(s) == s
+}
+
+// Comparison describes the input after applying one normalization form.
+type Comparison struct {
+	Form       string   // NFC, NFD, NFKC or NFKD
+	Text       string   // normalized text formatted via %q
+	CodePoints []string // U+XXXX for each rune of the normalized text
+	Runes      int      // rune count of the normalized text
+	Bytes      int      // UTF-8 byte count of the normalized text
+	Normalized bool     // true when the input is already in this form
+}
+
+// Compare normalizes s into all four forms so they can be shown side by side.
+func Compare(s string) []Comparison {
+	comparisons := make([]Comparison, 0, len(Forms))
+	for _, f := range Forms {
+		out := f.Normalize(s)
+		cps := make([]string, 0, len(out))
+		for _, r := range out {
+			cps = append(cps, fmt.Sprintf("U+%04X", r))
+		}
+		comparisons = append(comparisons, Comparison{
+			Form:       f.String(),
+			Text:       fmt.Sprintf("%q", out),
+			CodePoints: cps,
+			Runes:      utf8.RuneCountInString(out),
+			Bytes:      len(out),
+			Normalized: utf8.ValidString(s) && out == s,
+		})
+	}
+	return comparisons
+}
+
+type decomposition struct {
+	canonical bool
+	mapping   []rune
+}
+
+var (
+	dataOnce       sync.Once
+	decompositions map[rune]decomposition
+	compositions   map[[2]rune]rune
+)
+
+// loadData parses decompositions.txt. Lines read CODE;TYPE;MAPPING;FLAGS,
+// where FLAGS is "x" for characters excluded from composition.
+func loadData() {
+	decompositions = make(map[rune]decomposition, strings.Count(decompositionData, "\n"))
+	compositions = make(map[[2]rune]rune)
+	for _, line := range strings.Split(decompositionData, "\n") {
+		if line == "" {
+			continue
+		}
+		fields := strings.Split(line, ";")
+		if len(fields) != 4 {
+			panic(fmt.Sprintf("normalize: malformed line %q", line))
+		}
+		cp := parseHex(fields[0])
+		d := decomposition{canonical: fields[1] == "canonical"}
+		for _, part := range strings.Fields(fields[2]) {
+			d.mapping = append(d.mapping, parseHex(part))
+		}
+		decompositions[cp] = d
+		if d.canonical && len(d.mapping) == 2 && fields[3] != "x" {
+			compositions[[2]rune{d.mapping[0], d.mapping[1]}] = cp
+		}
+	}
+}
+
+func parseHex(s string) rune {
+	v, err := strconv.ParseUint(s, 16, 32)
+	if err != nil {
+		panic(fmt.Sprintf("normalize: malformed code point %q", s))
+	}
+	return rune(v)
+}
+
+// Hangul syllables are decomposed and composed arithmetically (Unicode 3.12).
+const (
+	hangulSBase  = 0xAC00
+	hangulLBase  = 0x1100
+	hangulVBase  = 0x1161
+	hangulTBase  = 0x11A7
+	hangulLCount = 19
+	hangulVCount = 21
+	hangulTCount = 28
+	hangulNCount = hangulVCount * hangulTCount
+	hangulSCount = hangulLCount * hangulNCount
+)
+
+// appendDecomposed appends the full decomposition of r to out.
+func appendDecomposed(out []rune, r rune, compat bool) []rune {
+	if s := r - hangulSBase; s >= 0 && s < hangulSCount {
+		out = append(out, hangulLBase+s/hangulNCount, hangulVBase+s%hangulNCount/hangulTCount)
+		if t := s % hangulTCount; t != 0 {
+			out = append(out, hangulTBase+t)
+		}
+		return out
+	}
+	dataOnce.Do(loadData)
+	d, ok := decompositions[r]
+	if !ok || (!d.canonical && !compat) {
+		return append(out, r)
+	}
+	for _, m := range d.mapping {
+		out = appendDecomposed(out, m, compat)
+	}
+	return out
+}
+
+// reorder applies the canonical ordering algorithm: runs of non-starters are
+// stably sorted by combining class.
+func reorder(runes []rune) {
+	for i := 1; i < len(runes); i++ {
+		cc := ucd.CombiningClass(runes[i])
+		if cc == 0 {
+			continue
+		}
+		for j := i; j > 0; j-- {
+			prev := ucd.CombiningClass(runes[j-1])
+			if prev <= cc {
+				break
+			}
+			runes[j-1], runes[j] = runes[j], runes[j-1]
+		}
+	}
+}
+
+// compose applies the canonical composition algorithm to decomposed runes.
+func compose(runes []rune) []rune {
+	out := make([]rune, 0, len(runes))
+	starter := -1
+	var lastClass uint8
+	for _, r := range runes {
+		cc := ucd.CombiningClass(r)
+		if starter >= 0 {
+			adjacent := len(out)-1 == starter
+			if adjacent || (lastClass != 0 && lastClass < cc) {
+				if composite, ok := composePair(out[starter], r); ok {
+					out[starter] = composite
+					continue
+				}
+			}
+		}
+		if cc == 0 {
+			starter = len(out)
+		}
+		lastClass = cc
+		out = append(out, r)
+	}
+	return out
+}
+
+func composePair(a, b rune) (rune, bool) {
+	if l, v := a-hangulLBase, b-hangulVBase; l >= 0 && l < hangulLCount && v >= 0 && v < hangulVCount {
+		return hangulSBase + (l*hangulVCount+v)*hangulTCount, true
+	}
+	if s, t := a-hangulSBase, b-hangulTBase; s >= 0 && s < hangulSCount && s%hangulTCount == 0 && t > 0 && t < hangulTCount {
+		return a + t, true
+	}
+	dataOnce.Do(loadData)
+	composite, ok := compositions[[2]rune{a, b}]
+	return composite, ok
+}
@@ -0,0 +1,56 @@
+package normalize
+
+import "testing"
+
+func TestNormalize(t *testing.T) {
+	cases := []struct {
+		input                string
+		nfc, nfd, nfkc, nfkd string
+	}{
+		{"\u00e9", "\u00e9", "e\u0301", "\u00e9", "e\u0301"},
+		{"e\u0301", "\u00e9", "e\u0301", "\u00e9", "e\u0301"},
+		{"\ufb01", "\ufb01", "\ufb01", "fi", "fi"},
+		{"\u212b", "\u00c5", "A\u030a", "\u00c5", "A\u030a"},
+		{"\uac01", "\uac01", "\u1100\u1161\u11a8", "\uac01", "\u1100\u1161\u11a8"},
+		{"\u1100\u1161\u11a8", "\uac01", "\u1100\u1161\u11a8", "\uac01", "\u1100\u1161\u11a8"},
+		{"q\u0307\u0323", "q\u0323\u0307", "q\u0323\u0307", "q\u0323\u0307", "q\u0323\u0307"},
+		{"\u0958", "\u0915\u093c", "\u0915\u093c", "\u0915\u093c", "\u0915\u093c"},
+		{"\u2460", "\u2460", "\u2460", "1", "1"},
+	}
+	for _, tc := range cases {
+		for form, want := range map[Form]string{NFC: tc.nfc, NFD: tc.nfd, NFKC: tc.nfkc, NFKD: tc.nfkd} {
+			if got := form.Normalize(tc.input); got != want {
+				t.Errorf("%s(%+q): want %+q, got %+q", form, tc.input, want, got)
+			}
+		}
+	}
+}
+
+func TestIsNormalized(t *testing.T) {
+	if !NFC.IsNormalized("\u00e9") || NFD.IsNormalized("\u00e9") {
+		t.Errorf("expected precomposed é to be NFC but not NFD")
+	}
+	if NFC.IsNormalized("e\u0301") || !NFD.IsNormalized("e\u0301") {
+		t.Errorf("expected decomposed é to be NFD but not NFC")
+	}
+	if NFC.IsNormalized("\xff") {
+		t.Errorf("expected invalid UTF-8 not to count as normalized")
+	}
+}
+
+func TestCompare(t *testing.T) {
+	comparisons := Compare("e\u0301")
+	if len(comparisons) != 4 {
+		t.Fatalf("expected 4 forms, got %d", len(comparisons))
+	}
+	nfc, nfd := comparisons[0], comparisons[1]
+	if nfc.Form != "NFC" || nfc.Runes != 1 || nfc.Bytes != 2 || nfc.Normalized {
+		t.Errorf("unexpected NFC comparison: %+v", nfc)
+	}
+	if nfd.Form != "NFD" || nfd.Runes != 2 || nfd.Bytes != 3 || !nfd.Normalized {
+		t.Errorf("unexpected NFD comparison: %+v", nfd)
+	}
+	if len(nfd.CodePoints) != 2 || nfd.CodePoints[1] != "U+0301" {
+		t.Errorf("unexpected NFD code points: %v", nfd.CodePoints)
+	}
+}
@@ -0,0 +1,181 @@
+package reverseinput
+
+import (
+	"encoding/ascii85"
+	"encoding/base32"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+)
+
+// compacted is input with some characters removed, remembering where each
+// remaining byte came from so errors can point into the original.
+type compacted struct {
+	input  string
+	text   string
+	origin []int // origin[i] is the offset in input of text[i]
+}
+
+func compact(input string, drop func(rune) bool) compacted {
+	c := compacted{input: input}
+	var sb strings.Builder
+	for i, r := range input {
+		if drop(r) {
+			continue
+		}
+		sb.WriteRune(r)
+		for j := range utf8.RuneLen(r) {
+			c.origin = append(c.origin, i+j)
+		}
+	}
+	c.text = sb.String()
+	return c
+}
+
+// slice keeps text[start:end] and its origins.
+func (c compacted) slice(start, end int) compacted {
+	return compacted{input: c.input, text: c.text[start:end], origin: c.origin[start:end]}
+}
+
+// fail reports the character at offset i of text, or the last character
+// when the input ended too early.
+func (c compacted) fail(i int, expected, suggestion string, err error) error {
+	if len(c.origin) == 0 {
+		return err
+	}
+	i = min(i, len(c.origin)-1)
+	start := c.origin[i]
+	_, size := utf8.DecodeRuneInString(c.input[start:])
+	return tokenError(Token{c.input[start : start+size], start, start + size}, i, expected, suggestion, err)
+}
+
+// Formats the binary-to-text modes expect.
+const (
+	expectBase64  = "base64 such as Y2Fmw6k= (standard) or Y2Fmw6k (URL-safe, padding optional)"
+	expectBase32  = "base32 such as MNQWNQ5J (A-Z and 2-7, padding optional)"
+	expectASCII85 = "Ascii85 such as @prueW; or <~@prueW;~>"
+	expectQP      = "quoted-printable text such as caf=C3=A9"
+)
+
+// DecodeBase64 decodes standard or URL-safe base64, with or without '='
+// padding. Whitespace and line breaks are ignored; the alphabet is picked
+// from the characters used.
+func DecodeBase64(input string) ([]byte, error) {
+	c := compact(input, unicode.IsSpace)
+	if c.text == "" {
+		return nil, errors.New("base64 input is empty")
+	}
+	if i := strings.IndexByte(c.text, '.'); i >= 0 {
+		return nil, c.fail(i, expectBase64, "a JWT joins base64url segments with dots; decode one segment at a time",
+			fmt.Errorf("'.' at offset %d is not base64", c.origin[i]))
+	}
+	enc := base64.RawStdEncoding
+	if std, url := strings.IndexAny(c.text, "+/"), strings.IndexAny(c.text, "-_"); url >= 0 {
+		if std >= 0 {
+			i := max(std, url)
+			return nil, c.fail(i, expectBase64, "+ and / are standard base64, - and _ URL-safe; use one alphabet",
+				fmt.Errorf("base64 mixes alphabets at offset %d", c.origin[i]))
+		}
+		enc = base64.RawURLEncoding
+	}
+	data := strings.TrimRight(c.text, "=")
+	if len(c.text)-len(data) > 2 {
+		return nil, c.fail(len(data)+2, expectBase64, "", errors.New("base64 has more than two '=' padding characters"))
+	}
+	out, err := enc.DecodeString(data)
+	var corrupt base64.CorruptInputError
+	if errors.As(err, &corrupt) {
+		return nil, c.slice(0, len(data)).fail(int(corrupt), expectBase64, encodingSuggestion(data, int(corrupt)),
+			fmt.Errorf("invalid base64 at offset %d", c.origin[min(int(corrupt), len(data)-1)]))
+	}
+	return out, err
+}
+
+// DecodeBase32 decodes RFC 4648 base32, with or without '=' padding, in
+// either case. Whitespace and line breaks are ignored.
+func DecodeBase32(input string) ([]byte, error) {
+	c := compact(input, unicode.IsSpace)
+	if c.text == "" {
+		return nil, errors.New("base32 input is empty")
+	}
+	data := strings.TrimRight(c.text, "=")
+	out, err := base32.StdEncoding.WithPadding(base32.NoPadding).DecodeString(strings.ToUpper(data))
+	var corrupt base32.CorruptInputError
+	if errors.As(err, &corrupt) {
+		i := min(int(corrupt), len(data)-1)
+		suggestion := encodingSuggestion(data, int(corrupt))
+		if strings.ContainsRune("0189", rune(data[i])) {
+			suggestion = "base32 digits are 2 to 7; 0, 1, 8 and 9 are not used"
+		}
+		return nil, c.slice(0, len(data)).fail(i, expectBase32, suggestion,
+			fmt.Errorf("invalid base32 at offset %d", c.origin[i]))
+	}
+	return out, err
+}
+
+// DecodeASCII85 decodes Ascii85 (btoa and Adobe flavours), with or without
+// the <~ ~> delimiters; 'z' stands for four zero bytes. Whitespace is
+// ignored.
+func DecodeASCII85(input string) ([]byte, error) {
+	c := compact(input, unicode.IsSpace)
+	if strings.HasPrefix(c.text, "<~") {
+		c = c.slice(2, len(c.text))
+	}
+	if strings.HasSuffix(c.text, "~>") {
+		c = c.slice(0, len(c.text)-2)
+	}
+	if c.text == "" {
+		return nil, errors.New("ascii85 input is empty")
+	}
+	out := make([]byte, 4*len(c.text))
+	n, _, err := ascii85.Decode(out, []byte(c.text), true)
+	var corrupt ascii85.CorruptInputError
+	if errors.As(err, &corrupt) {
+		i := min(int(corrupt), len(c.text)-1)
+		return nil, c.fail(i, expectASCII85, "Ascii85 uses the characters ! to u, and z",
+			fmt.Errorf("invalid Ascii85 at offset %d", c.origin[i]))
+	}
+	return out[:n], err
+}
+
+// DecodeQuotedPrintable decodes RFC 2045 quoted-printable text: =XX is a
+// byte (either case), '=' at the end of a line is a soft line break and
+// everything else is literal.
+func DecodeQuotedPrintable(input string) ([]byte, error) {
+	var out []byte
+	index := 0
+	for i := 0; i < len(input); i++ {
+		if input[i] != '=' {
+			out = append(out, input[i])
+			continue
+		}
+		rest := strings.TrimLeft(input[i+1:], " \t")
+		switch {
+		case rest == "" || rest[0] == '\n' || strings.HasPrefix(rest, "\r\n"):
+			i = len(input) - len(strings.TrimPrefix(strings.TrimPrefix(rest, "\r"), "\n")) - 1
+		case len(input) >= i+3 && isHex(input[i+1:i+3]):
+			b, _ := strconv.ParseUint(input[i+1:i+3], 16, 8)
+			out = append(out, byte(b))
+			i += 2
+		default:
+			end := min(i+3, len(input))
+			return nil, tokenError(Token{input[i:end], i, end}, index, expectQP, "write a literal = as =3D",
+				fmt.Errorf("invalid quoted-printable escape %q at offset %d", input[i:end], i))
+		}
+		index++
+	}
+	return out, nil
+}
+
+// encodingSuggestion explains a decoding error at offset i of text: a
+// letter or digit rejected at the very end means characters are missing.
+func encodingSuggestion(text string, i int) string {
+	if i >= len(text) || i == len(text)-1 && (unicode.IsLetter(rune(text[i])) || unicode.IsDigit(rune(text[i]))) {
+		return "the input looks truncated"
+	}
+	return ""
+}
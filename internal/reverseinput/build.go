@@ -0,0 +1,101 @@
+package reverseinput
+
+import (
+	"fmt"
+	"strings"
+)
+
+// Mode names an input format that Build understands.
+type Mode string
+
+// Supported reverse input modes.
+const (
+	ModeCodePoints Mode = "codepoints"
+	ModeBytes      Mode = "bytes"
+	ModeUTF16      Mode = "utf16"
+	ModeEscaped    Mode = "escaped"
+	ModeNames      Mode = "names"
+	ModeDump       Mode = "dump"
+	ModeBase64     Mode = "base64"
+	ModeBase32     Mode = "base32"
+	ModeASCII85    Mode = "ascii85"
+	ModeQP         Mode = "quoted-printable"
+	ModeLiteral    Mode = "literal"
+)
+
+var modeAliases = map[string]Mode{
+	"codepoint": ModeCodePoints,
+	"cp":        ModeCodePoints,
+	"byte":      ModeBytes,
+	"utf-16":    ModeUTF16,
+	"escapes":   ModeEscaped,
+	"unescape":  ModeEscaped,
+	"name":      ModeNames,
+	"hexdump":   ModeDump,
+	"xxd":       ModeDump,
+	"b64":       ModeBase64,
+	"base64url": ModeBase64,
+	"b32":       ModeBase32,
+	"base85":    ModeASCII85,
+	"a85":       ModeASCII85,
+	"qp":        ModeQP,
+	"array":     ModeLiteral,
+	"source":    ModeLiteral,
+}
+
+// ParseMode resolves a mode name or alias such as "cp" or "utf-16".
+func ParseMode(name string) (Mode, bool) {
+	key := strings.ToLower(strings.TrimSpace(name))
+	if mode, ok := modeAliases[key]; ok {
+		return mode, true
+	}
+	switch mode := Mode(key); mode {
+	case ModeCodePoints, ModeBytes, ModeUTF16, ModeEscaped, ModeNames, ModeDump,
+		ModeBase64, ModeBase32, ModeASCII85, ModeQP, ModeLiteral:
+		return mode, true
+	}
+	return "", false
+}
+
+// Build turns input written in mode into text. Code point ranges may expand
+// to at most maxCodePoints runes (no cap when maxCodePoints <= 0). Failures
+// that concern one token are *ParseError values whose byte spans point into
+// input.
+func Build(mode Mode, input string, maxCodePoints int) (string, error) {
+	switch mode {
+	case ModeEscaped:
+		return BuildStringFromEscapes(input)
+	case ModeNames:
+		return buildNames(NameSpans(input))
+	case ModeDump:
+		return decoded(ParseHexDump(input))
+	case ModeBase64:
+		return decoded(DecodeBase64(input))
+	case ModeBase32:
+		return decoded(DecodeBase32(input))
+	case ModeASCII85:
+		return decoded(DecodeASCII85(input))
+	case ModeQP:
+		return decoded(DecodeQuotedPrintable(input))
+	case ModeLiteral:
+		return decoded(ParseByteLiteral(input))
+	}
+
+	tokens := TokenSpans(input)
+	if len(tokens) == 0 {
+		return "", fmt.Errorf("%s mode requires values", mode)
+	}
+	switch mode {
+	case ModeCodePoints:
+		return buildCodePoints(tokens, maxCodePoints)
+	case ModeBytes:
+		return buildBytes(tokens)
+	case ModeUTF16:
+		return buildUTF16(tokens)
+	}
+	return "", fmt.Errorf("unknown reverse mode %q", mode)
+}
+
+func decoded(bytes []byte, err error) (string, error) {
+	return string(bytes), err
+}
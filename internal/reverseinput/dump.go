@@ -0,0 +1,303 @@
+package reverseinput
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"unicode"
+)
+
+// ParseHexDump rebuilds the bytes of a hex dump copied from one of these
+// tools, removing offsets and ASCII gutters:
+//
+//	xxd                      00000000: 4865 6c6c 6f0a  Hello.
+//	hexdump -C               00000000  48 65 6c 6c 6f 0a  |Hello.|
+//	hexdump, od -x           0000000 6548 6c6c 0a6f
+//	od -t x1z, od -A d       0000000 48 65 6c 6c 6f 0a  >Hello.<
+//	od (octal words)         0000000 062510 066154 005157
+//	Wireshark (Copy as Hex)  0000   48 65 6c 6c 6f 0a   Hello.
+//
+// Plain hex such as xxd -p output works too. Offsets may be hex, octal or
+// decimal; the radix is inferred from how far apart consecutive lines are.
+// When a line is followed by another offset, that offset says how many
+// bytes the line holds, which resolves gutters that happen to look like hex,
+// expands hexdump's "*" lines and trims the padding od and hexdump add to
+// the last word. Multi-byte words from hexdump and od (dumps without xxd's
+// colon) are read as little-endian, as those tools print them on x86 and ARM.
+func ParseHexDump(input string) ([]byte, error) {
+	var lines []dumpLine
+	start := 0
+	for i, text := range strings.SplitAfter(input, "\n") {
+		line, err := parseDumpLine(strings.TrimRight(text, "\r\n"), start, i)
+		start += len(text)
+		if err != nil {
+			return nil, err
+		}
+		if line.kind != dumpBlank {
+			lines = append(lines, line)
+		}
+	}
+	if len(lines) == 0 {
+		return nil, errors.New("hex dump contains no bytes")
+	}
+
+	// Without any offset column, a lone short field is data, not an offset.
+	hasOffsets := false
+	for _, line := range lines {
+		hasOffsets = hasOffsets || (line.kind == dumpData && line.offset != nil)
+	}
+	if !hasOffsets {
+		for i := range lines {
+			if lines[i].kind != dumpEnd {
+				continue
+			}
+			line, err := lines[i].asData()
+			if err != nil {
+				return nil, err
+			}
+			lines[i] = line
+		}
+	}
+
+	radix := dumpRadix(lines)
+	var out []byte
+	for i, line := range lines {
+		switch line.kind {
+		case dumpRepeat:
+			if i == 0 || lines[i-1].kind != dumpData {
+				return nil, line.fail(`"*" must follow a line of bytes`, errors.New(`unexpected "*" line`))
+			}
+			continue
+		case dumpEnd:
+			continue
+		}
+		if line.offset == nil || i+1 == len(lines) {
+			out = append(out, line.bytes...)
+			continue
+		}
+		next, repeat := i+1, false
+		if lines[next].kind == dumpRepeat {
+			next, repeat = next+1, true
+		}
+		if next == len(lines) || lines[next].offset == nil {
+			out = append(out, line.bytes...)
+			continue
+		}
+		from, err := line.offsetValue(radix)
+		if err != nil {
+			return nil, err
+		}
+		to, err := lines[next].offsetValue(radix)
+		if err != nil {
+			return nil, err
+		}
+		want := to - from
+		switch {
+		case want < 0:
+			return nil, lines[next].failOffset("offsets must increase",
+				fmt.Errorf("offset %s on line %d comes before offset %s", lines[next].offset.Text, lines[next].number+1, line.offset.Text))
+		case repeat && len(line.bytes) > 0:
+			for n := int64(0); n < want; n++ {
+				out = append(out, line.bytes[n%int64(len(line.bytes))])
+			}
+		case int64(len(line.bytes)) >= want:
+			out = append(out, line.bytes[:want]...)
+		default:
+			return nil, line.fail("check for a line that was cut short",
+				fmt.Errorf("line %d holds %d bytes but the next offset is %d bytes further", line.number+1, len(line.bytes), want))
+		}
+	}
+	if len(out) == 0 {
+		return nil, errors.New("hex dump contains no bytes")
+	}
+	return out, nil
+}
+
+type dumpKind int
+
+const (
+	dumpBlank  dumpKind = iota
+	dumpData            // a line of bytes, with or without an offset
+	dumpRepeat          // hexdump's "*": the previous line repeats
+	dumpEnd             // a lone offset giving the total length
+)
+
+type dumpLine struct {
+	kind   dumpKind
+	number int    // 0-based line number
+	offset *Token // the offset column, without xxd's colon
+	fields []Token
+	bytes  []byte
+}
+
+// asData reinterprets a lone offset as a line of plain hex.
+func (l dumpLine) asData() (dumpLine, error) {
+	l.kind, l.fields, l.offset = dumpData, []Token{*l.offset}, nil
+	group, err := decodeDumpGroup(l.fields[0].Text, false, false)
+	if err != nil {
+		return l, l.fail("", fmt.Errorf("line %d: %w", l.number+1, err))
+	}
+	l.bytes = group
+	return l, nil
+}
+
+func (l dumpLine) fail(suggestion string, err error) error {
+	tok := l.offset
+	if l.kind == dumpData {
+		tok = &l.fields[len(l.fields)-1]
+	}
+	return tokenError(*tok, l.number, expectDump, suggestion, err)
+}
+
+func (l dumpLine) failOffset(suggestion string, err error) error {
+	return tokenError(*l.offset, l.number, expectDump, suggestion, err)
+}
+
+func (l dumpLine) offsetValue(radix int) (int64, error) {
+	val, err := strconv.ParseInt(l.offset.Text, radix, 64)
+	if err != nil {
+		return 0, l.failOffset("", fmt.Errorf("invalid offset %q on line %d", l.offset.Text, l.number+1))
+	}
+	return val, nil
+}
+
+// parseDumpLine splits one line into its offset and the hex fields before
+// the gutter. base is the line's byte offset within the whole input.
+func parseDumpLine(text string, base, number int) (dumpLine, error) {
+	fields, gaps := dumpFields(text, base)
+	line := dumpLine{number: number}
+	if len(fields) == 0 {
+		return line, nil
+	}
+	if len(fields) == 1 && fields[0].Text == "*" {
+		line.kind, line.offset = dumpRepeat, &fields[0]
+		return line, nil
+	}
+
+	first := fields[0]
+	colon := strings.HasSuffix(first.Text, ":")
+	if colon {
+		first.Text, first.End = first.Text[:len(first.Text)-1], first.End-1
+	}
+	isOffset := isHex(first.Text) && (colon ||
+		len(first.Text) >= 4 && len(first.Text) <= 16 && (len(fields) == 1 || len(fields[1].Text) != len(first.Text)))
+	if isOffset {
+		line.offset = &first
+		fields, gaps = fields[1:], gaps[1:]
+		if len(fields) == 0 {
+			line.kind = dumpEnd
+			return line, nil
+		}
+	}
+
+	// Data fields share one width (the last may be shorter) and are separated
+	// by single spaces, apart from the double space hexdump -C and older
+	// Wireshark versions put after the eighth byte. xxd offsets end in a colon
+	// and xxd never splits its bytes, so there a double space always starts
+	// the gutter, as does any wider gap, a field that is not hex, or a wider
+	// field.
+	line.kind = dumpData
+	width := len(fields[0].Text)
+	octalWords := !colon && line.offset != nil && width == 6 && isOctal(fields[0].Text)
+	for i, field := range fields {
+		if i > 0 && (gaps[i] > 2 || gaps[i] == 2 && (colon || len(line.bytes) != 8)) {
+			break
+		}
+		if len(field.Text) > width || !isHex(field.Text) || octalWords && !isOctal(field.Text) {
+			break
+		}
+		group, err := decodeDumpGroup(field.Text, octalWords, !colon && line.offset != nil)
+		if err != nil {
+			return line, tokenError(field, number, expectDump, "", fmt.Errorf("line %d: %w", number+1, err))
+		}
+		line.fields = append(line.fields, field)
+		line.bytes = append(line.bytes, group...)
+	}
+	if len(line.fields) == 0 {
+		return line, tokenError(fields[0], number, expectDump, "",
+			fmt.Errorf("line %d has no hex bytes where %q is", number+1, fields[0].Text))
+	}
+	return line, nil
+}
+
+// decodeDumpGroup decodes one field of hex digits, or a 16-bit octal word
+// from od. Little-endian groups have their bytes reversed.
+func decodeDumpGroup(text string, octal, littleEndian bool) ([]byte, error) {
+	if octal {
+		word, err := strconv.ParseUint(text, 8, 16)
+		if err != nil {
+			return nil, fmt.Errorf("octal word %q does not fit in 16 bits", text)
+		}
+		return []byte{byte(word), byte(word >> 8)}, nil
+	}
+	if len(text)%2 != 0 {
+		return nil, fmt.Errorf("hex group %q has an odd number of digits", text)
+	}
+	group := make([]byte, len(text)/2)
+	for i := range group {
+		b, _ := strconv.ParseUint(text[2*i:2*i+2], 16, 8)
+		group[i] = byte(b)
+	}
+	if littleEndian && len(group) > 1 {
+		for i, j := 0, len(group)-1; i < j; i, j = i+1, j-1 {
+			group[i], group[j] = group[j], group[i]
+		}
+	}
+	return group, nil
+}
+
+// dumpFields splits text at whitespace like TokenSpans, also returning the
+// width of the gap before each field (a tab counts as a wide gap).
+func dumpFields(text string, base int) ([]Token, []int) {
+	var (
+		fields []Token
+		gaps   []int
+	)
+	gap, start := 0, -1
+	for i, r := range text {
+		switch {
+		case unicode.IsSpace(r) && start >= 0:
+			fields = append(fields, Token{text[start:i], base + start, base + i})
+			start, gap = -1, 0
+			fallthrough
+		case unicode.IsSpace(r):
+			if r == '\t' {
+				gap += 4
+			} else {
+				gap++
+			}
+		case start < 0:
+			start = i
+			gaps = append(gaps, gap)
+		}
+	}
+	if start >= 0 {
+		fields = append(fields, Token{text[start:], base + start, base + len(text)})
+	}
+	return fields, gaps
+}
+
+// dumpRadix infers whether offsets are hex, octal (od's default) or decimal
+// (od -A d) from the first line whose byte count matches the distance to
+// the next offset in one of them.
+func dumpRadix(lines []dumpLine) int {
+	for i := 0; i+1 < len(lines); i++ {
+		cur, next := lines[i], lines[i+1]
+		if cur.kind != dumpData || cur.offset == nil || next.offset == nil || next.kind == dumpRepeat {
+			continue
+		}
+		for _, radix := range []int{16, 8, 10} {
+			from, err1 := strconv.ParseInt(cur.offset.Text, radix, 64)
+			to, err2 := strconv.ParseInt(next.offset.Text, radix, 64)
+			if err1 == nil && err2 == nil && to-from == int64(len(cur.bytes)) {
+				return radix
+			}
+		}
+	}
+	return 16
+}
+
+func isOctal(s string) bool {
+	return s != "" && strings.Trim(s, "01234567") == ""
+}
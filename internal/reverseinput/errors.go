@@ -0,0 +1,80 @@
+package reverseinput
+
+import (
+	"fmt"
+	"strings"
+)
+
+// ParseError describes the token, name or escape sequence that stopped a
+// reverse parse, so callers can point at it in the original input.
+type ParseError struct {
+	Index      int    // 0-based index of the offending token, name or escape sequence (line for dumps)
+	Token      string // the offending text
+	Start      int    // byte offset of Token within the input
+	End        int    // byte offset just past Token
+	Expected   string // the format the token should have, e.g. "a byte from 0 to 255 ..."
+	Suggestion string // a likely fix such as "did you mean U+1F60A?", or empty
+	Err        error  // what went wrong
+}
+
+func (e *ParseError) Error() string {
+	if e.Suggestion == "" {
+		return e.Err.Error()
+	}
+	return fmt.Sprintf("%v (%s)", e.Err, e.Suggestion)
+}
+
+func (e *ParseError) Unwrap() error { return e.Err }
+
+// Formats each mode expects, quoted in ParseError.Expected.
+const (
+	expectCodePoint = "a code point such as U+0041, 0x41, 0b1000001 or 65, or a range such as U+0041..U+005A"
+	expectByte      = "a byte from 0 to 255 such as 0xF0, 0b11110000 or 240"
+	expectUTF16     = `a UTF-16 code unit from 0 to 0xFFFF such as 0xD83D, U+D83D or \uD83D`
+	expectEscape    = `literal text or an escape such as \n, \xE9, \u00E9, \u{1F600}, \U0001F600, \N{SNOWMAN}, &eacute; or %C3%A9`
+	expectName      = "a Unicode character name such as LATIN SMALL LETTER A"
+	expectDump      = "a hex dump line from xxd, hexdump -C, od or Wireshark, e.g. 00000000: 4869 0a  Hi."
+)
+
+func tokenError(tok Token, index int, expected, suggestion string, err error) *ParseError {
+	return &ParseError{
+		Index:      index,
+		Token:      tok.Text,
+		Start:      tok.Start,
+		End:        tok.End,
+		Expected:   expected,
+		Suggestion: suggestion,
+		Err:        err,
+	}
+}
+
+// suggestNumber guesses what a malformed numeric token was meant to be.
+func suggestNumber(text string, codePoint bool) string {
+	upper := strings.ToUpper(text)
+	switch {
+	case codePoint && len(upper) > 1 && upper[0] == 'U' && upper[1] != '+' && isHex(upper[1:]):
+		return fmt.Sprintf("did you mean U+%s?", upper[1:])
+	case strings.HasPrefix(upper, `\X`) && isHex(upper[2:]):
+		return fmt.Sprintf("did you mean 0x%s?", upper[2:])
+	case strings.HasPrefix(upper, `\U`) || strings.HasPrefix(upper, "&#"):
+		return "escape sequences are read by the escaped mode"
+	case isHex(upper) && strings.ContainsAny(upper, "ABCDEF"):
+		if codePoint {
+			return fmt.Sprintf("did you mean U+%s?", upper)
+		}
+		return fmt.Sprintf("did you mean 0x%s?", upper)
+	}
+	return ""
+}
+
+func isHex(s string) bool {
+	if s == "" {
+		return false
+	}
+	for _, c := range s {
+		if !strings.ContainsRune("0123456789ABCDEFabcdef", c) {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,281 @@
+package reverseinput
+
+import (
+	"fmt"
+	"html"
+	"strconv"
+	"strings"
+	"unicode/utf16"
+	"unicode/utf8"
+)
+
+// BuildStringFromEscapes unescapes text that mixes literal characters with
+// escape sequences:
+//
+//   - Go, JSON, JavaScript, Python, Java, C and Rust backslash escapes:
+//     \n \t \r \0 \a \b \f \v \\ \' \" \/, \xHH, octal \NNN, \uXXXX (with
+//     UTF-16 surrogate pairs), \u{X...}, \UXXXXXXXX and Python's \N{NAME}
+//   - HTML named and numeric entities: &eacute; &#233; &#xE9;
+//   - percent-encoding: %C3%A9
+//
+// \xHH, octal and percent escapes are bytes. A run of them that is valid
+// UTF-8 is decoded as such; otherwise each byte is read as a Latin-1 code
+// point, the way Python reads "caf\xe9". A '&' or '%' that does not start a
+// valid entity or percent escape is kept as is.
+func BuildStringFromEscapes(input string) (string, error) {
+	u := unescaper{input: input}
+	for u.pos < len(input) {
+		var err error
+		switch input[u.pos] {
+		case '\\':
+			err = u.backslash()
+			u.escapes++
+		case '&':
+			u.entity()
+		case '%':
+			u.percent()
+		default:
+			u.flushBytes()
+			_, size := utf8.DecodeRuneInString(input[u.pos:])
+			u.out.WriteString(input[u.pos : u.pos+size])
+			u.pos += size
+		}
+		if err != nil {
+			return "", err
+		}
+	}
+	u.flushBytes()
+	return u.out.String(), nil
+}
+
+type unescaper struct {
+	input   string
+	pos     int
+	escapes int // escape sequences decoded so far, the Index of a ParseError
+	out     strings.Builder
+	bytes   []byte // pending run of byte escapes
+}
+
+// fail reports the escape sequence from start up to the current position.
+func (u *unescaper) fail(start int, suggestion string, err error) error {
+	end := min(max(u.pos, start+1), len(u.input))
+	return tokenError(Token{u.input[start:end], start, end}, u.escapes, expectEscape, suggestion, err)
+}
+
+// flushBytes writes the pending byte escapes as UTF-8. A byte that does not
+// start a valid UTF-8 sequence is read as a Latin-1 code point, so "\xff"
+// after "\xc3\xa9" still leaves the é intact.
+func (u *unescaper) flushBytes() {
+	for rest := u.bytes; len(rest) > 0; {
+		r, size := utf8.DecodeRune(rest)
+		if r == utf8.RuneError && size == 1 {
+			u.out.WriteRune(rune(rest[0]))
+		} else {
+			u.out.Write(rest[:size])
+		}
+		rest = rest[size:]
+	}
+	u.bytes = u.bytes[:0]
+}
+
+func (u *unescaper) writeRune(r rune) {
+	u.flushBytes()
+	u.out.WriteRune(r)
+}
+
+var simpleEscapes = map[byte]rune{
+	'n': '\n', 't': '\t', 'r': '\r', 'a': '\a', 'b': '\b', 'f': '\f', 'v': '\v',
+	'\\': '\\', '\'': '\'', '"': '"', '/': '/', '?': '?', 'e': 0x1B,
+}
+
+func (u *unescaper) backslash() error {
+	start := u.pos
+	if start+1 >= len(u.input) {
+		u.pos = len(u.input)
+		return u.fail(start, `write a literal backslash as \\`, fmt.Errorf("dangling backslash at offset %d", start))
+	}
+	c := u.input[start+1]
+	u.pos += 2
+	if r, ok := simpleEscapes[c]; ok {
+		u.writeRune(r)
+		return nil
+	}
+	switch {
+	case c == 'x':
+		digits := u.takeHex(2)
+		if digits == "" {
+			return u.fail(start, "", fmt.Errorf(`\x at offset %d needs hex digits`, start))
+		}
+		b, _ := strconv.ParseUint(digits, 16, 8)
+		u.bytes = append(u.bytes, byte(b))
+	case c >= '0' && c <= '7':
+		u.pos--
+		digits := u.take(3, func(c byte) bool { return c >= '0' && c <= '7' })
+		b, _ := strconv.ParseUint(digits, 8, 16)
+		if b > 0xFF {
+			return u.fail(start, "", fmt.Errorf(`octal escape \%s at offset %d exceeds a byte`, digits, start))
+		}
+		u.bytes = append(u.bytes, byte(b))
+	case (c == 'u' || c == 'N') && u.pos < len(u.input) && u.input[u.pos] == '{':
+		end := strings.IndexByte(u.input[u.pos:], '}')
+		if end < 0 {
+			u.pos++
+			return u.fail(start, "close it with }", fmt.Errorf(`unterminated \%c{ escape at offset %d`, c, start))
+		}
+		inner := u.input[u.pos+1 : u.pos+end]
+		u.pos += end + 1
+		if c == 'u' {
+			return u.codePoint(inner, start)
+		}
+		r, suggestion, err := lookupName(inner)
+		if err != nil {
+			return u.fail(start, suggestion, fmt.Errorf("%w at offset %d", err, start))
+		}
+		u.writeRune(r)
+	case c == 'u':
+		digits := u.takeHex(4)
+		if len(digits) != 4 {
+			return u.fail(start, `use \u{...} for fewer digits`, fmt.Errorf(`\u at offset %d needs 4 hex digits`, start))
+		}
+		r, _ := strconv.ParseUint(digits, 16, 32)
+		if utf16.IsSurrogate(rune(r)) {
+			return u.surrogate(rune(r), start)
+		}
+		u.writeRune(rune(r))
+	case c == 'U':
+		digits := u.takeHex(8)
+		if len(digits) != 8 {
+			return u.fail(start, "", fmt.Errorf(`\U at offset %d needs 8 hex digits`, start))
+		}
+		return u.codePoint(digits, start)
+	default:
+		return u.fail(start, `write a literal backslash as \\`, fmt.Errorf(`unknown escape \%c at offset %d`, c, start))
+	}
+	return nil
+}
+
+// surrogate pairs a high surrogate with the \uXXXX low surrogate that must
+// follow it.
+func (u *unescaper) surrogate(high rune, start int) error {
+	rest := u.input[u.pos:]
+	if high < 0xDC00 && len(rest) >= 6 && rest[0] == '\\' && rest[1] == 'u' {
+		if low, err := strconv.ParseUint(rest[2:6], 16, 32); err == nil && low >= 0xDC00 && low <= 0xDFFF {
+			u.pos += 6
+			u.writeRune(utf16.DecodeRune(high, rune(low)))
+			return nil
+		}
+	}
+	return u.fail(start, "follow it with a low surrogate escape from DC00 to DFFF",
+		fmt.Errorf(`unpaired surrogate \u%04X at offset %d`, high, start))
+}
+
+func (u *unescaper) codePoint(digits string, start int) error {
+	escape := u.input[start:u.pos]
+	r, err := strconv.ParseUint(digits, 16, 32)
+	if err != nil || digits == "" || len(digits) > 8 {
+		return u.fail(start, "", fmt.Errorf("invalid escape %s at offset %d", escape, start))
+	}
+	if r > utf8.MaxRune || utf16.IsSurrogate(rune(r)) {
+		return u.fail(start, "", fmt.Errorf("escape %s at offset %d is not a valid code point", escape, start))
+	}
+	u.writeRune(rune(r))
+	return nil
+}
+
+// entity decodes one HTML character reference (&name;, &#NNN; or &#xHH;),
+// or keeps a bare '&'. Like HTML, it also reads numeric references and the
+// legacy names such as &amp and &eacute without their semicolon. Only the
+// reference itself is decoded, so escapes after it are still read.
+func (u *unescaper) entity() {
+	start := u.pos
+	u.pos++
+	numeric := true
+	var body string
+	switch rest := u.input[u.pos:]; {
+	case strings.HasPrefix(rest, "#x") || strings.HasPrefix(rest, "#X"):
+		u.pos += 2
+		body = u.takeHex(32)
+	case strings.HasPrefix(rest, "#"):
+		u.pos++
+		body = u.take(32, func(c byte) bool { return c >= '0' && c <= '9' })
+	default:
+		numeric = false
+		body = u.take(32, isAlnum)
+	}
+	if body != "" {
+		end := u.pos
+		if end < len(u.input) && u.input[end] == ';' {
+			end++
+		}
+		ref := u.input[start:end]
+		// A named reference with a semicolon must match a name in full:
+		// html.UnescapeString would turn "&ampx;" into "&x;".
+		if decoded := html.UnescapeString(ref); decoded != ref &&
+			(numeric || end > u.pos && (!strings.HasSuffix(decoded, ";") || ref == "&semi;")) {
+			u.writeEntity(decoded, end)
+			return
+		}
+		if text, n := legacyEntity(body); !numeric && n > 0 {
+			u.writeEntity(text, start+1+n)
+			return
+		}
+	}
+	u.pos = start
+	u.writeRune('&')
+	u.pos++
+}
+
+// writeEntity writes the text of a reference that ends at end.
+func (u *unescaper) writeEntity(text string, end int) {
+	u.flushBytes()
+	u.out.WriteString(text)
+	u.pos = end
+	u.escapes++
+}
+
+// legacyEntity decodes the longest legacy entity name that starts name, such
+// as amp in "ampx", and returns its text and length.
+func legacyEntity(name string) (string, int) {
+	ref := "&" + name
+	decoded := html.UnescapeString(ref)
+	if decoded == ref {
+		return "", 0
+	}
+	// html.UnescapeString keeps the letters after the entity name, and no
+	// entity decodes to an ASCII letter or digit.
+	kept := len(decoded) - len(strings.TrimRightFunc(decoded, func(r rune) bool { return r < 0x80 && isAlnum(byte(r)) }))
+	return decoded[:len(decoded)-kept], len(name) - kept
+}
+
+func isAlnum(c byte) bool {
+	return (c >= '0' && c <= '9') || (c >= 'a' && c <= 'z') || (c >= 'A' && c <= 'Z')
+}
+
+// percent decodes %HH as a byte, or keeps a bare '%'.
+func (u *unescaper) percent() {
+	if u.pos+3 <= len(u.input) {
+		if b, err := strconv.ParseUint(u.input[u.pos+1:u.pos+3], 16, 8); err == nil {
+			u.bytes = append(u.bytes, byte(b))
+			u.pos += 3
+			u.escapes++
+			return
+		}
+	}
+	u.writeRune('%')
+	u.pos++
+}
+
+func (u *unescaper) takeHex(max int) string {
+	return u.take(max, func(c byte) bool {
+		return (c >= '0' && c <= '9') || (c >= 'a' && c <= 'f') || (c >= 'A' && c <= 'F')
+	})
+}
+
+// take consumes up to max bytes matching ok.
+func (u *unescaper) take(max int, ok func(byte) bool) string {
+	start := u.pos
+	for u.pos < len(u.input) && u.pos-start < max && ok(u.input[u.pos]) {
+		u.pos++
+	}
+	return u.input[start:u.pos]
+}
@@ -0,0 +1,257 @@
+package reverseinput
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// ParseByteLiteral reads the bytes of a byte array literal copied from
+// source code:
+//
+//	Go          []byte{0x41, 0x42}
+//	C           unsigned char buf[] = {65, 0102, 'C', 0};
+//	Java        new byte[]{-61, -87, (byte) 0xE2}
+//	Python      b'\xe2\x82\xac', bytes([65, 66]), bytearray(b"AB")
+//
+// Elements may be hex, binary, octal (0101 or 0o101) or decimal, with digit
+// separators, integer suffixes such as 0x41u and casts such as (byte). Signed
+// bytes from -128 to -1 stand for 0x80 to 0xFF, as in Java. Character
+// literals such as 'A' or '\n' count as their byte. Python bytes literals
+// support \xHH, octal and the usual backslash escapes; adjacent ones are
+// joined as Python does.
+func ParseByteLiteral(input string) ([]byte, error) {
+	if lit, ok := findBytesLiteral(input, 0); ok {
+		return parseBytesLiterals(input, lit)
+	}
+	body, offset := literalBody(input)
+	var out []byte
+	for i, tok := range literalElements(body, offset) {
+		b, suggestion, err := literalByte(tok.Text)
+		if err != nil {
+			return nil, tokenError(tok, i, expectLiteral, suggestion, err)
+		}
+		out = append(out, b)
+	}
+	if len(out) == 0 {
+		return nil, errors.New("byte array literal has no elements")
+	}
+	return out, nil
+}
+
+const expectLiteral = `a byte array literal such as []byte{0x41, 0x42}, {65, 66, 0}, b'\xe2\x82\xac' or new byte[]{-61, -87}`
+
+// bytesLiteral locates a Python bytes literal.
+type bytesLiteral struct {
+	prefix int  // offset of the b, rb or br prefix
+	quote  int  // offset of the opening quote
+	raw    bool // rb'...' or br'...': backslashes are literal
+}
+
+// findBytesLiteral finds the first Python bytes literal at or after from.
+func findBytesLiteral(input string, from int) (bytesLiteral, bool) {
+	for i := from; i+1 < len(input); i++ {
+		if i > 0 && (isIdentByte(input[i-1]) || input[i-1] == '\'' || input[i-1] == '"') {
+			continue
+		}
+		prefix := strings.ToLower(input[i:min(i+3, len(input))])
+		for _, p := range []string{"rb", "br", "b"} {
+			if strings.HasPrefix(prefix, p) && len(prefix) > len(p) && (prefix[len(p)] == '\'' || prefix[len(p)] == '"') {
+				return bytesLiteral{prefix: i, quote: i + len(p), raw: len(p) == 2}, true
+			}
+		}
+	}
+	return bytesLiteral{}, false
+}
+
+// parseBytesLiterals decodes lit and any adjacent literals that follow it.
+func parseBytesLiterals(input string, lit bytesLiteral) ([]byte, error) {
+	var out []byte
+	index := 0
+	for {
+		start, quote, raw := lit.quote, input[lit.quote], lit.raw
+		i := start + 1
+		for ; i < len(input) && input[i] != quote; i++ {
+			c := input[i]
+			switch {
+			case c >= 0x80:
+				return nil, tokenError(Token{input[i : i+1], i, i + 1}, index, expectLiteral, `write non-ASCII bytes as \xHH`,
+					fmt.Errorf("bytes literal at offset %d holds a non-ASCII character", i))
+			case c != '\\' || raw:
+				out = append(out, c)
+			default:
+				b, n, ok := bytesEscape(input[i:])
+				if !ok {
+					end := min(i+n, len(input))
+					if isOctal(input[i+1 : end]) {
+						return nil, tokenError(Token{input[i:end], i, end}, index, expectLiteral, "",
+							fmt.Errorf(`octal escape %s at offset %d exceeds a byte`, input[i:end], i))
+					}
+					return nil, tokenError(Token{input[i:end], i, end}, index, expectLiteral, `write a literal backslash as \\`,
+						fmt.Errorf("invalid escape %q in bytes literal at offset %d", input[i:end], i))
+				}
+				out = append(out, b...)
+				i += n - 1
+			}
+			index++
+		}
+		if i == len(input) {
+			return nil, tokenError(Token{input[start : start+1], start, start + 1}, index, expectLiteral,
+				fmt.Sprintf("close it with %c", quote), fmt.Errorf("unterminated bytes literal at offset %d", start))
+		}
+		next, ok := findBytesLiteral(input, i+1)
+		if !ok || strings.TrimSpace(input[i+1:next.prefix]) != "" {
+			return out, nil
+		}
+		lit = next
+	}
+}
+
+// bytesEscape decodes the backslash escape at the start of s, returning its
+// bytes and length. Octal escapes above \377 do not fit a byte and fail.
+func bytesEscape(s string) ([]byte, int, bool) {
+	if len(s) < 2 {
+		return nil, len(s), false
+	}
+	c := s[1]
+	switch {
+	case c == '\n':
+		return nil, 2, true
+	case c == 'x':
+		if len(s) >= 4 && isHex(s[2:4]) {
+			b, _ := strconv.ParseUint(s[2:4], 16, 8)
+			return []byte{byte(b)}, 4, true
+		}
+		return nil, min(len(s), 4), false
+	case c >= '0' && c <= '7':
+		n := 2
+		for n < len(s) && n < 4 && s[n] >= '0' && s[n] <= '7' {
+			n++
+		}
+		b, _ := strconv.ParseUint(s[1:n], 8, 16)
+		return []byte{byte(b)}, n, b <= 0xFF
+	}
+	if r, ok := simpleEscapes[c]; ok && r < 0x80 {
+		return []byte{byte(r)}, 2, true
+	}
+	return nil, 2, false
+}
+
+// literalBody returns the part of input between the braces of an array
+// literal, or the brackets of a Python list, and its offset in input.
+func literalBody(input string) (string, int) {
+	if open := strings.IndexByte(input, '{'); open >= 0 {
+		if end := strings.LastIndexByte(input, '}'); end > open {
+			return input[open+1 : end], open + 1
+		}
+		return input[open+1:], open + 1
+	}
+	for i := 0; i < len(input); i++ {
+		if input[i] != '[' {
+			continue
+		}
+		rest := strings.TrimSpace(input[i+1:])
+		if rest == "" || rest[0] == ']' {
+			continue
+		}
+		if end := strings.LastIndexByte(input, ']'); end > i {
+			return input[i+1 : end], i + 1
+		}
+		return input[i+1:], i + 1
+	}
+	return input, 0
+}
+
+// literalElements splits an array body at commas and whitespace outside
+// character literals, dropping casts such as (byte) and (uint8_t).
+func literalElements(body string, offset int) []Token {
+	var tokens []Token
+	start := -1
+	flush := func(end int) {
+		if start >= 0 {
+			tokens = append(tokens, Token{body[start:end], offset + start, offset + end})
+			start = -1
+		}
+	}
+	for i := 0; i < len(body); i++ {
+		c := body[i]
+		switch {
+		case c == '\'' || c == '"':
+			if start < 0 {
+				start = i
+			}
+			for i++; i < len(body) && body[i] != c; i++ {
+				if body[i] == '\\' {
+					i++
+				}
+			}
+		case c == '(' && start < 0 && isCast(body[i:]):
+			i += strings.IndexByte(body[i:], ')')
+		case c == ',' || c == '(' || c == ')' || c == ' ' || c == '\t' || c == '\n' || c == '\r':
+			flush(i)
+		case start < 0:
+			start = i
+		}
+	}
+	flush(len(body))
+	return tokens
+}
+
+// literalByte parses one element of an array literal.
+func literalByte(text string) (byte, string, error) {
+	if len(text) >= 2 && (text[0] == '\'' || text[0] == '"') {
+		inner := text[1 : len(text)-1]
+		if text[len(text)-1] != text[0] {
+			return 0, "", fmt.Errorf("unterminated character literal %s", text)
+		}
+		if len(inner) == 1 && inner[0] < 0x80 {
+			return inner[0], "", nil
+		}
+		if strings.HasPrefix(inner, `\`) {
+			if b, n, ok := bytesEscape(inner); ok && n == len(inner) && len(b) == 1 {
+				return b[0], "", nil
+			}
+			if isOctal(inner[1:]) && len(inner) <= 4 {
+				return 0, "", fmt.Errorf(`octal escape %s in %s exceeds a byte`, inner, text)
+			}
+		}
+		return 0, "a character literal must hold one ASCII character or escape", fmt.Errorf("invalid character literal %s", text)
+	}
+
+	digits := strings.TrimRight(text, "uUlL")
+	val, err := strconv.ParseInt(digits, 0, 64)
+	if err != nil {
+		suggestion := ""
+		if isHex(digits) {
+			suggestion = fmt.Sprintf("did you mean 0x%s?", strings.ToUpper(digits))
+		}
+		return 0, suggestion, fmt.Errorf("invalid byte %q in literal", text)
+	}
+	switch {
+	case val < -128 || val > 255:
+		return 0, "bytes are 0 to 255, or -128 to 127 when signed", fmt.Errorf("value %s does not fit in a byte", text)
+	case val < 0:
+		return byte(val + 256), "", nil
+	}
+	return byte(val), "", nil
+}
+
+// isCast reports whether s starts with a type cast such as (byte) or
+// (unsigned char).
+func isCast(s string) bool {
+	end := strings.IndexByte(s, ')')
+	if end < 2 || s[1] >= '0' && s[1] <= '9' {
+		return false
+	}
+	for i := 1; i < end; i++ {
+		if !isIdentByte(s[i]) && s[i] != ' ' {
+			return false
+		}
+	}
+	return true
+}
+
+func isIdentByte(c byte) bool {
+	return c == '_' || c >= '0' && c <= '9' || c|0x20 >= 'a' && c|0x20 <= 'z'
+}
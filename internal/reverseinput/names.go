@@ -0,0 +1,91 @@
+package reverseinput
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+
+	"go_tutorials/internal/ucd"
+)
+
+// SplitNames splits a list of character names. Names contain spaces and
+// hyphens, so only semicolons, commas and newlines separate them.
+func SplitNames(input string) []string {
+	spans := NameSpans(input)
+	names := make([]string, len(spans))
+	for i, name := range spans {
+		names[i] = name.Text
+	}
+	return names
+}
+
+// NameSpans splits input exactly like SplitNames and records where each
+// trimmed name sits.
+func NameSpans(input string) []Token {
+	var names []Token
+	start := 0
+	for i := 0; i <= len(input); i++ {
+		if i < len(input) && input[i] != ';' && input[i] != ',' && input[i] != '\n' {
+			continue
+		}
+		field := input[start:i]
+		trimmed := strings.TrimSpace(field)
+		if trimmed != "" {
+			offset := start + strings.Index(field, trimmed)
+			names = append(names, Token{trimmed, offset, offset + len(trimmed)})
+		}
+		start = i + 1
+	}
+	return names
+}
+
+// BuildStringFromNames converts Unicode character names into a UTF-8 string.
+// Names match loosely (case, spaces, underscores and medial hyphens are
+// ignored), and each may be wrapped in Python's \N{...} syntax.
+func BuildStringFromNames(names []string) (string, error) {
+	return buildNames(joinedSpans(names))
+}
+
+func buildNames(names []Token) (string, error) {
+	if len(names) == 0 {
+		return "", errors.New("no character names provided")
+	}
+	var sb strings.Builder
+	for i, name := range names {
+		r, suggestion, err := lookupName(unwrapNamed(name.Text))
+		if err != nil {
+			return "", tokenError(name, i, expectName, suggestion, err)
+		}
+		sb.WriteRune(r)
+	}
+	return sb.String(), nil
+}
+
+// unwrapNamed strips a \N{...} wrapper from name.
+func unwrapNamed(name string) string {
+	name = strings.TrimSpace(name)
+	if inner, ok := strings.CutPrefix(name, `\N{`); ok {
+		if inner, ok := strings.CutSuffix(inner, "}"); ok {
+			return inner
+		}
+	}
+	return name
+}
+
+// lookupName resolves one character name. For an unknown name it also
+// returns a suggestion listing close matches.
+func lookupName(name string) (rune, string, error) {
+	if r, ok := ucd.Lookup(name); ok {
+		return r, "", nil
+	}
+	err := fmt.Errorf("unknown character name %q", name)
+	matches := ucd.Search(name, 3)
+	if len(matches) == 0 {
+		return 0, "search names with the lookup command", err
+	}
+	suggestions := make([]string, len(matches))
+	for i, m := range matches {
+		suggestions[i] = m.Name
+	}
+	return 0, fmt.Sprintf("did you mean %s?", strings.Join(suggestions, ", ")), err
+}
@@ -9,39 +9,171 @@ import (
 	"unicode/utf8"
 )
 
+// Token is one token of the input together with its byte span.
+type Token struct {
+	Text  string
+	Start int // byte offset of Text within the input
+	End   int // byte offset just past Text
+}
+
+func isSeparator(r rune) bool {
+	return unicode.IsSpace(r) || r == ',' || r == ';'
+}
+
 // Tokenize splits user-provided tokens for reverse parsing.
 func Tokenize(input string) []string {
-	return strings.FieldsFunc(input, func(r rune) bool {
-		return unicode.IsSpace(r) || r == ',' || r == ';'
-	})
+	spans := TokenSpans(input)
+	tokens := make([]string, len(spans))
+	for i, tok := range spans {
+		tokens[i] = tok.Text
+	}
+	return tokens
 }
 
+// TokenSpans splits input exactly like Tokenize and records where each token
+// sits, so parse errors can point into the original input.
+func TokenSpans(input string) []Token {
+	var tokens []Token
+	start := -1
+	for i, r := range input {
+		switch {
+		case isSeparator(r) && start >= 0:
+			tokens = append(tokens, Token{input[start:i], start, i})
+			start = -1
+		case !isSeparator(r) && start < 0:
+			start = i
+		}
+	}
+	if start >= 0 {
+		tokens = append(tokens, Token{input[start:], start, len(input)})
+	}
+	return tokens
+}
+
+// joinedSpans gives plain tokens the spans they have in
+// strings.Join(tokens, " "), for callers that never had the original input.
+func joinedSpans(texts []string) []Token {
+	tokens := make([]Token, len(texts))
+	offset := 0
+	for i, text := range texts {
+		tokens[i] = Token{text, offset, offset + len(text)}
+		offset += len(text) + 1
+	}
+	return tokens
+}
+
+// DefaultMaxCodePoints caps how many runes BuildStringFromCodePoints will
+// produce, so a range such as U+0000..U+10FFFF cannot exhaust memory.
+const DefaultMaxCodePoints = 4096
+
 // BuildStringFromCodePoints converts code point tokens into a UTF-8 string.
+// Besides single values, tokens may be ranges: U+0041..U+005A, 0x3B1-0x3C9
+// or U+1F600..+16 (16 code points from U+1F600). Ranges skip surrogates.
+// At most DefaultMaxCodePoints runes are produced.
 func BuildStringFromCodePoints(tokens []string) (string, error) {
+	return BuildStringFromCodePointsMax(tokens, DefaultMaxCodePoints)
+}
+
+// BuildStringFromCodePointsMax is BuildStringFromCodePoints with a custom cap
+// on the number of runes; max <= 0 means no cap.
+func BuildStringFromCodePointsMax(tokens []string, max int) (string, error) {
+	return buildCodePoints(joinedSpans(tokens), max)
+}
+
+func buildCodePoints(tokens []Token, max int) (string, error) {
 	var runes []rune
-	for _, tok := range tokens {
-		val, err := parseCodePointToken(tok)
+	for i, tok := range tokens {
+		lo, hi, err := parseCodePointRange(tok.Text)
 		if err != nil {
-			return "", err
+			return "", tokenError(tok, i, expectCodePoint, suggestNumber(tok.Text, true), err)
 		}
-		if val < 0 || val > utf8.MaxRune {
-			return "", fmt.Errorf("code point %d out of range for token %q", val, tok)
+		if lo >= 0xD800 && hi <= 0xDFFF {
+			err := fmt.Errorf("code point %q is a surrogate, not a character", tok.Text)
+			if lo != hi {
+				err = fmt.Errorf("range %q contains only surrogates, which are not characters", tok.Text)
+			}
+			return "", tokenError(tok, i, expectCodePoint, "surrogates are UTF-16 code units; use the utf16 mode", err)
+		}
+		for cp := lo; cp <= hi; cp++ {
+			if lo != hi && cp >= 0xD800 && cp <= 0xDFFF {
+				continue
+			}
+			if max > 0 && len(runes) == max {
+				return "", tokenError(tok, i, expectCodePoint, "narrow the range or raise the cap",
+					fmt.Errorf("token %q expands past the limit of %d code points", tok.Text, max))
+			}
+			runes = append(runes, rune(cp))
 		}
-		runes = append(runes, rune(val))
 	}
 	return string(runes), nil
 }
 
+// parseCodePointRange parses a single code point or a range token and
+// returns its inclusive bounds.
+func parseCodePointRange(tok string) (int64, int64, error) {
+	startTok, endTok, isRange := strings.Cut(tok, "..")
+	if !isRange && len(tok) > 1 {
+		// A hyphen after the first character separates 0x3B1-0x3C9.
+		if i := strings.Index(tok[1:], "-"); i >= 0 {
+			startTok, endTok, isRange = tok[:i+1], tok[i+2:], true
+		}
+	}
+	lo, err := codePointValue(startTok, tok)
+	if err != nil || !isRange {
+		return lo, lo, err
+	}
+
+	if count, ok := strings.CutPrefix(endTok, "+"); ok {
+		n, err := strconv.ParseInt(count, 10, 64)
+		if err != nil || n < 1 {
+			return 0, 0, fmt.Errorf("invalid range count %q in token %q", endTok, tok)
+		}
+		hi := lo + n - 1
+		if hi > utf8.MaxRune {
+			return 0, 0, fmt.Errorf("range %q runs past U+10FFFF", tok)
+		}
+		return lo, hi, nil
+	}
+	hi, err := codePointValue(endTok, tok)
+	if err != nil {
+		return 0, 0, err
+	}
+	if hi < lo {
+		return 0, 0, fmt.Errorf("range %q ends before it starts", tok)
+	}
+	return lo, hi, nil
+}
+
+func codePointValue(part, tok string) (int64, error) {
+	val, err := parseCodePointToken(part)
+	if err != nil {
+		return 0, err
+	}
+	if val < 0 || val > utf8.MaxRune {
+		return 0, fmt.Errorf("code point %d out of range for token %q", val, tok)
+	}
+	return val, nil
+}
+
 // BuildStringFromBytes converts byte tokens into a UTF-8 string.
 func BuildStringFromBytes(tokens []string) (string, error) {
+	return buildBytes(joinedSpans(tokens))
+}
+
+func buildBytes(tokens []Token) (string, error) {
 	bytes := make([]byte, len(tokens))
 	for i, tok := range tokens {
-		val, err := parseByteToken(tok)
+		val, err := parseByteToken(tok.Text)
 		if err != nil {
-			return "", err
+			return "", tokenError(tok, i, expectByte, suggestNumber(tok.Text, false), err)
 		}
 		if val < 0 || val > 255 {
-			return "", fmt.Errorf("byte %d out of range for token %q", val, tok)
+			suggestion := ""
+			if val > 255 && val <= utf8.MaxRune {
+				suggestion = "values above 255 are code points; use the codepoints mode"
+			}
+			return "", tokenError(tok, i, expectByte, suggestion,
+				fmt.Errorf("byte %d out of range for token %q", val, tok.Text))
 		}
 		bytes[i] = byte(val)
 	}
@@ -62,19 +194,26 @@ func parseNumericToken(token string, allowCodePointPrefix bool) (int64, error) {
 		return 0, errors.New("empty numeric token")
 	}
 
+	var (
+		val int64
+		err error
+	)
 	upper := strings.ToUpper(token)
 	switch {
 	case allowCodePointPrefix && strings.HasPrefix(upper, "U+"):
-		return strconv.ParseInt(token[2:], 16, 32)
+		val, err = strconv.ParseInt(token[2:], 16, 32)
 	case strings.HasPrefix(token, "0x") || strings.HasPrefix(token, "0X"):
-		return strconv.ParseInt(token[2:], 16, 64)
+		val, err = strconv.ParseInt(token[2:], 16, 64)
 	case strings.HasPrefix(token, "0b") || strings.HasPrefix(token, "0B"):
-		return strconv.ParseInt(token[2:], 2, 64)
+		val, err = strconv.ParseInt(token[2:], 2, 64)
 	default:
-		val, err := strconv.ParseInt(token, 10, 64)
-		if err != nil {
-			return 0, fmt.Errorf("invalid numeric token %q: %w", token, err)
-		}
-		return val, nil
+		val, err = strconv.ParseInt(token, 10, 64)
+	}
+	switch {
+	case errors.Is(err, strconv.ErrRange):
+		return 0, fmt.Errorf("number %q is too large", token)
+	case err != nil:
+		return 0, fmt.Errorf("%q is not a number", token)
 	}
+	return val, nil
 }
@@ -1,6 +1,10 @@
 package reverseinput
 
-import "testing"
+import (
+	"errors"
+	"strings"
+	"testing"
+)
 
 func TestTokenize(t *testing.T) {
 	tokens := Tokenize("U+0041, U+0042;0x0043 68")
@@ -45,3 +49,407 @@ func TestBuildStringInvalidTokens(t *testing.T) {
 		t.Fatalf("expected error for out-of-range byte")
 	}
 }
+
+func TestBuildStringFromEscapes(t *testing.T) {
+	cases := []struct {
+		input string
+		want  string
+	}{
+		{`caf\u00e9`, "café"},
+		{`\xF0\x9F\x98\x8A`, "\U0001F60A"},
+		{`caf\xe9`, "café"},
+		{`caf\xc3\xa9\xff`, "caféÿ"},
+		{`\xe9\xf0\x9f\x98\x8a%C3`, "é\U0001F60AÃ"},
+		{`\uD83D\uDE0A and \u{1F60A} and \U0001F60A`, "\U0001F60A and \U0001F60A and \U0001F60A"},
+		{`tab\there\n\"q\" \101\102`, "tab\there\n\"q\" AB"},
+		{`&eacute;&#x1F600;&#65;`, "é\U0001F600A"},
+		{`%E2%82%AC`, "€"},
+		{`100% & a&b; R&D`, "100% & a&b; R&D"},
+		{`mixed caf\u00e9 %C3%A9 &eacute;`, "mixed café é é"},
+		{`&amp \x41 &lt;`, "& A <"},
+		{`&amp\u0041; &eacutex; &#65\x42 &semi; &#x; &bogus;`, "&A; éx; AB ; &#x; &bogus;"},
+	}
+	for _, tc := range cases {
+		got, err := BuildStringFromEscapes(tc.input)
+		if err != nil {
+			t.Fatalf("BuildStringFromEscapes(%q) returned error: %v", tc.input, err)
+		}
+		if got != tc.want {
+			t.Errorf("BuildStringFromEscapes(%q) = %q, want %q", tc.input, got, tc.want)
+		}
+	}
+
+	for _, input := range []string{`\q`, `trailing\`, `\ud83d alone`, `\u12`, `\U00110000`, `\u{zz}`, `\400`} {
+		if _, err := BuildStringFromEscapes(input); err == nil {
+			t.Errorf("expected error for %q", input)
+		}
+	}
+}
+
+func TestBuildStringFromNames(t *testing.T) {
+	names := SplitNames("latin small letter e with acute; \\N{SNOWMAN},\nGRINNING FACE")
+	if len(names) != 3 {
+		t.Fatalf("expected 3 names, got %q", names)
+	}
+	got, err := BuildStringFromNames(names)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if got != "\u00e9\u2603\U0001F600" {
+		t.Fatalf("unexpected string %q", got)
+	}
+
+	_, err = BuildStringFromNames([]string{"SNOWMEN"})
+	if err == nil || !strings.Contains(err.Error(), "did you mean SNOWMAN") {
+		t.Fatalf("expected a suggestion for a misspelt name, got %v", err)
+	}
+
+	got, err = BuildStringFromEscapes(`a\N{snowman}b`)
+	if err != nil || got != "a\u2603b" {
+		t.Fatalf("BuildStringFromEscapes with \\N{} = %q, %v", got, err)
+	}
+}
+
+func TestBuildStringFromCodePointRanges(t *testing.T) {
+	cases := []struct {
+		tokens []string
+		want   string
+	}{
+		{[]string{"U+0041..U+0045"}, "ABCDE"},
+		{[]string{"0x3B1-0x3B3", "33"}, "\u03b1\u03b2\u03b3!"},
+		{[]string{"U+1F600..+3"}, "\U0001F600\U0001F601\U0001F602"},
+		{[]string{"97-99"}, "abc"},
+		{[]string{"U+D7FF..U+E000"}, "\uD7FF\uE000"},
+	}
+	for _, tc := range cases {
+		got, err := BuildStringFromCodePoints(tc.tokens)
+		if err != nil {
+			t.Fatalf("BuildStringFromCodePoints(%q) returned error: %v", tc.tokens, err)
+		}
+		if got != tc.want {
+			t.Errorf("BuildStringFromCodePoints(%q) = %q, want %q", tc.tokens, got, tc.want)
+		}
+	}
+
+	for _, tok := range []string{"U+005A..U+0041", "U+10FFFF..+2", "U+0041..+0", "U+0041..U+11FFFF", "U+0041..zz"} {
+		if _, err := BuildStringFromCodePoints([]string{tok}); err == nil {
+			t.Errorf("expected error for %q", tok)
+		}
+	}
+
+	if _, err := BuildStringFromCodePoints([]string{"U+D800..U+D8FF"}); err == nil || !strings.Contains(err.Error(), "contains only surrogates") {
+		t.Fatalf("expected a surrogate-only range to be reported, got %v", err)
+	}
+	if _, err := BuildStringFromCodePoints([]string{"U+0000..U+10FFFF"}); err == nil || !strings.Contains(err.Error(), "limit of 4096") {
+		t.Fatalf("expected the default cap to stop a full-range token, got %v", err)
+	}
+	if _, err := BuildStringFromCodePointsMax([]string{"U+0041..U+005A", "U+0061"}, 26); err == nil {
+		t.Fatalf("expected the cap to count runes across tokens")
+	}
+	if got, err := BuildStringFromCodePointsMax([]string{"U+0000..U+1FFF"}, 0); err != nil || len([]rune(got)) != 0x2000 {
+		t.Fatalf("expected no cap with max 0, got %d runes, %v", len([]rune(got)), err)
+	}
+}
+
+func TestBuildStringFromUTF16(t *testing.T) {
+	cases := []struct {
+		tokens []string
+		want   string
+	}{
+		{[]string{"0xD83D", "0xDE0A"}, "\U0001F60A"},
+		{[]string{`\uD83D\uDE0A`, "U+0041", "98"}, "\U0001F60AAb"},
+		{[]string{"0xFEFF", "0x00E9"}, "\u00e9"},
+		{[]string{"0xFFFE", "0x3DD8", "0x0ADE"}, "\U0001F60A"},
+	}
+	for _, tc := range cases {
+		got, err := BuildStringFromUTF16(tc.tokens)
+		if err != nil {
+			t.Fatalf("BuildStringFromUTF16(%q) returned error: %v", tc.tokens, err)
+		}
+		if got != tc.want {
+			t.Errorf("BuildStringFromUTF16(%q) = %q, want %q", tc.tokens, got, tc.want)
+		}
+	}
+
+	errorCases := []struct {
+		tokens []string
+		want   string
+	}{
+		{[]string{"0x41", "0xD83D", "0x42"}, `unpaired high surrogate 0xD83D at unit 1 (token 2 "0xD83D")`},
+		{[]string{`\u0041\uDE0A`}, "unpaired low surrogate 0xDE0A at unit 1"},
+		{[]string{"0xD83D"}, "unpaired high surrogate"},
+		{[]string{"0x1F60A"}, "out of range"},
+		{nil, "no UTF-16 code units"},
+	}
+	for _, tc := range errorCases {
+		_, err := BuildStringFromUTF16(tc.tokens)
+		if err == nil || !strings.Contains(err.Error(), tc.want) {
+			t.Errorf("BuildStringFromUTF16(%q) error = %v, want it to contain %q", tc.tokens, err, tc.want)
+		}
+	}
+}
+
+func TestTokenSpans(t *testing.T) {
+	got := TokenSpans(" U+0041,0x42  67")
+	want := []Token{{"U+0041", 1, 7}, {"0x42", 8, 12}, {"67", 14, 16}}
+	if len(got) != len(want) {
+		t.Fatalf("TokenSpans returned %v, want %v", got, want)
+	}
+	for i := range want {
+		if got[i] != want[i] {
+			t.Errorf("TokenSpans()[%d] = %+v, want %+v", i, got[i], want[i])
+		}
+	}
+}
+
+func TestBuildParseError(t *testing.T) {
+	cases := []struct {
+		mode       Mode
+		input      string
+		index      int
+		token      string
+		suggestion string
+	}{
+		{ModeCodePoints, "U+0041 1F60A 67", 1, "1F60A", "did you mean U+1F60A?"},
+		{ModeCodePoints, "0x41 U0042", 1, "U0042", "did you mean U+0042?"},
+		{ModeCodePoints, "0xD800", 0, "0xD800", "use the utf16 mode"},
+		{ModeCodePoints, "U+0041 U+D800..U+D8FF", 1, "U+D800..U+D8FF", "use the utf16 mode"},
+		{ModeBytes, "0xF0 0x9F 300", 2, "300", "use the codepoints mode"},
+		{ModeBytes, "F0 9F", 0, "F0", "did you mean 0xF0?"},
+		{ModeUTF16, "0x41 0xD83D 0x42", 1, "0xD83D", "low surrogate"},
+		{ModeNames, "SNOWMAN; SNOWMEN", 1, "SNOWMEN", "did you mean"},
+		{ModeEscaped, `ok \q then`, 0, `\q`, "literal backslash"},
+	}
+	for _, tc := range cases {
+		_, err := Build(tc.mode, tc.input, 0)
+		var perr *ParseError
+		if !errors.As(err, &perr) {
+			t.Fatalf("Build(%s, %q) error = %v, want a *ParseError", tc.mode, tc.input, err)
+		}
+		if perr.Index != tc.index || perr.Token != tc.token {
+			t.Errorf("Build(%s, %q) failed at token %d %q, want %d %q", tc.mode, tc.input, perr.Index, perr.Token, tc.index, tc.token)
+		}
+		if got := tc.input[perr.Start:perr.End]; got != tc.token {
+			t.Errorf("Build(%s, %q) span %d..%d covers %q, want %q", tc.mode, tc.input, perr.Start, perr.End, got, tc.token)
+		}
+		if perr.Expected == "" || !strings.Contains(perr.Suggestion, tc.suggestion) {
+			t.Errorf("Build(%s, %q) expected %q, suggestion %q, want it to contain %q", tc.mode, tc.input, perr.Expected, perr.Suggestion, tc.suggestion)
+		}
+	}
+}
+
+func TestParseHexDump(t *testing.T) {
+	want := "Hello, world! caf\xc3\xa9\n"
+	cases := map[string]string{
+		"xxd": "00000000: 4865 6c6c 6f2c 2077 6f72 6c64 2120 6361  Hello, world! ca\n" +
+			"00000010: 66c3 a90a                                f...\n",
+		"xxd -p": "48656c6c6f2c20776f726c6421206361\n66c3a90a\n",
+		"hexdump -C": "00000000  48 65 6c 6c 6f 2c 20 77  6f 72 6c 64 21 20 63 61  |Hello, world! ca|\n" +
+			"00000010  66 c3 a9 0a                                       |f...|\n" +
+			"00000014\n",
+		"od -x": "0000000 6548 6c6c 2c6f 7720 726f 646c 2021 6163\n" +
+			"0000020 c366 0aa9\n" +
+			"0000024\n",
+		"od": "0000000 062510 066154 026157 073440 071157 062154 020041 060543\n" +
+			"0000020 141546 005251\n" +
+			"0000024\n",
+		"od -A x -t x1z": "000000 48 65 6c 6c 6f 2c 20 77 6f 72 6c 64 21 20 63 61  >Hello, world! ca<\n" +
+			"000010 66 c3 a9 0a                                      >f...<\n" +
+			"000014\n",
+		"wireshark": "0000   48 65 6c 6c 6f 2c 20 77 6f 72 6c 64 21 20 63 61   Hello, world! ca\r\n" +
+			"0010   66 c3 a9 0a                                       f...\r\n",
+	}
+	for name, dump := range cases {
+		got, err := ParseHexDump(dump)
+		if err != nil {
+			t.Fatalf("ParseHexDump(%s) returned error: %v", name, err)
+		}
+		if string(got) != want {
+			t.Errorf("ParseHexDump(%s) = %q, want %q", name, got, want)
+		}
+	}
+}
+
+func TestParseHexDumpRepeatsAndPadding(t *testing.T) {
+	// hexdump collapses repeated lines into "*"; od -x pads the last word.
+	dump := "00000000  00 00 00 00 00 00 00 00  00 00 00 00 00 00 00 00  |................|\n" +
+		"*\n" +
+		"00000030  41 42 43                                          |ABC|\n" +
+		"00000033\n"
+	got, err := ParseHexDump(dump)
+	if err != nil {
+		t.Fatalf("ParseHexDump returned error: %v", err)
+	}
+	if want := strings.Repeat("\x00", 48) + "ABC"; string(got) != want {
+		t.Errorf("ParseHexDump = %q, want %q", got, want)
+	}
+
+	got, err = ParseHexDump("0000000 4241 0043\n0000003\n")
+	if err != nil || string(got) != "ABC" {
+		t.Errorf("ParseHexDump(od -x odd length) = %q, %v, want %q", got, err, "ABC")
+	}
+
+	// A gutter that looks like hex is cut off by the next line's offset.
+	got, err = ParseHexDump("0000  ca fe  cafe\n0002  ba be  babe\n")
+	if err != nil || string(got) != "\xca\xfe\xba\xbe" {
+		t.Errorf("ParseHexDump(hex-like gutter) = %q, %v", got, err)
+	}
+}
+
+func TestParseHexDumpXXDEightColumns(t *testing.T) {
+	// xxd -c 8 puts the gutter a double space after the eighth byte, where
+	// hexdump -C splits its data; the colon tells the two apart.
+	cases := map[string]string{
+		"00000000: 3031 3233 3435 3637  01234567\n" +
+			"00000008: 6162 2063 6420 6566  ab cd ef\n": "01234567ab cd ef",
+		"00000000: 6162 2063 6420 6566  ab cd ef\n" +
+			"00000008: 3132 2033 3420 3536  12 34 56\n": "ab cd ef12 34 56",
+		"00000000: 6162 6364 6566 6768  abcdefgh\n" +
+			"00000008: 3132 3334 35         12345\n": "abcdefgh12345",
+	}
+	for dump, want := range cases {
+		got, err := ParseHexDump(dump)
+		if err != nil {
+			t.Fatalf("ParseHexDump(%q) returned error: %v", dump, err)
+		}
+		if string(got) != want {
+			t.Errorf("ParseHexDump(%q) = %q, want %q", dump, got, want)
+		}
+	}
+}
+
+func TestParseHexDumpErrors(t *testing.T) {
+	cases := []struct {
+		dump  string
+		token string
+		want  string
+	}{
+		{"00000000: 4865 6c6c  Hel\n00000010: 6c6f  lo\n", "6c6c", "line 1 holds 4 bytes but the next offset is 16 bytes further"},
+		{"00000010: 4142  AB\n00000000: 4344  CD\n", "00000000", "comes before offset"},
+		{"Frame 1: 60 bytes\n", "Frame", "line 1 has no hex bytes"},
+		{"*\n", "*", `unexpected "*" line`},
+		{"\n\n", "", "contains no bytes"},
+	}
+	for _, tc := range cases {
+		_, err := ParseHexDump(tc.dump)
+		if err == nil || !strings.Contains(err.Error(), tc.want) {
+			t.Errorf("ParseHexDump(%q) error = %v, want it to contain %q", tc.dump, err, tc.want)
+			continue
+		}
+		var perr *ParseError
+		if tc.token != "" && (!errors.As(err, &perr) || perr.Token != tc.token || tc.dump[perr.Start:perr.End] != tc.token) {
+			t.Errorf("ParseHexDump(%q) error = %#v, want it to point at %q", tc.dump, err, tc.token)
+		}
+	}
+}
+
+func TestBinaryTextDecoders(t *testing.T) {
+	cases := []struct {
+		mode  Mode
+		input string
+		want  string
+	}{
+		{ModeBase64, "Y2Fmw6k=", "café"},
+		{ModeBase64, "Y2Fm\nw6k", "café"},
+		{ModeBase64, "-_8", "\xfb\xff"},
+		{ModeBase64, "+/8=", "\xfb\xff"},
+		{ModeBase32, "MNQWNQ5J", "café"},
+		{ModeBase32, "mnqw nq5j", "café"},
+		{ModeBase32, "MFRA====", "ab"},
+		{ModeASCII85, "@prueW;", "café"},
+		{ModeASCII85, "<~@prueW;~>", "café"},
+		{ModeASCII85, "z", "\x00\x00\x00\x00"},
+		{ModeQP, "caf=C3=a9 =\r\nsoft", "café soft"},
+	}
+	for _, tc := range cases {
+		got, err := Build(tc.mode, tc.input, 0)
+		if err != nil {
+			t.Fatalf("Build(%s, %q) returned error: %v", tc.mode, tc.input, err)
+		}
+		if got != tc.want {
+			t.Errorf("Build(%s, %q) = %q, want %q", tc.mode, tc.input, got, tc.want)
+		}
+	}
+
+	errorCases := []struct {
+		mode       Mode
+		input      string
+		token      string
+		suggestion string
+	}{
+		{ModeBase64, "eyJhbGciOiJIUzI1NiJ9.eyJzdWIiOiIxIn0", ".", "JWT"},
+		{ModeBase64, "Y2Fm w6kA1", "1", "truncated"},
+		{ModeBase64, "ab+c-d", "-", "one alphabet"},
+		{ModeBase32, "MNQW0Q5J", "0", "2 to 7"},
+		{ModeASCII85, "@pr~eW;", "~", "! to u"},
+		{ModeQP, "caf=C3=Z9", "=Z9", "=3D"},
+	}
+	for _, tc := range errorCases {
+		_, err := Build(tc.mode, tc.input, 0)
+		var perr *ParseError
+		if !errors.As(err, &perr) {
+			t.Fatalf("Build(%s, %q) error = %v, want a *ParseError", tc.mode, tc.input, err)
+		}
+		if perr.Token != tc.token || tc.input[perr.Start:perr.End] != tc.token || !strings.Contains(perr.Suggestion, tc.suggestion) {
+			t.Errorf("Build(%s, %q) error = %+v, want token %q and a suggestion with %q", tc.mode, tc.input, perr, tc.token, tc.suggestion)
+		}
+	}
+}
+
+func TestParseByteLiteral(t *testing.T) {
+	cases := []struct {
+		input string
+		want  string
+	}{
+		{"[]byte{0x41, 0x42}", "AB"},
+		{"{65, 66, 0}", "AB\x00"},
+		{`b'\xe2\x82\xac'`, "\xe2\x82\xac"},
+		{"new byte[]{-61, -87}", "\xc3\xa9"},
+		{"unsigned char buf[] = {0101, 'B', (unsigned char)0x43u, '\\n'};", "ABC\n"},
+		{"byte[] b = {(byte) 0xE2, (byte)0x82, -84};", "\xe2\x82\xac"},
+		{"bytes([0b1000001, 0o102, 1_0_0])", "ABd"},
+		{`bytearray(b"ab" b'\143' rb'\x')`, `abc\x`},
+		{"{'a', 'b'}", "ab"},
+		{"65 66", "AB"},
+	}
+	for _, tc := range cases {
+		got, err := ParseByteLiteral(tc.input)
+		if err != nil {
+			t.Fatalf("ParseByteLiteral(%q) returned error: %v", tc.input, err)
+		}
+		if string(got) != tc.want {
+			t.Errorf("ParseByteLiteral(%q) = %q, want %q", tc.input, got, tc.want)
+		}
+	}
+
+	errorCases := []struct {
+		input      string
+		token      string
+		suggestion string
+	}{
+		{"{0x41, 300}", "300", "-128 to 127"},
+		{"{0x41, -129}", "-129", "-128 to 127"},
+		{"{0x41, E2}", "E2", "did you mean 0xE2?"},
+		{`b'\xZZ'`, `\xZZ`, "backslash"},
+		{"{'ab'}", "'ab'", "one ASCII character"},
+		{"{''}", "''", "one ASCII character"},
+	}
+	for _, tc := range errorCases {
+		_, err := ParseByteLiteral(tc.input)
+		var perr *ParseError
+		if !errors.As(err, &perr) {
+			t.Fatalf("ParseByteLiteral(%q) error = %v, want a *ParseError", tc.input, err)
+		}
+		if perr.Token != tc.token || tc.input[perr.Start:perr.End] != tc.token || !strings.Contains(perr.Suggestion, tc.suggestion) {
+			t.Errorf("ParseByteLiteral(%q) error = %+v, want token %q and a suggestion with %q", tc.input, perr, tc.token, tc.suggestion)
+		}
+	}
+	for _, input := range []string{`b'\777'`, `b'A\400'`, `{'\777'}`} {
+		if _, err := ParseByteLiteral(input); err == nil || !strings.Contains(err.Error(), "exceeds a byte") {
+			t.Errorf("ParseByteLiteral(%q) error = %v, want an octal escape that exceeds a byte", input, err)
+		}
+	}
+	if _, err := ParseByteLiteral("[]byte{}"); err == nil {
+		t.Errorf("expected an error for an empty literal")
+	}
+}
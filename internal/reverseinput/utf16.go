@@ -0,0 +1,93 @@
+package reverseinput
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"unicode/utf16"
+)
+
+// Byte-order marks as a UTF-16 code unit, read in the right and the wrong
+// byte order.
+const (
+	bomUnit        = 0xFEFF
+	swappedBOMUnit = 0xFFFE
+)
+
+// BuildStringFromUTF16 converts UTF-16 code unit tokens into a UTF-8 string,
+// combining surrogate pairs into single runes. Tokens use the code point
+// notations (0xD83D, U+D83D, decimal) or \uD83D escapes, several of which
+// may run together as in \uD83D\uDE0A. A leading byte-order mark is dropped;
+// a byte-swapped one (0xFFFE) means every following unit is byte-swapped.
+// An unpaired surrogate is an error that names its position.
+func BuildStringFromUTF16(tokens []string) (string, error) {
+	return buildUTF16(joinedSpans(tokens))
+}
+
+func buildUTF16(tokens []Token) (string, error) {
+	var (
+		units  []uint16
+		origin []int // index of the token each unit came from
+	)
+	for i, tok := range tokens {
+		parts := []string{tok.Text}
+		if strings.HasPrefix(tok.Text, `\u`) || strings.HasPrefix(tok.Text, `\U`) {
+			parts = strings.FieldsFunc(tok.Text, func(r rune) bool { return r == '\\' })
+		}
+		for _, part := range parts {
+			if rest, ok := strings.CutPrefix(part, "u"); ok {
+				part = "0x" + rest
+			} else if rest, ok := strings.CutPrefix(part, "U"); ok && !strings.HasPrefix(rest, "+") {
+				part = "0x" + rest
+			}
+			val, err := parseNumericToken(part, true)
+			if err != nil {
+				return "", tokenError(tok, i, expectUTF16, suggestNumber(tok.Text, false),
+					fmt.Errorf("%q is not a UTF-16 code unit", tok.Text))
+			}
+			if val < 0 || val > 0xFFFF {
+				return "", tokenError(tok, i, expectUTF16, "code points above U+FFFF are written as a surrogate pair",
+					fmt.Errorf("UTF-16 code unit %d out of range for token %q", val, tok.Text))
+			}
+			units = append(units, uint16(val))
+			origin = append(origin, i)
+		}
+	}
+	if len(units) == 0 {
+		return "", errors.New("no UTF-16 code units provided")
+	}
+
+	start := 0
+	switch units[0] {
+	case bomUnit:
+		start = 1
+	case swappedBOMUnit:
+		start = 1
+		for i := range units {
+			units[i] = units[i]<<8 | units[i]>>8
+		}
+	}
+
+	unpaired := func(kind string, i int, suggestion string) error {
+		tok := tokens[origin[i]]
+		return tokenError(tok, origin[i], expectUTF16, suggestion,
+			fmt.Errorf("unpaired %s surrogate 0x%04X at unit %d (token %d %q)", kind, units[i], i, origin[i]+1, tok.Text))
+	}
+	var sb strings.Builder
+	for i := start; i < len(units); i++ {
+		u := rune(units[i])
+		switch {
+		case u >= 0xD800 && u <= 0xDBFF:
+			if i+1 < len(units) && units[i+1] >= 0xDC00 && units[i+1] <= 0xDFFF {
+				sb.WriteRune(utf16.DecodeRune(u, rune(units[i+1])))
+				i++
+				continue
+			}
+			return "", unpaired("high", i, "follow it with a low surrogate from 0xDC00 to 0xDFFF")
+		case u >= 0xDC00 && u <= 0xDFFF:
+			return "", unpaired("low", i, "precede it with a high surrogate from 0xD800 to 0xDBFF")
+		}
+		sb.WriteRune(u)
+	}
+	return sb.String(), nil
+}
@@ -0,0 +1,200 @@
+package ucd
+
+import (
+	"slices"
+	"strconv"
+	"strings"
+	"sync"
+)
+
+var (
+	lookupOnce sync.Once
+	byName     map[string]rune // loose name key -> code point
+	sortedRefs []nameRef       // every named code point in code point order
+)
+
+type nameRef struct {
+	r     rune
+	name  string
+	words []string
+}
+
+// loadLookup builds the reverse name index from names.txt plus the Hangul
+// syllables, whose names are derived rather than listed.
+func loadLookup() {
+	namesOnce.Do(loadNames)
+	byName = make(map[string]rune, len(names)+hangulCount)
+	sortedRefs = make([]nameRef, 0, len(names))
+	for r, name := range names {
+		byName[looseKey(name)] = r
+		sortedRefs = append(sortedRefs, nameRef{r: r, name: name, words: strings.Fields(name)})
+	}
+	slices.SortFunc(sortedRefs, func(a, b nameRef) int { return int(a.r - b.r) })
+	for s := rune(0); s < hangulCount; s++ {
+		byName[looseKey(Name(hangulBase+s))] = hangulBase + s
+	}
+}
+
+// looseKey applies the UAX #44 loose matching rule (UAX44-LM2): case,
+// spaces, underscores and medial hyphens are ignored. The one name the rule
+// exempts, HANGUL JUNGSEONG O-E, keeps its hyphen so that it stays distinct
+// from HANGUL JUNGSEONG OE.
+func looseKey(name string) string {
+	upper := strings.ToUpper(strings.TrimSpace(name))
+	var sb strings.Builder
+	for i := 0; i < len(upper); i++ {
+		switch c := upper[i]; c {
+		case ' ', '_':
+		case '-':
+			if i == 0 || i == len(upper)-1 || upper[i-1] == ' ' || upper[i+1] == ' ' {
+				sb.WriteByte(c)
+			}
+		default:
+			sb.WriteByte(c)
+		}
+	}
+	if key := sb.String(); key != "HANGULJUNGSEONGOE" || !strings.HasSuffix(upper, "O-E") {
+		return key
+	}
+	return "HANGULJUNGSEONGO-E"
+}
+
+// Lookup returns the code point with the given character name, matched
+// loosely as UAX #44 allows, so "latin small letter e with acute" and
+// "Latin_Small_Letter_E_With_Acute" both find U+00E9. Control aliases such as
+// "LINE FEED", Hangul syllables and derived names such as
+// "CJK UNIFIED IDEOGRAPH-4E00" are recognised too.
+func Lookup(name string) (rune, bool) {
+	lookupOnce.Do(loadLookup)
+	key := looseKey(name)
+	if key == "" {
+		return 0, false
+	}
+	if r, ok := byName[key]; ok {
+		return r, true
+	}
+	for _, dr := range derivedNameRanges {
+		// The hyphen before the code point is medial, so the key drops it.
+		hexPart, ok := strings.CutPrefix(key, looseKey(strings.TrimSuffix(dr.prefix, "-")))
+		if !ok {
+			continue
+		}
+		cp, err := strconv.ParseUint(hexPart, 16, 32)
+		if err == nil && rune(cp) >= dr.lo && rune(cp) <= dr.hi {
+			return rune(cp), true
+		}
+	}
+	return 0, false
+}
+
+// Match is one result of a name search.
+type Match struct {
+	Rune rune
+	Name string
+}
+
+// Search finds characters whose names contain every word of query, best
+// matches first: words matching whole name words rank above prefixes, which
+// rank above substrings, and shorter names win ties. When nothing contains
+// the words, names whose words are within a small edit distance are returned
+// instead, so "snowmen" still finds SNOWMAN. At most limit matches are
+// returned; limit <= 0 means no limit.
+func Search(query string, limit int) []Match {
+	lookupOnce.Do(loadLookup)
+	words := strings.Fields(strings.ToUpper(strings.NewReplacer("_", " ", ",", " ").Replace(query)))
+	if len(words) == 0 {
+		return nil
+	}
+
+	type scored struct {
+		ref   nameRef
+		score int
+	}
+	var hits []scored
+	for _, fuzzy := range []bool{false, true} {
+		for _, ref := range sortedRefs {
+			if score, ok := matchWords(ref, words, fuzzy); ok {
+				hits = append(hits, scored{ref, score})
+			}
+		}
+		if len(hits) > 0 {
+			break
+		}
+	}
+	slices.SortStableFunc(hits, func(a, b scored) int {
+		if a.score != b.score {
+			return a.score - b.score
+		}
+		return len(a.ref.name) - len(b.ref.name)
+	})
+	if limit > 0 && len(hits) > limit {
+		hits = hits[:limit]
+	}
+	matches := make([]Match, len(hits))
+	for i, hit := range hits {
+		matches[i] = Match{Rune: hit.ref.r, Name: hit.ref.name}
+	}
+	return matches
+}
+
+// matchWords scores how well every query word matches a name; lower is
+// better. The fuzzy pass only accepts whole words within maxTypos edits.
+func matchWords(ref nameRef, words []string, fuzzy bool) (int, bool) {
+	score := 0
+	for _, word := range words {
+		best := -1
+		for _, nameWord := range ref.words {
+			s := -1
+			switch {
+			case nameWord == word:
+				s = 0
+			case fuzzy && editDistance(nameWord, word) <= maxTypos(word):
+				s = 3
+			case fuzzy:
+			case strings.HasPrefix(nameWord, word):
+				s = 1
+			case strings.Contains(nameWord, word):
+				s = 2
+			}
+			if s >= 0 && (best < 0 || s < best) {
+				best = s
+			}
+		}
+		if best < 0 {
+			return 0, false
+		}
+		score += best
+	}
+	return score, true
+}
+
+func maxTypos(word string) int {
+	switch {
+	case len(word) < 4:
+		return 0
+	case len(word) < 8:
+		return 1
+	}
+	return 2
+}
+
+// editDistance is the Levenshtein distance between two ASCII words.
+func editDistance(a, b string) int {
+	prev := make([]int, len(b)+1)
+	cur := make([]int, len(b)+1)
+	for j := range prev {
+		prev[j] = j
+	}
+	for i := 1; i <= len(a); i++ {
+		cur[0] = i
+		for j := 1; j <= len(b); j++ {
+			cost := 1
+			if a[i-1] == b[j-1] {
+				cost = 0
+			}
+			cur[j] = min(prev[j]+1, cur[j-1]+1, prev[j-1]+cost)
+		}
+		prev, cur = cur, prev
+	}
+	return prev[len(b)]
+}
@@ -0,0 +1,92 @@
+package ucd
+
+import (
+	_ "embed"
+	"fmt"
+	"strconv"
+	"strings"
+	"sync"
+	"unicode"
+)
+
+//go:embed names.txt
+var namesData string
+
+// derivedNameRange covers code points whose name is prefix + hex code point.
+type derivedNameRange struct {
+	lo, hi rune
+	prefix string
+}
+
+var (
+	namesOnce sync.Once
+	names     map[rune]string
+)
+
+func loadNames() {
+	names = make(map[rune]string, strings.Count(namesData, "\n"))
+	for _, line := range strings.Split(namesData, "\n") {
+		hexPart, name, ok := strings.Cut(line, ";")
+		if !ok {
+			continue
+		}
+		cp, err := strconv.ParseUint(hexPart, 16, 32)
+		if err != nil {
+			panic(fmt.Sprintf("ucd: malformed names.txt line %q", line))
+		}
+		names[rune(cp)] = name
+	}
+}
+
+// Hangul syllable names are built from the short names of their jamo
+// (Unicode chapter 3.12).
+const (
+	hangulBase   = 0xAC00
+	hangulCount  = 11172
+	hangulTCount = 28
+	hangulNCount = 21 * hangulTCount
+)
+
+var (
+	jamoL = []string{"G", "GG", "N", "D", "DD", "R", "M", "B", "BB", "S", "SS", "", "J", "JJ", "C", "K", "T", "P", "H"}
+	jamoV = []string{"A", "AE", "YA", "YAE", "EO", "E", "YEO", "YE", "O", "WA", "WAE", "OE", "YO", "U", "WEO", "WE", "WI", "YU", "EU", "YI", "I"}
+	jamoT = []string{"", "G", "GG", "GS", "N", "NJ", "NH", "D", "L", "LG", "LM", "LB", "LS", "LT", "LP", "LH", "M", "B", "BS", "S", "SS", "NG", "J", "C", "K", "T", "P", "H"}
+)
+
+// Name returns the Unicode character name of r. Control characters use their
+// control alias (e.g. "LINE FEED"); code points without a name get a code
+// point label such as "<private-use-E000>" or "<reserved-0378>".
+func Name(r rune) string {
+	if s := r - hangulBase; s >= 0 && s < hangulCount {
+		return "HANGUL SYLLABLE " + jamoL[s/hangulNCount] + jamoV[s%hangulNCount/hangulTCount] + jamoT[s%hangulTCount]
+	}
+	for _, dr := range derivedNameRanges {
+		if r >= dr.lo && r <= dr.hi {
+			return fmt.Sprintf("%s%04X", dr.prefix, r)
+		}
+	}
+	namesOnce.Do(loadNames)
+	if name, ok := names[r]; ok {
+		return name
+	}
+	return label(r)
+}
+
+func label(r rune) string {
+	switch {
+	case r < 0 || r > unicode.MaxRune:
+		return "<invalid>"
+	case unicode.Is(unicode.Cs, r):
+		return fmt.Sprintf("<surrogate-%04X>", r)
+	case unicode.Is(unicode.Co, r):
+		return fmt.Sprintf("<private-use-%04X>", r)
+	case isNoncharacter(r):
+		return fmt.Sprintf("<noncharacter-%04X>", r)
+	default:
+		return fmt.Sprintf("<reserved-%04X>", r)
+	}
+}
+
+func isNoncharacter(r rune) bool {
+	return (r >= 0xFDD0 && r <= 0xFDEF) || r&0xFFFE == 0xFFFE
+}
@@ -0,0 +1,95 @@
+// Package ucd exposes Unicode Character Database properties — names,
+// categories, scripts, blocks and segmentation classes — backed by tables
+// generated from the UCD so every lookup agrees on one Unicode version.
+package ucd
+
+import "sort"
+
+// GraphemeBreak is the Grapheme_Cluster_Break property from UAX #29.
+type GraphemeBreak uint8
+
+// Grapheme_Cluster_Break values; GraphemeOther covers every unlisted rune.
+const (
+	GraphemeOther GraphemeBreak = iota
+	GraphemeCR
+	GraphemeLF
+	GraphemeControl
+	GraphemeExtend
+	GraphemeZWJ
+	GraphemeRegionalIndicator
+	GraphemePrepend
+	GraphemeSpacingMark
+	GraphemeL
+	GraphemeV
+	GraphemeT
+	GraphemeLV
+	GraphemeLVT
+)
+
+// EastAsianWidth is the East_Asian_Width property from UAX #11.
+type EastAsianWidth uint8
+
+// East_Asian_Width values; WidthNeutral covers every unlisted rune.
+const (
+	WidthNeutral EastAsianWidth = iota
+	WidthAmbiguous
+	WidthFullwidth
+	WidthHalfwidth
+	WidthNarrow
+	WidthWide
+)
+
+// rangeValue maps an inclusive code point range to a property value.
+type rangeValue struct {
+	lo, hi uint32
+	value  uint16
+}
+
+// lookup binary-searches a sorted range table and returns the value for r,
+// or 0 when r falls outside every range.
+func lookup(table []rangeValue, r rune) uint16 {
+	cp := uint32(r)
+	i := sort.Search(len(table), func(i int) bool { return table[i].hi >= cp })
+	if i < len(table) && table[i].lo <= cp {
+		return table[i].value
+	}
+	return 0
+}
+
+// GraphemeBreakOf reports the Grapheme_Cluster_Break property of r.
+func GraphemeBreakOf(r rune) GraphemeBreak {
+	return GraphemeBreak(lookup(graphemeBreakTable, r))
+}
+
+// IsExtendedPictographic reports whether r has the Extended_Pictographic property.
+func IsExtendedPictographic(r rune) bool {
+	return lookup(extendedPictographicTable, r) != 0
+}
+
+// EastAsianWidthOf reports the East_Asian_Width property of r. Wide and
+// fullwidth runes take two terminal cells.
+func EastAsianWidthOf(r rune) EastAsianWidth {
+	return EastAsianWidth(lookup(eastAsianWidthTable, r))
+}
+
+// CombiningClass returns the Canonical_Combining_Class of r; starters are 0.
+func CombiningClass(r rune) uint8 {
+	return uint8(lookup(combiningClassTable, r))
+}
+
+// Category returns the two-letter General_Category of r, such as "Lu" or "Mn".
+// Unassigned code points report "Cn".
+func Category(r rune) string {
+	return categoryValues[lookup(categoryTable, r)]
+}
+
+// Script returns the Script property of r, such as "Latin" or "Devanagari".
+// Shared punctuation reports "Common" and combining marks usually "Inherited".
+func Script(r rune) string {
+	return scriptValues[lookup(scriptTable, r)]
+}
+
+// Block returns the name of the Unicode block containing r, or "No_Block".
+func Block(r rune) string {
+	return blockValues[lookup(blockTable, r)]
+}
@@ -0,0 +1,153 @@
+package ucd
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestGraphemeBreakOf(t *testing.T) {
+	cases := []struct {
+		r    rune
+		want GraphemeBreak
+	}{
+		{'A', GraphemeOther},
+		{'\r', GraphemeCR},
+		{'\n', GraphemeLF},
+		{0x0301, GraphemeExtend},
+		{0x200D, GraphemeZWJ},
+		{0x1F1EE, GraphemeRegionalIndicator},
+		{0x1100, GraphemeL},
+		{0xAC00, GraphemeLV},
+		{0xAC01, GraphemeLVT},
+		{0x10FFFF, GraphemeOther},
+	}
+	for _, tc := range cases {
+		if got := GraphemeBreakOf(tc.r); got != tc.want {
+			t.Errorf("GraphemeBreakOf(%U): want %d, got %d", tc.r, tc.want, got)
+		}
+	}
+}
+
+func TestIsExtendedPictographic(t *testing.T) {
+	if !IsExtendedPictographic(0x1F600) {
+		t.Errorf("expected U+1F600 to be Extended_Pictographic")
+	}
+	if IsExtendedPictographic('A') {
+		t.Errorf("expected 'A' not to be Extended_Pictographic")
+	}
+}
+
+func TestName(t *testing.T) {
+	cases := []struct {
+		r    rune
+		want string
+	}{
+		{'A', "LATIN CAPITAL LETTER A"},
+		{0x200B, "ZERO WIDTH SPACE"},
+		{0x0915, "DEVANAGARI LETTER KA"},
+		{'\n', "LINE FEED"},
+		{0xAC01, "HANGUL SYLLABLE GAG"},
+		{0x4E2D, "CJK UNIFIED IDEOGRAPH-4E2D"},
+		{0x1F600, "GRINNING FACE"},
+		{0xE000, "<private-use-E000>"},
+		{0xD800, "<surrogate-D800>"},
+		{0xFFFF, "<noncharacter-FFFF>"},
+		{0x0378, "<reserved-0378>"},
+	}
+	for _, tc := range cases {
+		if got := Name(tc.r); got != tc.want {
+			t.Errorf("Name(%U): want %q, got %q", tc.r, tc.want, got)
+		}
+	}
+}
+
+func TestCategoryScriptBlock(t *testing.T) {
+	cases := []struct {
+		r                       rune
+		category, script, block string
+	}{
+		{'A', "Lu", "Latin", "Basic Latin"},
+		{0x0301, "Mn", "Inherited", "Combining Diacritical Marks"},
+		{0x200B, "Cf", "Common", "General Punctuation"},
+		{0x0915, "Lo", "Devanagari", "Devanagari"},
+		{0x0378, "Cn", "Unknown", "Greek and Coptic"},
+		{0x2FE0, "Cn", "Unknown", "No_Block"},
+	}
+	for _, tc := range cases {
+		if got := Category(tc.r); got != tc.category {
+			t.Errorf("Category(%U): want %s, got %s", tc.r, tc.category, got)
+		}
+		if got := Script(tc.r); got != tc.script {
+			t.Errorf("Script(%U): want %s, got %s", tc.r, tc.script, got)
+		}
+		if got := Block(tc.r); got != tc.block {
+			t.Errorf("Block(%U): want %s, got %s", tc.r, tc.block, got)
+		}
+	}
+}
+
+func TestCombiningClass(t *testing.T) {
+	cases := map[rune]uint8{'A': 0, 0x0301: 230, 0x0323: 220, 0x093C: 7, 0x05B8: 18}
+	for r, want := range cases {
+		if got := CombiningClass(r); got != want {
+			t.Errorf("CombiningClass(%U): want %d, got %d", r, want, got)
+		}
+	}
+}
+
+func TestEastAsianWidthOf(t *testing.T) {
+	cases := map[rune]EastAsianWidth{
+		'A': WidthNarrow, 0x00E9: WidthAmbiguous, 0x4E00: WidthWide, 0xFF21: WidthFullwidth,
+		0xFF76: WidthHalfwidth, 0x1F600: WidthWide, 0x0300: WidthAmbiguous, 0x05D0: WidthNeutral,
+	}
+	for r, want := range cases {
+		if got := EastAsianWidthOf(r); got != want {
+			t.Errorf("EastAsianWidthOf(%U): want %d, got %d", r, want, got)
+		}
+	}
+}
+
+func TestLookup(t *testing.T) {
+	cases := []struct {
+		name string
+		want rune
+	}{
+		{"SNOWMAN", 0x2603},
+		{"latin small letter e with acute", 0xE9},
+		{"Latin_Small_Letter_E_With_Acute", 0xE9},
+		{"LINE FEED", 0x0A},
+		{"CJK UNIFIED IDEOGRAPH-4E00", 0x4E00},
+		{"hangul syllable gag", 0xAC01},
+		{"HANGUL JUNGSEONG O-E", 0x1180},
+		{"HANGUL JUNGSEONG OE", 0x116C},
+	}
+	for _, tc := range cases {
+		if got, ok := Lookup(tc.name); !ok || got != tc.want {
+			t.Errorf("Lookup(%q) = %U, %v; want %U", tc.name, got, ok, tc.want)
+		}
+	}
+	for _, name := range []string{"", "NOT A CHARACTER", "CJK UNIFIED IDEOGRAPH-0041"} {
+		if r, ok := Lookup(name); ok {
+			t.Errorf("Lookup(%q) = %U, want no match", name, r)
+		}
+	}
+}
+
+func TestSearch(t *testing.T) {
+	matches := Search("e acute", 2)
+	if len(matches) != 2 || matches[0].Rune != 0xE9 || matches[1].Rune != 0xC9 {
+		t.Fatalf("unexpected matches for %q: %v", "e acute", matches)
+	}
+	for _, m := range Search("arrow", 0) {
+		if !strings.Contains(m.Name, "ARROW") {
+			t.Fatalf("match %v does not contain ARROW", m)
+		}
+	}
+	// Typos fall back to whole-word fuzzy matching.
+	if fuzzy := Search("snowmen", 1); len(fuzzy) != 1 || fuzzy[0].Rune != 0x2603 {
+		t.Fatalf("expected SNOWMAN for a misspelt query, got %v", fuzzy)
+	}
+	if none := Search("zzzzqqqq", 5); len(none) != 0 {
+		t.Fatalf("expected no matches, got %v", none)
+	}
+}
@@ -0,0 +1,82 @@
+package visualiser
+
+import (
+	"encoding/ascii85"
+	"encoding/base32"
+	"encoding/base64"
+	"fmt"
+	"strings"
+)
+
+// TextEncoding names a binary-to-text encoding of a string's UTF-8 bytes,
+// the form bytes take in JWTs, email bodies and HTTP headers.
+type TextEncoding string
+
+// Supported binary-to-text encodings.
+const (
+	TextBase64          TextEncoding = "base64"
+	TextBase64URL       TextEncoding = "base64url"
+	TextBase32          TextEncoding = "base32"
+	TextASCII85         TextEncoding = "ascii85"
+	TextQuotedPrintable TextEncoding = "quoted-printable"
+)
+
+// AllTextEncodings lists every binary-to-text encoding in display order.
+var AllTextEncodings = []TextEncoding{TextBase64, TextBase64URL, TextBase32, TextASCII85, TextQuotedPrintable}
+
+// Label returns a human-friendly name such as "Base64 URL".
+func (e TextEncoding) Label() string {
+	switch e {
+	case TextBase64:
+		return "Base64"
+	case TextBase64URL:
+		return "Base64 URL"
+	case TextBase32:
+		return "Base32"
+	case TextASCII85:
+		return "Ascii85"
+	case TextQuotedPrintable:
+		return "Quoted-printable"
+	}
+	return string(e)
+}
+
+// EncodeText encodes the UTF-8 bytes of s. Base64 and Base32 are padded;
+// Base64 URL is unpadded, as in JWTs. Ascii85 is written without the <~ ~>
+// delimiters, and quoted-printable without soft line breaks, so every
+// encoding fits on one line.
+func EncodeText(s string, enc TextEncoding) string {
+	switch enc {
+	case TextBase64:
+		return base64.StdEncoding.EncodeToString([]byte(s))
+	case TextBase64URL:
+		return base64.RawURLEncoding.EncodeToString([]byte(s))
+	case TextBase32:
+		return base32.StdEncoding.EncodeToString([]byte(s))
+	case TextASCII85:
+		buf := make([]byte, ascii85.MaxEncodedLen(len(s)))
+		return string(buf[:ascii85.Encode(buf, []byte(s))])
+	case TextQuotedPrintable:
+		return quotePrintable(s)
+	}
+	return s
+}
+
+// quotePrintable escapes every byte that is not printable ASCII, '=' and
+// spaces that end the text (RFC 2045 rules 1 to 3).
+func quotePrintable(s string) string {
+	var sb strings.Builder
+	trailing := len(strings.TrimRight(s, " "))
+	for i := 0; i < len(s); i++ {
+		c := s[i]
+		switch {
+		case c == ' ' && i < trailing:
+			sb.WriteByte(c)
+		case c > ' ' && c <= '~' && c != '=':
+			sb.WriteByte(c)
+		default:
+			fmt.Fprintf(&sb, "=%02X", c)
+		}
+	}
+	return sb.String()
+}
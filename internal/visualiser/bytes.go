@@ -0,0 +1,102 @@
+package visualiser
+
+import (
+	"errors"
+	"unicode/utf8"
+)
+
+// InvalidReason explains why a byte sequence is not well-formed UTF-8.
+type InvalidReason string
+
+// Reasons reported by AnalyseBytes.
+const (
+	ReasonUnexpectedContinuation InvalidReason = "unexpected continuation byte"
+	ReasonTruncated              InvalidReason = "truncated sequence"
+	ReasonOverlong               InvalidReason = "overlong encoding"
+	ReasonSurrogate              InvalidReason = "encoded surrogate"
+	ReasonOutOfRange             InvalidReason = "out-of-range value"
+)
+
+// InvalidSequence describes one malformed run of bytes found by AnalyseBytes.
+type InvalidSequence struct {
+	Offset int           // byte offset of the first offending byte
+	Index  int           // number of valid runes before the sequence, i.e. its position among the Results
+	Bytes  []string      // offending bytes formatted as 0xHH
+	Reason InvalidReason // why the bytes are not valid UTF-8
+}
+
+// AnalyseBytes decodes raw bytes strictly. Valid runes are returned as
+// Results, and every malformed sequence is reported with its offset and
+// reason instead of being replaced with U+FFFD.
+func AnalyseBytes(input []byte) ([]Result, []InvalidSequence, error) {
+	if len(input) == 0 {
+		return nil, nil, errors.New("input is empty")
+	}
+
+	var (
+		results []Result
+		invalid []InvalidSequence
+	)
+	pos := newPosition()
+	for i := 0; i < len(input); {
+		r, size, reason := decodeStrict(input[i:])
+		if reason != "" {
+			pos.skip(size)
+			invalid = append(invalid, InvalidSequence{
+				Offset: i,
+				Index:  len(results),
+				Bytes:  hexBytes(input[i : i+size]),
+				Reason: reason,
+			})
+		} else {
+			res := analyseRune(r)
+			pos.place(&res)
+			results = append(results, res)
+		}
+		i += size
+	}
+	return results, invalid, nil
+}
+
+// decodeStrict decodes the first sequence of b. For malformed input it
+// returns how many bytes belong to the bad sequence and why it is invalid.
+func decodeStrict(b []byte) (rune, int, InvalidReason) {
+	lead := b[0]
+	var (
+		need int
+		min  rune
+		r    rune
+	)
+	switch {
+	case lead < 0x80:
+		return rune(lead), 1, ""
+	case lead < 0xC0:
+		return 0, 1, ReasonUnexpectedContinuation
+	case lead < 0xE0:
+		need, min, r = 1, 0x80, rune(lead&0x1F)
+	case lead < 0xF0:
+		need, min, r = 2, 0x800, rune(lead&0x0F)
+	case lead < 0xF8:
+		need, min, r = 3, 0x10000, rune(lead&0x07)
+	default:
+		// 0xF8-0xFF once introduced five- and six-byte forms, all beyond U+10FFFF.
+		return 0, 1, ReasonOutOfRange
+	}
+
+	size := 1
+	for size <= need && size < len(b) && b[size]&0xC0 == 0x80 {
+		r = r<<6 | rune(b[size]&0x3F)
+		size++
+	}
+	switch {
+	case size <= need:
+		return 0, size, ReasonTruncated
+	case r < min:
+		return 0, size, ReasonOverlong
+	case r >= 0xD800 && r <= 0xDFFF:
+		return 0, size, ReasonSurrogate
+	case r > utf8.MaxRune:
+		return 0, size, ReasonOutOfRange
+	}
+	return r, size, ""
+}
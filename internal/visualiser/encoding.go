@@ -0,0 +1,119 @@
+package visualiser
+
+import (
+	"encoding/binary"
+	"fmt"
+	"strings"
+	"unicode/utf16"
+)
+
+// Encoding names a Unicode encoding form the visualiser can display.
+type Encoding string
+
+// Supported encoding forms. UTF-16 and UTF-32 come in both byte orders.
+const (
+	UTF8    Encoding = "utf8"
+	UTF16BE Encoding = "utf16be"
+	UTF16LE Encoding = "utf16le"
+	UTF32BE Encoding = "utf32be"
+	UTF32LE Encoding = "utf32le"
+)
+
+// AllEncodings lists every supported encoding in display order.
+var AllEncodings = []Encoding{UTF8, UTF16BE, UTF16LE, UTF32BE, UTF32LE}
+
+// Label returns a human-friendly name such as "UTF-16LE".
+func (e Encoding) Label() string {
+	switch e {
+	case UTF8:
+		return "UTF-8"
+	case UTF16BE:
+		return "UTF-16BE"
+	case UTF16LE:
+		return "UTF-16LE"
+	case UTF32BE:
+		return "UTF-32BE"
+	case UTF32LE:
+		return "UTF-32LE"
+	}
+	return string(e)
+}
+
+// ParseEncodings validates encoding names such as "utf8", "UTF-16LE" or
+// "utf_32be" and returns them in canonical form without duplicates.
+func ParseEncodings(names []string) ([]Encoding, error) {
+	var encodings []Encoding
+	seen := make(map[Encoding]bool)
+	for _, name := range names {
+		key := strings.ToLower(strings.TrimSpace(name))
+		key = strings.NewReplacer("-", "", "_", "").Replace(key)
+		if key == "" {
+			continue
+		}
+		enc := Encoding(key)
+		if !enc.valid() {
+			return nil, fmt.Errorf("unknown encoding %q (use utf8, utf16be, utf16le, utf32be, utf32le)", name)
+		}
+		if !seen[enc] {
+			seen[enc] = true
+			encodings = append(encodings, enc)
+		}
+	}
+	return encodings, nil
+}
+
+func (e Encoding) valid() bool {
+	for _, known := range AllEncodings {
+		if e == known {
+			return true
+		}
+	}
+	return false
+}
+
+// EncodeRune returns the bytes of r in the given encoding form.
+func EncodeRune(r rune, enc Encoding) []byte {
+	switch enc {
+	case UTF16BE, UTF16LE:
+		units := utf16.Encode([]rune{r})
+		out := make([]byte, 0, 2*len(units))
+		for _, u := range units {
+			if enc == UTF16BE {
+				out = binary.BigEndian.AppendUint16(out, u)
+			} else {
+				out = binary.LittleEndian.AppendUint16(out, u)
+			}
+		}
+		return out
+	case UTF32BE:
+		return binary.BigEndian.AppendUint32(nil, uint32(r))
+	case UTF32LE:
+		return binary.LittleEndian.AppendUint32(nil, uint32(r))
+	default:
+		return []byte(string(r))
+	}
+}
+
+// BytesFor returns the hex-formatted bytes of the result in the given encoding.
+func (r Result) BytesFor(enc Encoding) []string {
+	switch enc {
+	case UTF16BE:
+		return r.UTF16BEBytes
+	case UTF16LE:
+		return r.UTF16LEBytes
+	case UTF32BE:
+		return r.UTF32BEBytes
+	case UTF32LE:
+		return r.UTF32LEBytes
+	default:
+		return r.UTF8BytesHex
+	}
+}
+
+func hexBytes(b []byte) []string {
+	parts := make([]string, len(b))
+	for i, by := range b {
+		parts[i] = fmt.Sprintf("0x%02X", by)
+	}
+	return parts
+}
@@ -0,0 +1,280 @@
+package visualiser
+
+import (
+	"fmt"
+	"strings"
+	"unicode/utf16"
+	"unicode/utf8"
+)
+
+// Language names a syntax whose escape sequences the visualiser can produce.
+type Language string
+
+// Supported escape syntaxes.
+const (
+	LangGo     Language = "go"
+	LangJSON   Language = "json"
+	LangJS     Language = "js"
+	LangPython Language = "python"
+	LangJava   Language = "java"
+	LangC      Language = "c"
+	LangRust   Language = "rust"
+	LangCSS    Language = "css"
+	LangURL    Language = "url"
+	LangXML    Language = "xml"
+)
+
+// AllLanguages lists every escape syntax in display order.
+var AllLanguages = []Language{LangGo, LangJSON, LangJS, LangPython, LangJava, LangC, LangRust, LangCSS, LangURL, LangXML}
+
+var languageAliases = map[string]Language{
+	"golang":     LangGo,
+	"javascript": LangJS,
+	"ecmascript": LangJS,
+	"py":         LangPython,
+	"cpp":        LangC,
+	"c++":        LangC,
+	"cxx":        LangC,
+	"rs":         LangRust,
+	"percent":    LangURL,
+	"html":       LangXML,
+}
+
+// Label returns a human-friendly name such as "JavaScript".
+func (l Language) Label() string {
+	switch l {
+	case LangGo:
+		return "Go"
+	case LangJSON:
+		return "JSON"
+	case LangJS:
+		return "JavaScript"
+	case LangPython:
+		return "Python"
+	case LangJava:
+		return "Java"
+	case LangC:
+		return "C/C++"
+	case LangRust:
+		return "Rust"
+	case LangCSS:
+		return "CSS"
+	case LangURL:
+		return "URL"
+	case LangXML:
+		return "XML"
+	}
+	return string(l)
+}
+
+// ParseLanguages validates escape syntax names such as "go", "JavaScript" or
+// "c++" and returns them in canonical form without duplicates.
+func ParseLanguages(names []string) ([]Language, error) {
+	var languages []Language
+	seen := make(map[Language]bool)
+	for _, name := range names {
+		key := strings.ToLower(strings.TrimSpace(name))
+		if key == "" {
+			continue
+		}
+		lang := Language(key)
+		if alias, ok := languageAliases[key]; ok {
+			lang = alias
+		}
+		if !lang.valid() {
+			return nil, fmt.Errorf("unknown escape syntax %q (use go, json, js, python, java, c, rust, css, url, xml)", name)
+		}
+		if !seen[lang] {
+			seen[lang] = true
+			languages = append(languages, lang)
+		}
+	}
+	return languages, nil
+}
+
+func (l Language) valid() bool {
+	for _, known := range AllLanguages {
+		if l == known {
+			return true
+		}
+	}
+	return false
+}
+
+// EscapeRune returns r written purely as an escape sequence, even when the
+// rune is printable ASCII. C/C++ and URL escape the UTF-8 bytes; the other
+// syntaxes escape the code point, except that Java writes line breaks,
+// quotes, backslashes and other ASCII controls with \n-style or octal
+// escapes, as EscapeString does.
+func EscapeRune(r rune, lang Language) string {
+	switch lang {
+	case LangGo:
+		switch {
+		case r < 0x80:
+			return fmt.Sprintf(`\x%02x`, r)
+		case r <= 0xFFFF:
+			return fmt.Sprintf(`\u%04x`, r)
+		}
+		return fmt.Sprintf(`\U%08x`, r)
+	case LangJSON:
+		return utf16Escape(r)
+	case LangJava:
+		// javac translates \u escapes before lexing, so \u000a, \u0022 or
+		// \u005c would break the literal they are in.
+		switch {
+		case shortEscape(r) != "":
+			return shortEscape(r)
+		case r == '"' || r == '\'' || r == '\\':
+			return `\` + string(r)
+		case r < 0x20 || r == 0x7F:
+			return fmt.Sprintf(`\%03o`, r)
+		}
+		return utf16Escape(r)
+	case LangJS:
+		if r > 0xFFFF {
+			return fmt.Sprintf(`\u{%x}`, r)
+		}
+		return fmt.Sprintf(`\u%04x`, r)
+	case LangPython:
+		switch {
+		case r <= 0xFF:
+			return fmt.Sprintf(`\x%02x`, r)
+		case r <= 0xFFFF:
+			return fmt.Sprintf(`\u%04x`, r)
+		}
+		return fmt.Sprintf(`\U%08x`, r)
+	case LangC:
+		return byteEscapes(r, `\x%02x`)
+	case LangRust:
+		if r < 0x80 {
+			return fmt.Sprintf(`\x%02x`, r)
+		}
+		return fmt.Sprintf(`\u{%x}`, r)
+	case LangCSS:
+		return fmt.Sprintf(`\%06x`, r)
+	case LangURL:
+		return byteEscapes(r, "%%%02X")
+	case LangXML:
+		return fmt.Sprintf("&#x%X;", r)
+	}
+	return string(r)
+}
+
+// EscapeString returns s as a literal in the given syntax. Printable ASCII
+// is kept as is, quotes and backslashes are escaped, and everything else is
+// written with EscapeRune. URL and XML output has no surrounding quotes.
+func EscapeString(s string, lang Language) string {
+	switch lang {
+	case LangGo:
+		return fmt.Sprintf("%+q", s)
+	case LangURL:
+		var sb strings.Builder
+		for i := 0; i < len(s); i++ {
+			if c := s[i]; isUnreserved(c) {
+				sb.WriteByte(c)
+			} else {
+				fmt.Fprintf(&sb, "%%%02X", c)
+			}
+		}
+		return sb.String()
+	case LangXML:
+		var sb strings.Builder
+		for _, r := range s {
+			switch r {
+			case '&':
+				sb.WriteString("&amp;")
+			case '<':
+				sb.WriteString("&lt;")
+			case '>':
+				sb.WriteString("&gt;")
+			case '"':
+				sb.WriteString("&quot;")
+			case '\'':
+				sb.WriteString("&apos;")
+			default:
+				if isPrintableASCII(r) {
+					sb.WriteRune(r)
+				} else {
+					sb.WriteString(EscapeRune(r, LangXML))
+				}
+			}
+		}
+		return sb.String()
+	}
+
+	var sb strings.Builder
+	sb.WriteByte('"')
+	for i, r := range s {
+		switch {
+		case r == '"' || r == '\\':
+			sb.WriteByte('\\')
+			sb.WriteRune(r)
+		case isPrintableASCII(r):
+			if lang == LangC && i > 0 && isHexDigit(r) && s[i-1] >= 0x80 {
+				// A hex digit straight after \xNN would extend the escape.
+				sb.WriteString(`" "`)
+			}
+			sb.WriteRune(r)
+		case lang != LangCSS && shortEscape(r) != "":
+			sb.WriteString(shortEscape(r))
+		case (lang == LangC || lang == LangJava) && r < 0x80:
+			// Java would translate \u000a-style escapes before lexing the literal.
+			sb.WriteString(fmt.Sprintf(`\%03o`, r))
+		case lang == LangJSON && r < 0x80:
+			sb.WriteString(fmt.Sprintf(`\u%04x`, r))
+		default:
+			sb.WriteString(EscapeRune(r, lang))
+		}
+	}
+	sb.WriteByte('"')
+	return sb.String()
+}
+
+func shortEscape(r rune) string {
+	switch r {
+	case '\n':
+		return `\n`
+	case '\r':
+		return `\r`
+	case '\t':
+		return `\t`
+	}
+	return ""
+}
+
+func utf16Escape(r rune) string {
+	if r > 0xFFFF {
+		hi, lo := utf16.EncodeRune(r)
+		return fmt.Sprintf(`\u%04x\u%04x`, hi, lo)
+	}
+	return fmt.Sprintf(`\u%04x`, r)
+}
+
+func byteEscapes(r rune, format string) string {
+	var sb strings.Builder
+	buf := make([]byte, utf8.UTFMax)
+	for _, b := range buf[:utf8.EncodeRune(buf, r)] {
+		fmt.Fprintf(&sb, format, b)
+	}
+	return sb.String()
+}
+
+func isPrintableASCII(r rune) bool { return r >= 0x20 && r < 0x7F }
+
+func isHexDigit(r rune) bool {
+	return (r >= '0' && r <= '9') || (r >= 'a' && r <= 'f') || (r >= 'A' && r <= 'F')
+}
+
+func isUnreserved(c byte) bool {
+	return (c >= 'A' && c <= 'Z') || (c >= 'a' && c <= 'z') || (c >= '0' && c <= '9') ||
+		c == '-' || c == '.' || c == '_' || c == '~'
+}
+
+// escapesFor returns the forced escape of r in every supported syntax.
+func escapesFor(r rune) map[Language]string {
+	escapes := make(map[Language]string, len(AllLanguages))
+	for _, lang := range AllLanguages {
+		escapes[lang] = EscapeRune(r, lang)
+	}
+	return escapes
+}
@@ -0,0 +1,112 @@
+package visualiser
+
+import (
+	"errors"
+	"fmt"
+
+	"go_tutorials/internal/ucd"
+)
+
+// Cluster groups the runes that render as one user-perceived character.
+type Cluster struct {
+	Text  string   // whole cluster formatted via %q
+	Runes []Result // per-rune breakdown, in input order
+}
+
+// AnalyseGraphemes splits the input into extended grapheme clusters (UAX #29)
+// and nests the per-rune metadata under each cluster.
+func AnalyseGraphemes(input string) ([]Cluster, error) {
+	if len(input) == 0 {
+		return nil, errors.New("input string is empty")
+	}
+
+	segments := Graphemes(input)
+	clusters := make([]Cluster, 0, len(segments))
+	pos := newPosition()
+	for _, seg := range segments {
+		runes := make([]Result, 0, len(seg))
+		for _, r := range seg {
+			res := analyseRune(r)
+			pos.place(&res)
+			runes = append(runes, res)
+		}
+		clusters = append(clusters, Cluster{
+			Text:  fmt.Sprintf("%q", seg),
+			Runes: runes,
+		})
+	}
+	return clusters, nil
+}
+
+// Graphemes splits s into extended grapheme clusters following the
+// boundary rules of UAX #29.
+func Graphemes(s string) []string {
+	var (
+		clusters []string
+		start    int
+		prev     ucd.GraphemeBreak
+		// emoji tracks GB11: 1 after ExtPict Extend*, 2 once a ZWJ follows.
+		emoji int
+		// regional counts consecutive regional indicators before the current rune.
+		regional int
+	)
+	for i, r := range s {
+		prop := ucd.GraphemeBreakOf(r)
+		pict := ucd.IsExtendedPictographic(r)
+		if i > 0 && isGraphemeBoundary(prev, prop, pict, emoji, regional) {
+			clusters = append(clusters, s[start:i])
+			start = i
+		}
+
+		switch {
+		case pict:
+			emoji = 1
+		case emoji == 1 && prop == ucd.GraphemeExtend:
+		case emoji == 1 && prop == ucd.GraphemeZWJ:
+			emoji = 2
+		default:
+			emoji = 0
+		}
+		if prop == ucd.GraphemeRegionalIndicator {
+			regional++
+		} else {
+			regional = 0
+		}
+		prev = prop
+	}
+	if start < len(s) {
+		clusters = append(clusters, s[start:])
+	}
+	return clusters
+}
+
+// isGraphemeBoundary applies rules GB3–GB999 between prev and the current rune.
+func isGraphemeBoundary(prev, cur ucd.GraphemeBreak, curPict bool, emoji, regional int) bool {
+	switch {
+	case prev == ucd.GraphemeCR && cur == ucd.GraphemeLF: // GB3
+		return false
+	case isControlBreak(prev) || isControlBreak(cur): // GB4, GB5
+		return true
+	case prev == ucd.GraphemeL && (cur == ucd.GraphemeL || cur == ucd.GraphemeV || cur == ucd.GraphemeLV || cur == ucd.GraphemeLVT): // GB6
+		return false
+	case (prev == ucd.GraphemeLV || prev == ucd.GraphemeV) && (cur == ucd.GraphemeV || cur == ucd.GraphemeT): // GB7
+		return false
+	case (prev == ucd.GraphemeLVT || prev == ucd.GraphemeT) && cur == ucd.GraphemeT: // GB8
+		return false
+	case cur == ucd.GraphemeExtend || cur == ucd.GraphemeZWJ: // GB9
+		return false
+	case cur == ucd.GraphemeSpacingMark: // GB9a
+		return false
+	case prev == ucd.GraphemePrepend: // GB9b
+		return false
+	case emoji == 2 && curPict: // GB11
+		return false
+	case prev == ucd.GraphemeRegionalIndicator && cur == ucd.GraphemeRegionalIndicator: // GB12, GB13
+		return regional%2 == 0
+	}
+	return true // GB999
+}
+
+func isControlBreak(p ucd.GraphemeBreak) bool {
+	return p == ucd.GraphemeCR || p == ucd.GraphemeLF || p == ucd.GraphemeControl
+}
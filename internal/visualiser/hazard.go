@@ -0,0 +1,79 @@
+package visualiser
+
+import "fmt"
+
+// Severity ranks how suspicious a hazardous rune is.
+type Severity string
+
+// Severities from least to most dangerous.
+const (
+	SeverityInfo    Severity = "info"    // usually legitimate, e.g. a variation selector
+	SeverityWarning Severity = "warning" // invisible or control characters worth a second look
+	SeverityDanger  Severity = "danger"  // can make text render differently from how it is parsed
+)
+
+// Hazard explains why a rune may be invisible, misleading or unsafe.
+type Hazard struct {
+	Kind        string   // short classification, e.g. bidi-override or zero-width
+	Severity    Severity // info, warning or danger
+	Explanation string   // one-line description for people reading the report
+}
+
+// ClassifyHazard returns the hazard posed by r, or nil for ordinary runes.
+// Tab, line feed and carriage return are treated as ordinary.
+func ClassifyHazard(r rune) *Hazard {
+	switch {
+	case r == '\t' || r == '\n' || r == '\r':
+		return nil
+	case r < 0x20 || r == 0x7F:
+		return &Hazard{"c0-control", SeverityWarning, "C0 control character; invisible and may be interpreted by terminals or parsers"}
+	case r >= 0x80 && r <= 0x9F:
+		return &Hazard{"c1-control", SeverityWarning, "C1 control character; invisible and often a sign of mis-decoded Windows-1252 text"}
+	case r >= 0x202A && r <= 0x202E:
+		return &Hazard{"bidi-override", SeverityDanger, "bidirectional embedding or override; reorders the text that follows (Trojan Source, CVE-2021-42574)"}
+	case r >= 0x2066 && r <= 0x2069:
+		return &Hazard{"bidi-isolate", SeverityDanger, "bidirectional isolate; reorders the text that follows (Trojan Source, CVE-2021-42574)"}
+	case r == 0x200E || r == 0x200F || r == 0x061C:
+		return &Hazard{"bidi-mark", SeverityWarning, "invisible directional mark; changes how neighbouring characters are ordered"}
+	case r >= 0xE0000 && r <= 0xE007F:
+		return &Hazard{"tag", SeverityDanger, "tag character; invisible and can smuggle hidden ASCII text"}
+	case r == 0x200B || r == 0x2060 || r == 0xFEFF:
+		return &Hazard{"zero-width", SeverityWarning, "zero-width character; invisible but makes otherwise identical strings differ"}
+	case r == 0x200C || r == 0x200D:
+		return &Hazard{"zero-width-joiner", SeverityInfo, "zero-width (non-)joiner; needed by emoji and some scripts but invisible elsewhere"}
+	case r == 0x00AD || r == 0x034F || r == 0x180E || (r >= 0x2061 && r <= 0x2064):
+		return &Hazard{"invisible", SeverityWarning, "invisible formatting character with no glyph"}
+	case r == 0x115F || r == 0x1160 || r == 0x3164 || r == 0xFFA0:
+		return &Hazard{"invisible", SeverityWarning, "Hangul filler; renders as blank space"}
+	case r == 0x2028 || r == 0x2029:
+		return &Hazard{"line-separator", SeverityWarning, "Unicode line or paragraph separator; breaks lines in some parsers but not others"}
+	case (r >= 0xFE00 && r <= 0xFE0F) || (r >= 0xE0100 && r <= 0xE01EF):
+		return &Hazard{"variation-selector", SeverityInfo, "variation selector; picks a glyph variant of the preceding character"}
+	case (r >= 0x180B && r <= 0x180D) || r == 0x180F:
+		return &Hazard{"variation-selector", SeverityInfo, "Mongolian free variation selector; picks a glyph variant of the preceding character"}
+	}
+	return nil
+}
+
+// Warning pairs a hazardous rune with its position in the input.
+type Warning struct {
+	Index  int    // rune index within the input
+	Result Result // the hazardous rune
+}
+
+// Warnings lists every result carrying a hazard, in input order.
+func Warnings(results []Result) []Warning {
+	var warnings []Warning
+	for i, res := range results {
+		if res.Hazard != nil {
+			warnings = append(warnings, Warning{Index: i, Result: res})
+		}
+	}
+	return warnings
+}
+
+// String renders the warning for terminal output.
+func (w Warning) String() string {
+	h := w.Result.Hazard
+	return fmt.Sprintf("[%s] #%d %s %s: %s", h.Severity, w.Index+1, w.Result.CodePointHex, w.Result.Name, h.Explanation)
+}
@@ -0,0 +1,46 @@
+package visualiser
+
+import "unicode/utf16"
+
+// position tracks where the next rune starts while an input is walked.
+// Lines end at U+000A, so CRLF counts as a single line break. Columns are
+// 1-based and restart on every line.
+type position struct {
+	offset      int // byte offset from the start of the input
+	index       int // rune index from the start of the input
+	line        int
+	columnUTF8  int // column counted in UTF-8 bytes
+	columnUTF16 int // column counted in UTF-16 code units
+}
+
+func newPosition() position {
+	return position{line: 1, columnUTF8: 1, columnUTF16: 1}
+}
+
+// place stamps the current position onto res and moves past its rune.
+func (p *position) place(res *Result) {
+	res.ByteOffset = p.offset
+	res.RuneIndex = p.index
+	res.Line = p.line
+	res.ColumnUTF8 = p.columnUTF8
+	res.ColumnUTF16 = p.columnUTF16
+
+	r := rune(res.CodePointDec)
+	p.offset += len(res.UTF8BytesHex)
+	p.index++
+	if r == '\n' {
+		p.line++
+		p.columnUTF8, p.columnUTF16 = 1, 1
+		return
+	}
+	p.columnUTF8 += len(res.UTF8BytesHex)
+	p.columnUTF16 += utf16.RuneLen(r)
+}
+
+// skip moves past a malformed sequence of n bytes. It takes no rune index
+// and counts as one UTF-16 unit, the U+FFFD a lenient decoder would emit.
+func (p *position) skip(n int) {
+	p.offset += n
+	p.columnUTF8 += n
+	p.columnUTF16++
+}
@@ -0,0 +1,91 @@
+package visualiser
+
+import (
+	"bufio"
+	"io"
+	"iter"
+	"unicode/utf8"
+)
+
+// DecodedRune is one step of Decode: a rune or a malformed UTF-8 sequence,
+// without the analysis Stream adds.
+type DecodedRune struct {
+	Offset int           // byte offset of the rune within the stream
+	Rune   rune          // decoded rune, utf8.RuneError for malformed bytes
+	Bytes  []byte        // raw bytes, only valid until the next step
+	Reason InvalidReason // why the bytes are malformed, empty for a valid rune
+}
+
+// Decode reads UTF-8 text from r one rune at a time, reassembling runes
+// split across reads and reporting malformed bytes exactly as AnalyseBytes
+// splits them. It allocates nothing per rune, so it suits callers that only
+// need the text. A read error is yielded once and ends the sequence.
+func Decode(r io.Reader) iter.Seq2[DecodedRune, error] {
+	return func(yield func(DecodedRune, error) bool) {
+		br := bufio.NewReader(r)
+		offset := 0
+		for {
+			buf, err := br.Peek(utf8.UTFMax)
+			if len(buf) == 0 {
+				if err != nil && err != io.EOF {
+					yield(DecodedRune{Offset: offset}, err)
+				}
+				return
+			}
+			if err != nil && err != io.EOF && err != bufio.ErrBufferFull {
+				yield(DecodedRune{Offset: offset}, err)
+				return
+			}
+
+			r, size, reason := decodeStrict(buf)
+			if reason != "" {
+				r = utf8.RuneError
+			}
+			if !yield(DecodedRune{Offset: offset, Rune: r, Bytes: buf[:size], Reason: reason}, nil) {
+				return
+			}
+			br.Discard(size) // cannot fail: the bytes were just peeked
+			offset += size
+		}
+	}
+}
+
+// StreamItem is one step of a streamed analysis: either a decoded rune or a
+// malformed UTF-8 sequence. Exactly one of Result and Invalid is set.
+type StreamItem struct {
+	Offset  int              // byte offset of the item within the stream
+	Result  *Result          // decoded rune, nil for malformed bytes
+	Invalid *InvalidSequence // malformed sequence, nil for decoded runes
+}
+
+// Stream analyses UTF-8 text read from r one rune at a time, so memory use
+// stays constant however large the input is. It is Decode with a full
+// Result for every rune.
+func Stream(r io.Reader) iter.Seq2[StreamItem, error] {
+	return func(yield func(StreamItem, error) bool) {
+		pos := newPosition()
+		for dr, err := range Decode(r) {
+			item := StreamItem{Offset: dr.Offset}
+			switch {
+			case err != nil:
+				yield(item, err)
+				return
+			case dr.Reason != "":
+				item.Invalid = &InvalidSequence{
+					Offset: dr.Offset,
+					Index:  pos.index,
+					Bytes:  hexBytes(dr.Bytes),
+					Reason: dr.Reason,
+				}
+				pos.skip(len(dr.Bytes))
+			default:
+				res := analyseRune(dr.Rune)
+				pos.place(&res)
+				item.Result = &res
+			}
+			if !yield(item, nil) {
+				return
+			}
+		}
+	}
+}
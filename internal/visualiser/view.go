@@ -0,0 +1,219 @@
+package visualiser
+
+import (
+	"fmt"
+	"slices"
+	"strconv"
+	"strings"
+)
+
+// SortOrder names an order for result rows.
+type SortOrder string
+
+// Supported sort orders. Sorting is stable, so ties keep input order.
+const (
+	SortInput     SortOrder = "input"     // as the runes appear
+	SortCodePoint SortOrder = "codepoint" // ascending code point
+	SortBytes     SortOrder = "bytes"     // ascending UTF-8 length
+)
+
+// ParseSortOrder resolves a sort order name; empty means input order.
+func ParseSortOrder(name string) (SortOrder, error) {
+	switch strings.ToLower(strings.TrimSpace(name)) {
+	case "", "input":
+		return SortInput, nil
+	case "codepoint", "cp":
+		return SortCodePoint, nil
+	case "bytes", "utf8":
+		return SortBytes, nil
+	}
+	return "", fmt.Errorf("unknown sort order %q (use input, codepoint or bytes)", name)
+}
+
+// Filter reports whether a result row should be shown.
+type Filter func(Result) bool
+
+// ParseFilter parses comma-separated conditions that must all hold:
+//
+//	ascii, non-ascii   the rune is (not) in U+0000..U+007F
+//	hidden             the rune is invisible, a control or a bidi control
+//	category=Mn        General_Category; one letter such as L matches Lu, Ll, ...
+//	script=Latin       Script, ignoring case
+//	block=Emoticons    Block, ignoring case, spaces, hyphens and underscores
+//	bytes>2            UTF-8 length compared with =, !=, <, <=, > or >=
+//	width=2            display width, compared likewise
+//	cp>=0x80           code point, compared likewise (U+0080 works too)
+//
+// category, script and block also take != to exclude. An empty expression
+// keeps every row and returns a nil Filter.
+func ParseFilter(expr string) (Filter, error) {
+	var conds []Filter
+	for part := range strings.SplitSeq(expr, ",") {
+		part = strings.TrimSpace(part)
+		if part == "" {
+			continue
+		}
+		cond, err := parseCondition(part)
+		if err != nil {
+			return nil, err
+		}
+		conds = append(conds, cond)
+	}
+	if len(conds) == 0 {
+		return nil, nil
+	}
+	return func(res Result) bool {
+		for _, cond := range conds {
+			if !cond(res) {
+				return false
+			}
+		}
+		return true
+	}, nil
+}
+
+// filterOperators are tried in order, so two-character operators win.
+var filterOperators = []string{"!=", "<=", ">=", "=", "<", ">"}
+
+func parseCondition(cond string) (Filter, error) {
+	switch strings.ToLower(cond) {
+	case "ascii":
+		return func(res Result) bool { return res.CodePointDec < 0x80 }, nil
+	case "non-ascii", "nonascii":
+		return func(res Result) bool { return res.CodePointDec >= 0x80 }, nil
+	case "hidden":
+		return func(res Result) bool { return res.Hazard != nil }, nil
+	}
+
+	key, op, value := "", "", ""
+	for _, candidate := range filterOperators {
+		if i := strings.Index(cond, candidate); i > 0 {
+			key, op, value = strings.ToLower(strings.TrimSpace(cond[:i])), candidate, strings.TrimSpace(cond[i+len(candidate):])
+			break
+		}
+	}
+	if op == "" || value == "" {
+		return nil, fmt.Errorf("invalid filter %q (use ascii, non-ascii, hidden, category=Mn, script=Latin, block=..., or compare bytes, width or cp, e.g. bytes>2)", cond)
+	}
+
+	switch key {
+	case "category", "script", "block":
+		if op != "=" && op != "!=" {
+			return nil, fmt.Errorf("filter %q: %s only supports = and !=", cond, key)
+		}
+		match := textMatcher(key, value)
+		if op == "!=" {
+			return func(res Result) bool { return !match(res) }, nil
+		}
+		return match, nil
+	case "bytes", "width", "cp":
+		want, err := parseFilterNumber(value)
+		if err != nil {
+			return nil, fmt.Errorf("filter %q: %w", cond, err)
+		}
+		measure := map[string]func(Result) int{
+			"bytes": func(res Result) int { return len(res.UTF8BytesHex) },
+			"width": func(res Result) int { return RuneWidth(rune(res.CodePointDec)) },
+			"cp":    func(res Result) int { return res.CodePointDec },
+		}[key]
+		return func(res Result) bool { return compareInts(measure(res), op, want) }, nil
+	}
+	return nil, fmt.Errorf("unknown filter field %q in %q (use category, script, block, bytes, width or cp)", key, cond)
+}
+
+// textMatcher matches a category, script or block name loosely.
+func textMatcher(key, value string) Filter {
+	fold := func(s string) string {
+		return strings.ToLower(strings.NewReplacer(" ", "", "_", "", "-", "").Replace(s))
+	}
+	want := fold(value)
+	switch key {
+	case "category":
+		if len(want) == 1 {
+			return func(res Result) bool { return strings.HasPrefix(fold(res.Category), want) }
+		}
+		return func(res Result) bool { return fold(res.Category) == want }
+	case "script":
+		return func(res Result) bool { return fold(res.Script) == want }
+	}
+	return func(res Result) bool { return fold(res.Block) == want }
+}
+
+func parseFilterNumber(value string) (int, error) {
+	if rest, ok := strings.CutPrefix(strings.ToUpper(value), "U+"); ok {
+		value = "0x" + rest
+	}
+	n, err := strconv.ParseInt(value, 0, 64)
+	if err != nil {
+		return 0, fmt.Errorf("%q is not a number", value)
+	}
+	return int(n), nil
+}
+
+func compareInts(got int, op string, want int) bool {
+	switch op {
+	case "=":
+		return got == want
+	case "!=":
+		return got != want
+	case "<":
+		return got < want
+	case "<=":
+		return got <= want
+	case ">":
+		return got > want
+	}
+	return got >= want
+}
+
+// View selects and orders the rows of a result table without copying the
+// results, so data kept parallel to them stays valid.
+type View struct {
+	Rows   []int // indices into the results, in display order
+	Counts []int // how often each row's character occurs; nil unless collapsed
+}
+
+// NewView keeps the results that pass filter (nil keeps all), collapses
+// repeated characters into their first occurrence when unique is set, and
+// sorts the rows.
+func NewView(results []Result, filter Filter, order SortOrder, unique bool) View {
+	type row struct{ index, count int }
+	var rows []row
+	first := map[int]int{} // code point -> position in rows
+	for i, res := range results {
+		if filter != nil && !filter(res) {
+			continue
+		}
+		if unique {
+			if at, ok := first[res.CodePointDec]; ok {
+				rows[at].count++
+				continue
+			}
+			first[res.CodePointDec] = len(rows)
+		}
+		rows = append(rows, row{i, 1})
+	}
+
+	switch order {
+	case SortCodePoint:
+		slices.SortStableFunc(rows, func(a, b row) int {
+			return results[a.index].CodePointDec - results[b.index].CodePointDec
+		})
+	case SortBytes:
+		slices.SortStableFunc(rows, func(a, b row) int {
+			return len(results[a.index].UTF8BytesHex) - len(results[b.index].UTF8BytesHex)
+		})
+	}
+
+	view := View{Rows: make([]int, len(rows))}
+	if unique {
+		view.Counts = make([]int, len(rows))
+	}
+	for i, r := range rows {
+		view.Rows[i] = r.index
+		if unique {
+			view.Counts[i] = r.count
+		}
+	}
+	return view
+}
@@ -4,18 +4,39 @@ package visualiser
 import (
 	"errors"
 	"fmt"
+	"unicode/utf16"
+
+	"go_tutorials/internal/ucd"
 )
 
 // Result captures descriptive data for a single rune in a string.
 type Result struct {
-	Character         string   // original rune formatted via %q for readability
-	CodePointHex      string   // Unicode code point in U+XXXX form
-	CodePointDec      int      // Unicode code point in decimal
-	UTF8BytesHex      []string // each UTF-8 byte formatted as 0xHH
-	UTF8BytesDec      []string // decimal byte values
-	UTF8BytesBinary   []string // binary byte values
-	HTMLEntityDecimal string   // e.g., &#65;
-	HTMLEntityHex     string   // e.g., &#x0041;
+	Character         string              // original rune formatted via %q for readability
+	CodePointHex      string              // Unicode code point in U+XXXX form
+	CodePointDec      int                 // Unicode code point in decimal
+	UTF8BytesHex      []string            // each UTF-8 byte formatted as 0xHH
+	UTF8BytesDec      []string            // decimal byte values
+	UTF8BytesBinary   []string            // binary byte values
+	HTMLEntityDecimal string              // e.g., &#65;
+	HTMLEntityHex     string              // e.g., &#x0041;
+	Name              string              // Unicode character name, e.g., LATIN CAPITAL LETTER A
+	Category          string              // two-letter General_Category, e.g., Lu or Mn
+	Script            string              // Script property, e.g., Latin
+	Block             string              // Unicode block, e.g., Basic Latin
+	UTF16Units        []string            // UTF-16 code units formatted as 0xHHHH
+	UTF16High         string              // high (lead) surrogate for runes above U+FFFF, else empty
+	UTF16Low          string              // low (trail) surrogate for runes above U+FFFF, else empty
+	UTF16BEBytes      []string            // UTF-16 big-endian bytes formatted as 0xHH
+	UTF16LEBytes      []string            // UTF-16 little-endian bytes formatted as 0xHH
+	UTF32BEBytes      []string            // UTF-32 big-endian bytes formatted as 0xHH
+	UTF32LEBytes      []string            // UTF-32 little-endian bytes formatted as 0xHH
+	Hazard            *Hazard             // invisible, control or bidi hazard; nil for ordinary runes
+	Escapes           map[Language]string // the rune as an escape sequence in every supported syntax
+	ByteOffset        int                 // byte offset of the rune from the start of the input
+	RuneIndex         int                 // 0-based index of the rune among the decoded runes
+	Line              int                 // 1-based line number; lines end at U+000A
+	ColumnUTF8        int                 // 1-based column within the line, counted in UTF-8 bytes
+	ColumnUTF16       int                 // 1-based column within the line, counted in UTF-16 code units
 }
 
 // AnalyseString walks each rune in the input and returns byte-level metadata.
@@ -25,28 +46,56 @@ func AnalyseString(input string) ([]Result, error) {
 	}
 
 	results := make([]Result, 0, len(input))
+	pos := newPosition()
 	for _, r := range input {
-		b := []byte(string(r))
-		hexParts := make([]string, len(b))
-		decParts := make([]string, len(b))
-		binParts := make([]string, len(b))
-		for i, by := range b {
-			hexParts[i] = fmt.Sprintf("0x%02X", by)
-			decParts[i] = fmt.Sprintf("%d", by)
-			binParts[i] = fmt.Sprintf("%08b", by)
-		}
-
-		res := Result{
-			Character:         fmt.Sprintf("%q", r),
-			CodePointHex:      fmt.Sprintf("U+%04X", r),
-			CodePointDec:      int(r),
-			UTF8BytesHex:      hexParts,
-			UTF8BytesDec:      decParts,
-			UTF8BytesBinary:   binParts,
-			HTMLEntityDecimal: fmt.Sprintf("&#%d;", r),
-			HTMLEntityHex:     fmt.Sprintf("&#x%04X;", r),
-		}
+		res := analyseRune(r)
+		pos.place(&res)
 		results = append(results, res)
 	}
 	return results, nil
 }
+
+// analyseRune builds the Result describing a single rune.
+func analyseRune(r rune) Result {
+	b := []byte(string(r))
+	decParts := make([]string, len(b))
+	binParts := make([]string, len(b))
+	for i, by := range b {
+		decParts[i] = fmt.Sprintf("%d", by)
+		binParts[i] = fmt.Sprintf("%08b", by)
+	}
+
+	units := utf16.Encode([]rune{r})
+	unitParts := make([]string, len(units))
+	for i, u := range units {
+		unitParts[i] = fmt.Sprintf("0x%04X", u)
+	}
+	var high, low string
+	if len(units) == 2 {
+		high, low = unitParts[0], unitParts[1]
+	}
+
+	return Result{
+		Character:         fmt.Sprintf("%q", r),
+		CodePointHex:      fmt.Sprintf("U+%04X", r),
+		CodePointDec:      int(r),
+		UTF8BytesHex:      hexBytes(b),
+		UTF8BytesDec:      decParts,
+		UTF8BytesBinary:   binParts,
+		HTMLEntityDecimal: fmt.Sprintf("&#%d;", r),
+		HTMLEntityHex:     fmt.Sprintf("&#x%04X;", r),
+		Name:              ucd.Name(r),
+		Category:          ucd.Category(r),
+		Script:            ucd.Script(r),
+		Block:             ucd.Block(r),
+		UTF16Units:        unitParts,
+		UTF16High:         high,
+		UTF16Low:          low,
+		UTF16BEBytes:      hexBytes(EncodeRune(r, UTF16BE)),
+		UTF16LEBytes:      hexBytes(EncodeRune(r, UTF16LE)),
+		UTF32BEBytes:      hexBytes(EncodeRune(r, UTF32BE)),
+		UTF32LEBytes:      hexBytes(EncodeRune(r, UTF32LE)),
+		Hazard:            ClassifyHazard(r),
+		Escapes:           escapesFor(r),
+	}
+}
@@ -1,8 +1,18 @@
 package visualiser
 
 import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"io"
+	"net/url"
 	"reflect"
+	"slices"
+	"strconv"
+	"strings"
 	"testing"
+	"testing/iotest"
+	"unicode/utf8"
 )
 
 func TestAnalyseStringASCII(t *testing.T) {
@@ -41,6 +51,12 @@ func TestAnalyseStringASCII(t *testing.T) {
 	if !reflect.DeepEqual(res.UTF8BytesBinary, expectBin) {
 		t.Errorf("bin bytes mismatch: %v", res.UTF8BytesBinary)
 	}
+	if res.Name != "LATIN CAPITAL LETTER A" {
+		t.Errorf("unexpected name: %s", res.Name)
+	}
+	if res.Category != "Lu" || res.Script != "Latin" || res.Block != "Basic Latin" {
+		t.Errorf("unexpected properties: %s %s %s", res.Category, res.Script, res.Block)
+	}
 }
 
 func TestAnalyseStringMultiByte(t *testing.T) {
@@ -99,3 +115,614 @@ func TestAnalyseStringEmpty(t *testing.T) {
 		t.Fatalf("expected error for empty input")
 	}
 }
+
+func TestGraphemes(t *testing.T) {
+	cases := []struct {
+		name  string
+		input string
+		want  []string
+	}{
+		{"combining mark", "e\u0301x", []string{"e\u0301", "x"}},
+		{"flags", "🇮🇳🇫🇷", []string{"🇮🇳", "🇫🇷"}},
+		{"zwj family", "👨‍👩‍👧!", []string{"👨‍👩‍👧", "!"}},
+		{"skin tone", "👩🏽‍💻", []string{"👩🏽‍💻"}},
+		{"crlf", "a\r\nb", []string{"a", "\r\n", "b"}},
+		{"hangul jamo", "각", []string{"각"}},
+	}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			if got := Graphemes(tc.input); !reflect.DeepEqual(got, tc.want) {
+				t.Fatalf("want %q, got %q", tc.want, got)
+			}
+		})
+	}
+}
+
+func TestAnalyseGraphemes(t *testing.T) {
+	clusters, err := AnalyseGraphemes("e\u0301A")
+	if err != nil {
+		t.Fatalf("AnalyseGraphemes returned error: %v", err)
+	}
+	if len(clusters) != 2 {
+		t.Fatalf("expected 2 clusters, got %d", len(clusters))
+	}
+	if clusters[0].Text != "\"e\u0301\"" {
+		t.Errorf("unexpected cluster text: %s", clusters[0].Text)
+	}
+	if len(clusters[0].Runes) != 2 || clusters[0].Runes[1].CodePointHex != "U+0301" {
+		t.Errorf("unexpected nested runes: %+v", clusters[0].Runes)
+	}
+	if _, err := AnalyseGraphemes(""); err == nil {
+		t.Fatalf("expected error for empty input")
+	}
+}
+
+func TestAnalyseStringUTF16AndUTF32(t *testing.T) {
+	results, err := AnalyseString("A😊")
+	if err != nil {
+		t.Fatalf("AnalyseString returned error: %v", err)
+	}
+	ascii, emoji := results[0], results[1]
+	if !reflect.DeepEqual(ascii.UTF16Units, []string{"0x0041"}) {
+		t.Errorf("ascii UTF-16 units mismatch: %v", ascii.UTF16Units)
+	}
+	if ascii.UTF16High != "" || ascii.UTF16Low != "" {
+		t.Errorf("expected no surrogates for BMP rune, got %q %q", ascii.UTF16High, ascii.UTF16Low)
+	}
+	if !reflect.DeepEqual(emoji.UTF16Units, []string{"0xD83D", "0xDE0A"}) {
+		t.Errorf("emoji UTF-16 units mismatch: %v", emoji.UTF16Units)
+	}
+	if emoji.UTF16High != "0xD83D" || emoji.UTF16Low != "0xDE0A" {
+		t.Errorf("unexpected surrogates: %q %q", emoji.UTF16High, emoji.UTF16Low)
+	}
+	checks := map[Encoding][]string{
+		UTF16BE: {"0xD8", "0x3D", "0xDE", "0x0A"},
+		UTF16LE: {"0x3D", "0xD8", "0x0A", "0xDE"},
+		UTF32BE: {"0x00", "0x01", "0xF6", "0x0A"},
+		UTF32LE: {"0x0A", "0xF6", "0x01", "0x00"},
+		UTF8:    {"0xF0", "0x9F", "0x98", "0x8A"},
+	}
+	for enc, want := range checks {
+		if got := emoji.BytesFor(enc); !reflect.DeepEqual(got, want) {
+			t.Errorf("%s bytes mismatch: want %v, got %v", enc, want, got)
+		}
+	}
+}
+
+func TestParseEncodings(t *testing.T) {
+	got, err := ParseEncodings([]string{"UTF-8", "utf16le", "utf_32be", "utf8", ""})
+	if err != nil {
+		t.Fatalf("ParseEncodings returned error: %v", err)
+	}
+	want := []Encoding{UTF8, UTF16LE, UTF32BE}
+	if !reflect.DeepEqual(got, want) {
+		t.Fatalf("want %v, got %v", want, got)
+	}
+	if _, err := ParseEncodings([]string{"latin1"}); err == nil {
+		t.Fatalf("expected error for unknown encoding")
+	}
+}
+
+func TestClassifyHazard(t *testing.T) {
+	cases := []struct {
+		r        rune
+		kind     string
+		severity Severity
+	}{
+		{0x202E, "bidi-override", SeverityDanger},
+		{0x2067, "bidi-isolate", SeverityDanger},
+		{0x200F, "bidi-mark", SeverityWarning},
+		{0x200B, "zero-width", SeverityWarning},
+		{0x200D, "zero-width-joiner", SeverityInfo},
+		{0x0007, "c0-control", SeverityWarning},
+		{0x0085, "c1-control", SeverityWarning},
+		{0xE0041, "tag", SeverityDanger},
+		{0xFE0F, "variation-selector", SeverityInfo},
+		{0xE0100, "variation-selector", SeverityInfo},
+		{0x00AD, "invisible", SeverityWarning},
+	}
+	for _, tc := range cases {
+		h := ClassifyHazard(tc.r)
+		if h == nil || h.Kind != tc.kind || h.Severity != tc.severity || h.Explanation == "" {
+			t.Errorf("ClassifyHazard(%U): want %s/%s, got %+v", tc.r, tc.kind, tc.severity, h)
+		}
+	}
+	for _, r := range []rune{'A', '\t', '\n', '\r', ' ', 'é', '😊'} {
+		if h := ClassifyHazard(r); h != nil {
+			t.Errorf("ClassifyHazard(%U): expected no hazard, got %+v", r, h)
+		}
+	}
+}
+
+func TestWarnings(t *testing.T) {
+	results, err := AnalyseString("a\u202eb\u200bc")
+	if err != nil {
+		t.Fatalf("AnalyseString returned error: %v", err)
+	}
+	warnings := Warnings(results)
+	if len(warnings) != 2 || warnings[0].Index != 1 || warnings[1].Index != 3 {
+		t.Fatalf("unexpected warnings: %+v", warnings)
+	}
+	want := "[danger] #2 U+202E RIGHT-TO-LEFT OVERRIDE: "
+	if got := warnings[0].String(); !strings.HasPrefix(got, want) {
+		t.Errorf("want prefix %q, got %q", want, got)
+	}
+}
+
+func TestAnalyseBytes(t *testing.T) {
+	cases := []struct {
+		name    string
+		input   []byte
+		runes   int
+		invalid []InvalidSequence
+	}{
+		{"valid", []byte("A😊"), 2, nil},
+		{"continuation", []byte{0x41, 0x80, 0x42}, 2, []InvalidSequence{
+			{Offset: 1, Index: 1, Bytes: []string{"0x80"}, Reason: ReasonUnexpectedContinuation},
+		}},
+		{"truncated", []byte{0xE2, 0x82, 0x41}, 1, []InvalidSequence{
+			{Offset: 0, Index: 0, Bytes: []string{"0xE2", "0x82"}, Reason: ReasonTruncated},
+		}},
+		{"truncated at end", []byte{0x41, 0xF0, 0x9F}, 1, []InvalidSequence{
+			{Offset: 1, Index: 1, Bytes: []string{"0xF0", "0x9F"}, Reason: ReasonTruncated},
+		}},
+		{"overlong", []byte{0xC0, 0xAF}, 0, []InvalidSequence{
+			{Offset: 0, Index: 0, Bytes: []string{"0xC0", "0xAF"}, Reason: ReasonOverlong},
+		}},
+		{"overlong three bytes", []byte{0xE0, 0x80, 0xAF}, 0, []InvalidSequence{
+			{Offset: 0, Index: 0, Bytes: []string{"0xE0", "0x80", "0xAF"}, Reason: ReasonOverlong},
+		}},
+		{"surrogate", []byte{0xED, 0xA0, 0x80}, 0, []InvalidSequence{
+			{Offset: 0, Index: 0, Bytes: []string{"0xED", "0xA0", "0x80"}, Reason: ReasonSurrogate},
+		}},
+		{"out of range", []byte{0xF4, 0x90, 0x80, 0x80, 0xFF}, 0, []InvalidSequence{
+			{Offset: 0, Index: 0, Bytes: []string{"0xF4", "0x90", "0x80", "0x80"}, Reason: ReasonOutOfRange},
+			{Offset: 4, Index: 0, Bytes: []string{"0xFF"}, Reason: ReasonOutOfRange},
+		}},
+	}
+	for _, tc := range cases {
+		results, invalid, err := AnalyseBytes(tc.input)
+		if err != nil {
+			t.Fatalf("%s: AnalyseBytes returned error: %v", tc.name, err)
+		}
+		if len(results) != tc.runes {
+			t.Errorf("%s: expected %d runes, got %d", tc.name, tc.runes, len(results))
+		}
+		if !reflect.DeepEqual(invalid, tc.invalid) {
+			t.Errorf("%s: want %+v, got %+v", tc.name, tc.invalid, invalid)
+		}
+	}
+	if _, _, err := AnalyseBytes(nil); err == nil {
+		t.Fatalf("expected error for empty input")
+	}
+}
+
+func TestStream(t *testing.T) {
+	input := []byte("A😊\xC0\xAFé")
+	want, wantInvalid, err := AnalyseBytes(input)
+	if err != nil {
+		t.Fatalf("AnalyseBytes returned error: %v", err)
+	}
+	// OneByteReader splits every multi-byte rune across reads.
+	var (
+		got     []Result
+		invalid []InvalidSequence
+		offsets []int
+	)
+	for item, err := range Stream(iotest.OneByteReader(bytes.NewReader(input))) {
+		if err != nil {
+			t.Fatalf("Stream returned error: %v", err)
+		}
+		offsets = append(offsets, item.Offset)
+		if item.Invalid != nil {
+			invalid = append(invalid, *item.Invalid)
+			continue
+		}
+		got = append(got, *item.Result)
+	}
+	if !reflect.DeepEqual(got, want) || !reflect.DeepEqual(invalid, wantInvalid) {
+		t.Fatalf("stream mismatch: want %+v %+v, got %+v %+v", want, wantInvalid, got, invalid)
+	}
+	if !reflect.DeepEqual(offsets, []int{0, 1, 5, 7}) {
+		t.Errorf("unexpected offsets: %v", offsets)
+	}
+}
+
+func TestStreamReadError(t *testing.T) {
+	boom := errors.New("boom")
+	count := 0
+	for _, err := range Stream(io.MultiReader(strings.NewReader("ab"), iotest.ErrReader(boom))) {
+		if err != nil {
+			if !errors.Is(err, boom) {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			return
+		}
+		count++
+	}
+	t.Fatalf("expected read error after %d items", count)
+}
+
+func TestDecode(t *testing.T) {
+	input := []byte("A\U0001F60A\xC0\xAF\u00e9")
+	var got []DecodedRune
+	for dr, err := range Decode(iotest.OneByteReader(bytes.NewReader(input))) {
+		if err != nil {
+			t.Fatalf("Decode returned error: %v", err)
+		}
+		dr.Bytes = slices.Clone(dr.Bytes)
+		got = append(got, dr)
+	}
+	want := []DecodedRune{
+		{0, 'A', []byte("A"), ""},
+		{1, 0x1F60A, []byte("\U0001F60A"), ""},
+		{5, utf8.RuneError, []byte{0xC0, 0xAF}, ReasonOverlong},
+		{7, 0xE9, []byte("\u00e9"), ""},
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Fatalf("want %+v, got %+v", want, got)
+	}
+}
+
+func TestDecodeAllocsDoNotGrowWithInput(t *testing.T) {
+	allocs := func(input string) float64 {
+		return testing.AllocsPerRun(10, func() {
+			for _, err := range Decode(strings.NewReader(input)) {
+				if err != nil {
+					t.Fatal(err)
+				}
+			}
+		})
+	}
+	small := allocs("h\u00e9llo\xFF")
+	large := allocs(strings.Repeat("h\u00e9llo \U0001F60A\xFF", 10000))
+	if large != small {
+		t.Errorf("decoding allocates per rune: %v allocations for a short input, %v for a long one", small, large)
+	}
+}
+
+func BenchmarkDecode(b *testing.B) {
+	input := strings.Repeat("h\u00e9llo \U0001F60A ", 1000)
+	b.SetBytes(int64(len(input)))
+	b.ReportAllocs()
+	for b.Loop() {
+		for range Decode(strings.NewReader(input)) {
+		}
+	}
+}
+
+func BenchmarkStream(b *testing.B) {
+	input := strings.Repeat("h\u00e9llo \U0001F60A ", 1000)
+	b.SetBytes(int64(len(input)))
+	b.ReportAllocs()
+	for b.Loop() {
+		for range Stream(strings.NewReader(input)) {
+		}
+	}
+}
+
+func TestResultPositions(t *testing.T) {
+	input := "a😊\r\nbé"
+	type pos struct{ offset, index, line, col8, col16 int }
+	want := []pos{
+		{0, 0, 1, 1, 1}, // a
+		{1, 1, 1, 2, 2}, // 😊
+		{5, 2, 1, 6, 4}, // \r
+		{6, 3, 1, 7, 5}, // \n
+		{7, 4, 2, 1, 1}, // b
+		{8, 5, 2, 2, 2}, // é
+	}
+	check := func(name string, results []Result) {
+		t.Helper()
+		if len(results) != len(want) {
+			t.Fatalf("%s: expected %d results, got %d", name, len(want), len(results))
+		}
+		for i, res := range results {
+			got := pos{res.ByteOffset, res.RuneIndex, res.Line, res.ColumnUTF8, res.ColumnUTF16}
+			if got != want[i] {
+				t.Errorf("%s: rune %d: want %+v, got %+v", name, i, want[i], got)
+			}
+		}
+	}
+
+	results, err := AnalyseString(input)
+	if err != nil {
+		t.Fatalf("AnalyseString returned error: %v", err)
+	}
+	check("AnalyseString", results)
+
+	clusters, err := AnalyseGraphemes(input)
+	if err != nil {
+		t.Fatalf("AnalyseGraphemes returned error: %v", err)
+	}
+	var flat []Result
+	for _, c := range clusters {
+		flat = append(flat, c.Runes...)
+	}
+	check("AnalyseGraphemes", flat)
+
+	var streamed []Result
+	for item, err := range Stream(strings.NewReader(input)) {
+		if err != nil {
+			t.Fatalf("Stream returned error: %v", err)
+		}
+		streamed = append(streamed, *item.Result)
+	}
+	check("Stream", streamed)
+
+	// A malformed sequence advances the byte offset and columns but not the
+	// rune index.
+	results, _, err = AnalyseBytes([]byte("a\xFFb"))
+	if err != nil {
+		t.Fatalf("AnalyseBytes returned error: %v", err)
+	}
+	if b := results[1]; b.ByteOffset != 2 || b.RuneIndex != 1 || b.ColumnUTF8 != 3 || b.ColumnUTF16 != 3 {
+		t.Errorf("unexpected position after invalid byte: %+v", b)
+	}
+}
+
+func TestEscapeRune(t *testing.T) {
+	cases := []struct {
+		r    rune
+		lang Language
+		want string
+	}{
+		{'A', LangGo, `\x41`},
+		{0xE9, LangGo, `\u00e9`},
+		{0x1F600, LangGo, `\U0001f600`},
+		{0x1F600, LangJSON, `\ud83d\ude00`},
+		{0x1F600, LangJava, `\ud83d\ude00`},
+		{'A', LangJava, `\u0041`},
+		{'\n', LangJava, `\n`},
+		{'"', LangJava, `\"`},
+		{'\\', LangJava, `\\`},
+		{0x01, LangJava, `\001`},
+		{0xE9, LangJS, `\u00e9`},
+		{0x1F600, LangJS, `\u{1f600}`},
+		{0xE9, LangPython, `\xe9`},
+		{0x3B1, LangPython, `\u03b1`},
+		{0x1F600, LangPython, `\U0001f600`},
+		{0xE9, LangC, `\xc3\xa9`},
+		{0xE9, LangRust, `\u{e9}`},
+		{0xE9, LangCSS, `\0000e9`},
+		{0x20AC, LangURL, "%E2%82%AC"},
+		{0x1F600, LangXML, "&#x1F600;"},
+	}
+	for _, tc := range cases {
+		if got := EscapeRune(tc.r, tc.lang); got != tc.want {
+			t.Errorf("EscapeRune(%U, %s) = %q, want %q", tc.r, tc.lang, got, tc.want)
+		}
+	}
+
+	results, err := AnalyseString("é")
+	if err != nil {
+		t.Fatalf("AnalyseString returned error: %v", err)
+	}
+	if len(results[0].Escapes) != len(AllLanguages) || results[0].Escapes[LangRust] != `\u{e9}` {
+		t.Fatalf("unexpected Escapes: %v", results[0].Escapes)
+	}
+}
+
+func TestEscapeString(t *testing.T) {
+	input := "café \"\U0001F600\"\n<a&b>"
+	cases := []struct {
+		lang Language
+		want string
+	}{
+		{LangJS, `"caf\u00e9 \"\u{1f600}\"\n<a&b>"`},
+		{LangPython, `"caf\xe9 \"\U0001f600\"\n<a&b>"`},
+		{LangC, `"caf\xc3\xa9 \"\xf0\x9f\x98\x80\"\n<a&b>"`},
+		{LangRust, `"caf\u{e9} \"\u{1f600}\"\n<a&b>"`},
+		{LangCSS, `"caf\0000e9 \"\01f600\"\00000a<a&b>"`},
+		{LangURL, "caf%C3%A9%20%22%F0%9F%98%80%22%0A%3Ca%26b%3E"},
+		{LangXML, "caf&#xE9; &quot;&#x1F600;&quot;&#xA;&lt;a&amp;b&gt;"},
+	}
+	for _, tc := range cases {
+		if got := EscapeString(input, tc.lang); got != tc.want {
+			t.Errorf("EscapeString(%s) = %s, want %s", tc.lang, got, tc.want)
+		}
+	}
+
+	// The literals must round-trip through the languages' own decoders.
+	goLiteral := EscapeString(input, LangGo)
+	if got, err := strconv.Unquote(goLiteral); err != nil || got != input {
+		t.Errorf("Go literal %s unquoted to %q, %v", goLiteral, got, err)
+	}
+	var fromJSON string
+	jsonLiteral := EscapeString(input, LangJSON)
+	if err := json.Unmarshal([]byte(jsonLiteral), &fromJSON); err != nil || fromJSON != input {
+		t.Errorf("JSON literal %s decoded to %q, %v", jsonLiteral, fromJSON, err)
+	}
+	if got, err := url.PathUnescape(EscapeString(input, LangURL)); err != nil || got != input {
+		t.Errorf("URL escape decoded to %q, %v", got, err)
+	}
+
+	// A hex digit after a \x escape starts a new C literal segment.
+	if got := EscapeString("éa", LangC); got != `"\xc3\xa9" "a"` {
+		t.Errorf("EscapeString(c) = %s, want split literal", got)
+	}
+	// Java must not see \u000a, which it would translate before lexing.
+	if got := EscapeString("a\x01", LangJava); got != `"a\001"` {
+		t.Errorf("EscapeString(java) = %s", got)
+	}
+}
+
+func TestParseLanguages(t *testing.T) {
+	got, err := ParseLanguages([]string{"Go", "javascript", "c++", "go", " "})
+	if err != nil {
+		t.Fatalf("ParseLanguages returned error: %v", err)
+	}
+	want := []Language{LangGo, LangJS, LangC}
+	if !reflect.DeepEqual(got, want) {
+		t.Fatalf("want %v, got %v", want, got)
+	}
+	if _, err := ParseLanguages([]string{"cobol"}); err == nil {
+		t.Fatalf("expected error for unknown escape syntax")
+	}
+}
+
+func TestEncodeText(t *testing.T) {
+	cases := []struct {
+		enc  TextEncoding
+		in   string
+		want string
+	}{
+		{TextBase64, "café", "Y2Fmw6k="},
+		{TextBase64URL, "\xfb\xff", "-_8"},
+		{TextBase32, "café", "MNQWNQ5J"},
+		{TextASCII85, "café", "@prueW;"},
+		{TextQuotedPrintable, "a=b café ", "a=3Db caf=C3=A9=20"},
+	}
+	for _, tc := range cases {
+		if got := EncodeText(tc.in, tc.enc); got != tc.want {
+			t.Errorf("EncodeText(%q, %s) = %q, want %q", tc.in, tc.enc, got, tc.want)
+		}
+	}
+}
+
+func TestRuneWidth(t *testing.T) {
+	cases := map[rune]int{
+		'A': 1, 0x00E9: 1, 0x4E00: 2, 0xFF21: 2, 0xFF76: 1, 0x1F600: 2,
+		0x0301: 0, 0x200B: 0, 0x200D: 0, 0xFE0F: 0, 0x1160: 0, 0x11A8: 0,
+		'\n': 0, 0x007F: 0, 0x0085: 0, 0x00AD: 1,
+	}
+	for r, want := range cases {
+		if got := RuneWidth(r); got != want {
+			t.Errorf("RuneWidth(%U): want %d, got %d", r, want, got)
+		}
+	}
+}
+
+func TestStringWidth(t *testing.T) {
+	cases := map[string]int{
+		"":                   0,
+		"Go":                 2,
+		"日本語":                6,
+		"e\u0301":            1,
+		"한":                  2,
+		"\u1100\u1161\u11A8": 2,
+		"😀":                  2,
+		"👍🏽":                 2,
+		"👩\u200d💻":           2,
+		"🇮🇳":                 2,
+		"❤\ufe0f":            2,
+		"'🏽'":                4,
+		"a\u200bb":           2,
+	}
+	for s, want := range cases {
+		if got := StringWidth(s); got != want {
+			t.Errorf("StringWidth(%q): want %d, got %d", s, want, got)
+		}
+	}
+}
+
+func TestTruncateWidth(t *testing.T) {
+	cases := []struct {
+		in    string
+		width int
+		want  string
+	}{
+		{"LATIN SMALL LETTER A", 8, "LATIN S…"},
+		{"short", 8, "short"},
+		{"日本語", 5, "日本…"},
+		{"日本語", 4, "日…"},
+		{"e\u0301e\u0301e\u0301", 2, "e\u0301…"},
+		{"abc", 0, ""},
+	}
+	for _, tc := range cases {
+		got := TruncateWidth(tc.in, tc.width)
+		if got != tc.want {
+			t.Errorf("TruncateWidth(%q, %d): want %q, got %q", tc.in, tc.width, tc.want, got)
+		}
+		if StringWidth(got) > tc.width {
+			t.Errorf("TruncateWidth(%q, %d) is %d cells wide", tc.in, tc.width, StringWidth(got))
+		}
+	}
+}
+
+func TestParseSortOrder(t *testing.T) {
+	cases := map[string]SortOrder{"": SortInput, "Input": SortInput, "cp": SortCodePoint, "codepoint": SortCodePoint, "bytes": SortBytes}
+	for name, want := range cases {
+		got, err := ParseSortOrder(name)
+		if err != nil || got != want {
+			t.Errorf("ParseSortOrder(%q): want %q, got %q (%v)", name, want, got, err)
+		}
+	}
+	if _, err := ParseSortOrder("name"); err == nil {
+		t.Fatal("expected an unknown sort order to fail")
+	}
+}
+
+func TestParseFilter(t *testing.T) {
+	results, err := AnalyseString("A\u00e9\u0301日😀\u200b")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	cases := map[string]string{
+		"ascii":                    "A",
+		"non-ascii":                "\u00e9\u0301日😀\u200b",
+		"category=Mn":              "\u0301",
+		"category=L":               "A\u00e9日",
+		"category!=L":              "\u0301😀\u200b",
+		"script=han":               "日",
+		"block=Latin-1 Supplement": "\u00e9",
+		"bytes>2":                  "日😀\u200b",
+		"bytes=2, category=Ll":     "\u00e9",
+		"width=2":                  "日😀",
+		"cp>=U+1F000":              "😀",
+		"cp<0x80":                  "A",
+		"hidden":                   "\u200b",
+	}
+	for expr, want := range cases {
+		filter, err := ParseFilter(expr)
+		if err != nil {
+			t.Fatalf("ParseFilter(%q): %v", expr, err)
+		}
+		var got strings.Builder
+		for _, res := range results {
+			if filter(res) {
+				got.WriteRune(rune(res.CodePointDec))
+			}
+		}
+		if got.String() != want {
+			t.Errorf("ParseFilter(%q): want %q, got %q", expr, want, got.String())
+		}
+	}
+
+	if filter, err := ParseFilter(" , "); err != nil || filter != nil {
+		t.Fatalf("expected an empty expression to keep every row, got %v", err)
+	}
+	for _, expr := range []string{"emoji", "bytes>two", "category>L", "size=2", "bytes="} {
+		if _, err := ParseFilter(expr); err == nil {
+			t.Errorf("expected ParseFilter(%q) to fail", expr)
+		}
+	}
+}
+
+func TestNewView(t *testing.T) {
+	results, err := AnalyseString("bé日aéb")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	view := NewView(results, nil, SortInput, false)
+	if !slices.Equal(view.Rows, []int{0, 1, 2, 3, 4, 5}) || view.Counts != nil {
+		t.Fatalf("expected every row in input order, got %+v", view)
+	}
+	view = NewView(results, nil, SortCodePoint, true)
+	if !slices.Equal(view.Rows, []int{3, 0, 1, 2}) || !slices.Equal(view.Counts, []int{1, 2, 2, 1}) {
+		t.Fatalf("expected unique rows by code point, got %+v", view)
+	}
+	view = NewView(results, nil, SortBytes, false)
+	if !slices.Equal(view.Rows, []int{0, 3, 5, 1, 4, 2}) {
+		t.Fatalf("expected a stable sort by UTF-8 length, got %+v", view)
+	}
+	filter, _ := ParseFilter("non-ascii")
+	view = NewView(results, filter, SortInput, true)
+	if !slices.Equal(view.Rows, []int{1, 2}) || !slices.Equal(view.Counts, []int{2, 1}) {
+		t.Fatalf("expected filtered unique rows, got %+v", view)
+	}
+	filter, _ = ParseFilter("cp>0x10000")
+	if view := NewView(results, filter, SortInput, false); view.Rows == nil || len(view.Rows) != 0 {
+		t.Fatalf("expected an empty, non-nil view, got %+v", view)
+	}
+}
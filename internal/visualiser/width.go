@@ -0,0 +1,100 @@
+package visualiser
+
+import (
+	"strings"
+	"unicode/utf8"
+
+	"go_tutorials/internal/ucd"
+)
+
+// RuneWidth returns how many terminal cells r takes on its own: 2 for East
+// Asian wide and fullwidth runes, 0 for controls, combining marks, format
+// characters such as U+200B and conjoining Hangul vowels and finals, and 1
+// otherwise. Ambiguous-width runes count as narrow, as they do outside CJK
+// locales.
+func RuneWidth(r rune) int {
+	switch {
+	case r < 0x20 || r >= 0x7F && r < 0xA0:
+		return 0
+	case r < 0x7F:
+		return 1
+	case r == 0xAD: // soft hyphen: Cf, but terminals draw a hyphen
+		return 1
+	}
+	switch ucd.Category(r) {
+	case "Mn", "Me", "Cf":
+		return 0
+	}
+	switch ucd.GraphemeBreakOf(r) {
+	case ucd.GraphemeV, ucd.GraphemeT:
+		return 0
+	}
+	switch ucd.EastAsianWidthOf(r) {
+	case ucd.WidthWide, ucd.WidthFullwidth:
+		return 2
+	}
+	return 1
+}
+
+// StringWidth returns how many terminal cells s takes, summing its grapheme
+// clusters. An emoji sequence (ZWJ, skin tone or U+FE0F presentation) is as
+// wide as its base emoji; other clusters add up their runes, so combining
+// marks take no room of their own.
+func StringWidth(s string) int {
+	if isPlainASCII(s) {
+		return len(s)
+	}
+	width := 0
+	for _, cluster := range Graphemes(s) {
+		width += clusterWidth(cluster)
+	}
+	return width
+}
+
+func clusterWidth(cluster string) int {
+	first, _ := utf8.DecodeRuneInString(cluster)
+	switch {
+	case strings.ContainsRune(cluster, 0xFE0F):
+		return 2
+	case ucd.IsExtendedPictographic(first):
+		return RuneWidth(first)
+	}
+	width := 0
+	for _, r := range cluster {
+		width += RuneWidth(r)
+	}
+	return width
+}
+
+// TruncateWidth shortens s to at most width cells, ending it with "…" when
+// anything was cut. Grapheme clusters are never split.
+func TruncateWidth(s string, width int) string {
+	if StringWidth(s) <= width {
+		return s
+	}
+	if width <= 0 {
+		return ""
+	}
+	var sb strings.Builder
+	used := 0
+	for _, cluster := range Graphemes(s) {
+		w := clusterWidth(cluster)
+		if used+w > width-1 {
+			break
+		}
+		sb.WriteString(cluster)
+		used += w
+	}
+	return sb.String() + "…"
+}
+
+// isPlainASCII reports whether s holds only printable ASCII, one cell per
+// byte.
+func isPlainASCII(s string) bool {
+	for i := 0; i < len(s); i++ {
+		if s[i] < 0x20 || s[i] >= 0x7F {
+			return false
+		}
+	}
+	return true
+}
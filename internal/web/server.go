@@ -3,15 +3,23 @@ package web
 import (
 	"bytes"
 	"embed"
-	"encoding/csv"
 	"encoding/json"
 	"errors"
 	"fmt"
 	"html/template"
 	"net/http"
+	"strconv"
 	"strings"
+	"unicode/utf16"
+	"unicode/utf8"
 
+	"go_tutorials/internal/codepage"
+	"go_tutorials/internal/confusables"
+	"go_tutorials/internal/emoji"
+	"go_tutorials/internal/export"
+	"go_tutorials/internal/normalize"
 	"go_tutorials/internal/reverseinput"
+	"go_tutorials/internal/ucd"
 	"go_tutorials/internal/visualiser"
 )
 
@@ -37,15 +45,101 @@ func (s *Server) Routes(mux *http.ServeMux) {
 	mux.HandleFunc("/", s.handleHome)
 	mux.HandleFunc("/api/visualise", s.handleVisualise)
 	mux.HandleFunc("/api/download", s.handleDownload)
+	mux.HandleFunc("/api/lookup", s.handleLookup)
 }
 
 type visualiseRequest struct {
-	Input string `json:"input"`
-	Mode  string `json:"mode"`
+	Input     string   `json:"input"`
+	Mode      string   `json:"mode"`
+	Graphemes bool     `json:"graphemes"`
+	Encodings []string `json:"encodings"`
+	CodePage  string   `json:"codepage"`
+	Normalize bool     `json:"normalize"`
+	// Confusables asks for UTS #39 homoglyph detection; CompareWith, when
+	// set, also checks the input for visual equivalence with that string.
+	Confusables bool   `json:"confusables"`
+	CompareWith string `json:"compareWith"`
+	// Sort, Unique and Filter arrange the result rows as the see command's
+	// --sort, --unique and --filter do; the response's view lists them.
+	Sort   string `json:"sort"`
+	Unique bool   `json:"unique"`
+	Filter string `json:"filter"`
+	// MaxCodePoints caps how many runes code point ranges may expand to.
+	// Zero means reverseinput.DefaultMaxCodePoints; larger values are
+	// clamped to maxCodePointsLimit.
+	MaxCodePoints int `json:"maxCodePoints"`
+}
+
+// maxCodePointsLimit is the highest code point cap a request may ask for.
+const maxCodePointsLimit = 65536
+
+// codePointCap returns the cap a request asked for, or the default when it
+// asked for none, never more than maxCodePointsLimit.
+func codePointCap(requested int) int {
+	if requested <= 0 {
+		return reverseinput.DefaultMaxCodePoints
+	}
+	return min(requested, maxCodePointsLimit)
 }
 
 type visualiseResponse struct {
-	Items []visualiser.Result `json:"items"`
+	Items         []visualiser.Result    `json:"items"`
+	Clusters      []visualiser.Cluster   `json:"clusters,omitempty"`
+	Encodings     []visualiser.Encoding  `json:"encodings,omitempty"`
+	CodePage      *codePageView          `json:"codepage,omitempty"`
+	Normalization []normalize.Comparison `json:"normalization,omitempty"`
+	Confusables   *confusables.Report    `json:"confusables,omitempty"`
+	// Invalid lists malformed UTF-8 sequences when byte input does not
+	// decode cleanly; Items then holds only the runes that did decode.
+	Invalid []visualiser.InvalidSequence `json:"invalid,omitempty"`
+	// Emoji breaks down every multi-rune emoji sequence in the input.
+	Emoji []emoji.Sequence `json:"emoji,omitempty"`
+	// Escaped holds the whole input as a string literal in each syntax.
+	Escaped []export.EscapedString `json:"escaped,omitempty"`
+	// Encoded holds the input's bytes in each binary-to-text encoding.
+	Encoded []export.EncodedString `json:"encoded,omitempty"`
+	// View is set when the request sorts, collapses or filters the rows.
+	View *rowView `json:"view,omitempty"`
+}
+
+// rowView lists the items to show, by index, in display order. Items and
+// the data parallel to them keep their input order. Counts holds how often
+// each row's character occurs when repeats are collapsed.
+type rowView struct {
+	Rows   []int `json:"rows"`
+	Counts []int `json:"counts,omitempty"`
+}
+
+// codePageView lists each rune's byte in a legacy code page, parallel to
+// the response items. Unmappable runes have an empty entry.
+type codePageView struct {
+	Name       string   `json:"name"`
+	Bytes      []string `json:"bytes"`
+	Unmappable int      `json:"unmappable"`
+}
+
+type indexData struct {
+	CodePages []string
+}
+
+// lookupCodePage resolves an optional code page name; empty means none.
+func lookupCodePage(name string) (*codepage.CodePage, error) {
+	if strings.TrimSpace(name) == "" {
+		return nil, nil
+	}
+	return codepage.Lookup(name)
+}
+
+func newCodePageView(cp *codepage.CodePage, text string) *codePageView {
+	mappings := cp.Encode(text)
+	view := &codePageView{Name: cp.Name, Bytes: make([]string, len(mappings))}
+	for i, m := range mappings {
+		if m.OK {
+			view.Bytes[i] = fmt.Sprintf("0x%02X", m.Byte)
+		}
+	}
+	view.Unmappable = codepage.Unmappable(mappings)
+	return view
 }
 
 func (s *Server) handleHome(w http.ResponseWriter, r *http.Request) {
@@ -54,7 +148,7 @@ func (s *Server) handleHome(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 	w.Header().Set("Content-Type", "text/html; charset=utf-8")
-	if err := s.indexTmpl.Execute(w, nil); err != nil {
+	if err := s.indexTmpl.Execute(w, indexData{CodePages: codepage.Names()}); err != nil {
 		http.Error(w, "failed to render template", http.StatusInternalServerError)
 		return
 	}
@@ -71,19 +165,173 @@ func (s *Server) handleVisualise(w http.ResponseWriter, r *http.Request) {
 		http.Error(w, "invalid JSON payload", http.StatusBadRequest)
 		return
 	}
-	resolved, err := s.resolveInput(req.Mode, req.Input)
+	cp, err := lookupCodePage(req.CodePage)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	resolved, err := s.resolveInput(req.Mode, req.Input, cp, codePointCap(req.MaxCodePoints))
+	if err != nil {
+		writeInputError(w, req.Input, err)
+		return
+	}
+	encodings, err := visualiser.ParseEncodings(req.Encodings)
 	if err != nil {
 		http.Error(w, err.Error(), http.StatusBadRequest)
 		return
 	}
+	order, err := visualiser.ParseSortOrder(req.Sort)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	filter, err := visualiser.ParseFilter(req.Filter)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	reordered := filter != nil || order != visualiser.SortInput || req.Unique
+	if reordered && req.Graphemes {
+		http.Error(w, "grapheme grouping cannot be combined with sorting, collapsing or filtering rows", http.StatusBadRequest)
+		return
+	}
 
-	results, err := visualiser.AnalyseString(resolved)
+	var (
+		results []visualiser.Result
+		invalid []visualiser.InvalidSequence
+		raw     = resolved // the binary-to-text encodings keep every byte
+	)
+	if utf8.ValidString(resolved) {
+		results, err = visualiser.AnalyseString(resolved)
+	} else {
+		results, invalid, err = visualiser.AnalyseBytes([]byte(resolved))
+		// Everything below describes the runes that did decode.
+		resolved = strings.ToValidUTF8(resolved, "")
+	}
 	if err != nil {
 		http.Error(w, err.Error(), http.StatusBadRequest)
 		return
 	}
 
-	resp := visualiseResponse{Items: results}
+	resp := visualiseResponse{Items: results, Encodings: encodings, Invalid: invalid}
+	if reordered {
+		view := visualiser.NewView(results, filter, order, req.Unique)
+		resp.View = &rowView{Rows: view.Rows, Counts: view.Counts}
+	}
+	if cp != nil {
+		resp.CodePage = newCodePageView(cp, resolved)
+	}
+	if req.Normalize {
+		resp.Normalization = normalize.Compare(resolved)
+	}
+	resp.Emoji = emoji.Analyse(resolved, false)
+	resp.Escaped = export.EscapeAll(resolved, visualiser.AllLanguages)
+	resp.Encoded = export.EncodeAll(raw)
+	if req.Confusables || req.CompareWith != "" {
+		report := confusables.NewReport(resolved, req.CompareWith)
+		resp.Confusables = &report
+	}
+	if req.Graphemes && resolved != "" {
+		clusters, err := visualiser.AnalyseGraphemes(resolved)
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		resp.Clusters = clusters
+	}
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(resp)
+}
+
+// parseErrorResponse is the body of a 400 caused by one bad token of a
+// reverse input mode. Start and End are byte offsets into the input;
+// StartUTF16 and EndUTF16 are the same span in UTF-16 code units, which is
+// how the browser indexes the textarea.
+type parseErrorResponse struct {
+	Error      string `json:"error"`
+	Token      string `json:"token"`
+	Index      int    `json:"index"`
+	Start      int    `json:"start"`
+	End        int    `json:"end"`
+	StartUTF16 int    `json:"startUtf16"`
+	EndUTF16   int    `json:"endUtf16"`
+	Expected   string `json:"expected"`
+	Suggestion string `json:"suggestion,omitempty"`
+}
+
+// writeInputError reports a resolveInput failure: parse errors become a JSON
+// body that locates the bad token, anything else stays plain text.
+func writeInputError(w http.ResponseWriter, input string, err error) {
+	var perr *reverseinput.ParseError
+	if !errors.As(err, &perr) || perr.End > len(input) {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusBadRequest)
+	json.NewEncoder(w).Encode(parseErrorResponse{
+		Error:      perr.Err.Error(),
+		Token:      perr.Token,
+		Index:      perr.Index,
+		Start:      perr.Start,
+		End:        perr.End,
+		StartUTF16: utf16Len(input[:perr.Start]),
+		EndUTF16:   utf16Len(input[:perr.End]),
+		Expected:   perr.Expected,
+		Suggestion: perr.Suggestion,
+	})
+}
+
+// utf16Len counts the UTF-16 code units s would take.
+func utf16Len(s string) int {
+	n := 0
+	for _, r := range s {
+		n += utf16.RuneLen(r)
+	}
+	return n
+}
+
+type lookupResponse struct {
+	Matches []lookupMatch `json:"matches"`
+}
+
+type lookupMatch struct {
+	Character string `json:"character"`
+	CodePoint string `json:"codePoint"`
+	Name      string `json:"name"`
+}
+
+// maxLookupResults caps how many matches one name search returns.
+const maxLookupResults = 100
+
+func (s *Server) handleLookup(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+	query := r.URL.Query()
+	if strings.TrimSpace(query.Get("q")) == "" {
+		http.Error(w, "q is required", http.StatusBadRequest)
+		return
+	}
+	limit := 20
+	if raw := query.Get("limit"); raw != "" {
+		n, err := strconv.Atoi(raw)
+		if err != nil || n < 1 {
+			http.Error(w, "limit must be a positive integer", http.StatusBadRequest)
+			return
+		}
+		limit = min(n, maxLookupResults)
+	}
+
+	resp := lookupResponse{Matches: []lookupMatch{}}
+	for _, m := range ucd.Search(query.Get("q"), limit) {
+		resp.Matches = append(resp.Matches, lookupMatch{
+			Character: string(m.Rune),
+			CodePoint: fmt.Sprintf("U+%04X", m.Rune),
+			Name:      m.Name,
+		})
+	}
 	w.Header().Set("Content-Type", "application/json")
 	json.NewEncoder(w).Encode(resp)
 }
@@ -94,69 +342,75 @@ func (s *Server) handleDownload(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 	query := r.URL.Query()
-	format := strings.ToLower(query.Get("format"))
-	if format == "" {
-		format = "json"
+	format := export.JSON
+	if name := query.Get("format"); name != "" {
+		parsed, err := export.ParseFormat(name)
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		format = parsed
 	}
 	input := query.Get("input")
 	mode := query.Get("mode")
+	encodings := visualiser.AllEncodings
+	if list := query.Get("encodings"); list != "" {
+		parsed, err := visualiser.ParseEncodings(strings.Split(list, ","))
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		encodings = parsed
+	}
+	languages := visualiser.AllLanguages
+	if list := query.Get("escapes"); list != "" {
+		parsed, err := visualiser.ParseLanguages(strings.Split(list, ","))
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		languages = parsed
+	}
 
-	resolved, err := s.resolveInput(mode, input)
+	cp, err := lookupCodePage(query.Get("codepage"))
 	if err != nil {
 		http.Error(w, err.Error(), http.StatusBadRequest)
 		return
 	}
+	requested := 0
+	if value := query.Get("maxCodePoints"); value != "" {
+		requested, err = strconv.Atoi(value)
+		if err != nil {
+			http.Error(w, fmt.Sprintf("maxCodePoints %q is not a number", value), http.StatusBadRequest)
+			return
+		}
+	}
+	resolved, err := s.resolveInput(mode, input, cp, codePointCap(requested))
+	if err != nil {
+		writeInputError(w, input, err)
+		return
+	}
 
-	results, err := visualiser.AnalyseString(resolved)
+	report, err := export.Analyse(resolved, languages)
 	if err != nil {
 		http.Error(w, err.Error(), http.StatusBadRequest)
 		return
 	}
 
-	switch format {
-	case "json":
-		w.Header().Set("Content-Type", "application/json")
-		w.Header().Set("Content-Disposition", "attachment; filename=\"visualiser.json\"")
-		json.NewEncoder(w).Encode(visualiseResponse{Items: results})
-	case "csv":
-		var buf bytes.Buffer
-		writer := csv.NewWriter(&buf)
-		writer.Write([]string{
-			"Character",
-			"CodePointHex",
-			"CodePointDec",
-			"UTF8BytesHex",
-			"UTF8BytesDec",
-			"UTF8BytesBinary",
-			"HTMLEntityDecimal",
-			"HTMLEntityHex",
-		})
-		for _, item := range results {
-			writer.Write([]string{
-				item.Character,
-				item.CodePointHex,
-				fmt.Sprintf("%d", item.CodePointDec),
-				strings.Join(item.UTF8BytesHex, " "),
-				strings.Join(item.UTF8BytesDec, " "),
-				strings.Join(item.UTF8BytesBinary, " "),
-				item.HTMLEntityDecimal,
-				item.HTMLEntityHex,
-			})
-		}
-		writer.Flush()
-		if err := writer.Error(); err != nil {
-			http.Error(w, "failed to generate CSV", http.StatusInternalServerError)
-			return
-		}
-		w.Header().Set("Content-Type", "text/csv")
-		w.Header().Set("Content-Disposition", "attachment; filename=\"visualiser.csv\"")
-		w.Write(buf.Bytes())
-	default:
-		http.Error(w, "unsupported format", http.StatusBadRequest)
+	var buf bytes.Buffer
+	if err := export.Write(&buf, format, report, export.Options{Encodings: encodings, Languages: languages}); err != nil {
+		http.Error(w, "failed to generate "+string(format), http.StatusInternalServerError)
+		return
 	}
+	w.Header().Set("Content-Type", format.ContentType())
+	w.Header().Set("Content-Disposition", fmt.Sprintf("attachment; filename=\"visualiser.%s\"", format.Extension()))
+	w.Write(buf.Bytes())
 }
 
-func (s *Server) resolveInput(mode, input string) (string, error) {
+// resolveInput turns the request input into text according to mode. The
+// codepage mode decodes byte tokens with cp, which must then be set, and
+// code point ranges expand to at most maxCodePoints runes.
+func (s *Server) resolveInput(mode, input string, cp *codepage.CodePage, maxCodePoints int) (string, error) {
 	if strings.TrimSpace(input) == "" {
 		return "", errors.New("input is required")
 	}
@@ -164,19 +418,22 @@ func (s *Server) resolveInput(mode, input string) (string, error) {
 	switch normalized {
 	case "", "text":
 		return input, nil
-	case "codepoint", "codepoints", "cp":
-		tokens := reverseinput.Tokenize(input)
-		if len(tokens) == 0 {
-			return "", errors.New("code point values required")
+	case "codepage", "legacy":
+		if cp == nil {
+			return "", errors.New("codepage mode requires a code page")
+		}
+		raw, err := reverseinput.Build(reverseinput.ModeBytes, input, 0)
+		if err != nil {
+			return "", err
 		}
-		return reverseinput.BuildStringFromCodePoints(tokens)
-	case "byte", "bytes":
-		tokens := reverseinput.Tokenize(input)
-		if len(tokens) == 0 {
-			return "", errors.New("byte values required")
+		text, undefined := cp.Decode([]byte(raw))
+		if len(undefined) > 0 {
+			return "", fmt.Errorf("byte 0x%02X at offset %d is undefined in %s", raw[undefined[0]], undefined[0], cp.Name)
 		}
-		return reverseinput.BuildStringFromBytes(tokens)
-	default:
-		return "", fmt.Errorf("unknown mode %q (use text, codepoints, bytes)", mode)
+		return text, nil
+	}
+	if m, ok := reverseinput.ParseMode(normalized); ok {
+		return reverseinput.Build(m, input, maxCodePoints)
 	}
+	return "", fmt.Errorf("unknown mode %q (use text, codepoints, bytes, utf16, escaped, names, dump, base64, base32, ascii85, quoted-printable, literal, codepage)", mode)
 }
@@ -5,6 +5,7 @@ import (
 	"encoding/json"
 	"net/http"
 	"net/http/httptest"
+	"slices"
 	"strings"
 	"testing"
 )
@@ -27,6 +28,9 @@ func TestHomeHandler(t *testing.T) {
 	if ct := w.Header().Get("Content-Type"); !strings.Contains(ct, "text/html") {
 		t.Fatalf("expected text/html content type, got %s", ct)
 	}
+	if got := w.Body.String(); !strings.Contains(got, `<option value="windows-1252">`) {
+		t.Fatalf("expected code page options in page")
+	}
 }
 
 func TestVisualiseHandler(t *testing.T) {
@@ -152,4 +156,746 @@ func TestDownloadHandlerCSV(t *testing.T) {
 	if !strings.Contains(w.Body.String(), "Character") {
 		t.Fatalf("expected CSV header, got %s", w.Body.String())
 	}
+	if !strings.Contains(w.Body.String(), "LATIN CAPITAL LETTER G,Lu,Latin,Basic Latin") {
+		t.Fatalf("expected character properties in CSV, got %s", w.Body.String())
+	}
+	if !strings.Contains(w.Body.String(), "LATIN SMALL LETTER O,Ll,Latin,Basic Latin,1,1,1,2,2") {
+		t.Fatalf("expected positions in CSV, got %s", w.Body.String())
+	}
+}
+
+func TestDownloadHandlerFormats(t *testing.T) {
+	srv := NewServer()
+	mux := http.NewServeMux()
+	srv.Routes(mux)
+
+	cases := []struct{ format, contentType, filename, body string }{
+		{"ndjson", "application/x-ndjson", "visualiser.ndjson", `"Name":"LATIN CAPITAL LETTER G"`},
+		{"tsv", "text/tab-separated-values", "visualiser.tsv", "Character\tCodePointHex\t"},
+		{"md", "text/markdown", "visualiser.md", "| Character | CodePointHex |"},
+		{"html", "text/html", "visualiser.html", "<th>Character</th>"},
+		{"yaml", "application/yaml", "visualiser.yaml", "    Name: \"LATIN SMALL LETTER O\"\n"},
+	}
+	for _, tc := range cases {
+		req := httptest.NewRequest(http.MethodGet, "/api/download?format="+tc.format+"&input=Go", nil)
+		w := httptest.NewRecorder()
+		mux.ServeHTTP(w, req)
+		if w.Code != http.StatusOK {
+			t.Fatalf("%s: expected status 200, got %d", tc.format, w.Code)
+		}
+		if ct := w.Header().Get("Content-Type"); !strings.Contains(ct, tc.contentType) {
+			t.Fatalf("%s: unexpected content type %s", tc.format, ct)
+		}
+		if disp := w.Header().Get("Content-Disposition"); !strings.Contains(disp, tc.filename) {
+			t.Fatalf("%s: unexpected attachment %s", tc.format, disp)
+		}
+		if !strings.Contains(w.Body.String(), tc.body) {
+			t.Fatalf("%s: expected body to contain %q, got %s", tc.format, tc.body, w.Body.String())
+		}
+	}
+
+	req := httptest.NewRequest(http.MethodGet, "/api/download?format=xml&input=Go", nil)
+	w := httptest.NewRecorder()
+	mux.ServeHTTP(w, req)
+	if w.Code != http.StatusBadRequest {
+		t.Fatalf("expected status 400 for an unknown format, got %d", w.Code)
+	}
+}
+
+func TestVisualiseHandlerGraphemes(t *testing.T) {
+	srv := NewServer()
+	mux := http.NewServeMux()
+	srv.Routes(mux)
+
+	payload := visualiseRequest{Input: "e\u0301🇮🇳", Graphemes: true}
+	buf, _ := json.Marshal(payload)
+
+	req := httptest.NewRequest(http.MethodPost, "/api/visualise", bytes.NewReader(buf))
+	w := httptest.NewRecorder()
+
+	mux.ServeHTTP(w, req)
+	if w.Code != http.StatusOK {
+		t.Fatalf("expected status 200, got %d", w.Code)
+	}
+	var resp visualiseResponse
+	if err := json.Unmarshal(w.Body.Bytes(), &resp); err != nil {
+		t.Fatalf("failed to unmarshal response: %v", err)
+	}
+	if len(resp.Items) != 4 {
+		t.Fatalf("expected 4 items, got %d", len(resp.Items))
+	}
+	if len(resp.Clusters) != 2 {
+		t.Fatalf("expected 2 clusters, got %d", len(resp.Clusters))
+	}
+	if len(resp.Clusters[1].Runes) != 2 {
+		t.Fatalf("expected flag cluster to hold 2 runes, got %d", len(resp.Clusters[1].Runes))
+	}
+}
+
+func TestVisualiseHandlerEncodings(t *testing.T) {
+	srv := NewServer()
+	mux := http.NewServeMux()
+	srv.Routes(mux)
+
+	payload := visualiseRequest{Input: "😊", Encodings: []string{"utf8", "UTF-16LE"}}
+	buf, _ := json.Marshal(payload)
+
+	req := httptest.NewRequest(http.MethodPost, "/api/visualise", bytes.NewReader(buf))
+	w := httptest.NewRecorder()
+
+	mux.ServeHTTP(w, req)
+	if w.Code != http.StatusOK {
+		t.Fatalf("expected status 200, got %d", w.Code)
+	}
+	var resp visualiseResponse
+	if err := json.Unmarshal(w.Body.Bytes(), &resp); err != nil {
+		t.Fatalf("failed to unmarshal response: %v", err)
+	}
+	if len(resp.Encodings) != 2 || resp.Encodings[1] != "utf16le" {
+		t.Fatalf("expected normalised encodings, got %v", resp.Encodings)
+	}
+	if resp.Items[0].UTF16High != "0xD83D" {
+		t.Fatalf("expected high surrogate 0xD83D, got %q", resp.Items[0].UTF16High)
+	}
+
+	bad, _ := json.Marshal(visualiseRequest{Input: "A", Encodings: []string{"ebcdic"}})
+	req = httptest.NewRequest(http.MethodPost, "/api/visualise", bytes.NewReader(bad))
+	w = httptest.NewRecorder()
+	mux.ServeHTTP(w, req)
+	if w.Code != http.StatusBadRequest {
+		t.Fatalf("expected 400 for unknown encoding, got %d", w.Code)
+	}
+}
+
+func TestDownloadHandlerCSVEncodings(t *testing.T) {
+	srv := NewServer()
+	mux := http.NewServeMux()
+	srv.Routes(mux)
+
+	req := httptest.NewRequest(http.MethodGet, "/api/download?format=csv&input=%F0%9F%98%8A&encodings=utf8,utf16be", nil)
+	w := httptest.NewRecorder()
+
+	mux.ServeHTTP(w, req)
+	if w.Code != http.StatusOK {
+		t.Fatalf("expected status 200, got %d", w.Code)
+	}
+	body := w.Body.String()
+	if !strings.Contains(body, "UTF16Units,UTF16High,UTF16Low,UTF16BEBytes") {
+		t.Fatalf("expected UTF-16 columns in CSV header, got %s", body)
+	}
+	if strings.Contains(body, "UTF32") {
+		t.Fatalf("expected UTF-32 columns to be omitted, got %s", body)
+	}
+	if !strings.Contains(body, "0xD83D 0xDE0A,0xD83D,0xDE0A,0xD8 0x3D 0xDE 0x0A") {
+		t.Fatalf("expected UTF-16 values in CSV row, got %s", body)
+	}
+}
+
+func TestVisualiseHandlerCodePage(t *testing.T) {
+	srv := NewServer()
+	mux := http.NewServeMux()
+	srv.Routes(mux)
+
+	payload := visualiseRequest{Input: "0x63 0x61 0x66 0xE9", Mode: "codepage", CodePage: "windows-1252"}
+	buf, _ := json.Marshal(payload)
+
+	req := httptest.NewRequest(http.MethodPost, "/api/visualise", bytes.NewReader(buf))
+	w := httptest.NewRecorder()
+
+	mux.ServeHTTP(w, req)
+	if w.Code != http.StatusOK {
+		t.Fatalf("expected status 200, got %d: %s", w.Code, w.Body.String())
+	}
+	var resp visualiseResponse
+	if err := json.Unmarshal(w.Body.Bytes(), &resp); err != nil {
+		t.Fatalf("failed to unmarshal response: %v", err)
+	}
+	if len(resp.Items) != 4 || resp.Items[3].CodePointHex != "U+00E9" {
+		t.Fatalf("expected café decoded from windows-1252, got %+v", resp.Items)
+	}
+	if resp.CodePage == nil || resp.CodePage.Bytes[3] != "0xE9" {
+		t.Fatalf("expected code page bytes in response, got %+v", resp.CodePage)
+	}
+
+	payload = visualiseRequest{Input: "aж", CodePage: "iso-8859-1"}
+	buf, _ = json.Marshal(payload)
+	req = httptest.NewRequest(http.MethodPost, "/api/visualise", bytes.NewReader(buf))
+	w = httptest.NewRecorder()
+	mux.ServeHTTP(w, req)
+	resp = visualiseResponse{}
+	if err := json.Unmarshal(w.Body.Bytes(), &resp); err != nil {
+		t.Fatalf("failed to unmarshal response: %v", err)
+	}
+	if resp.CodePage.Unmappable != 1 || resp.CodePage.Bytes[1] != "" {
+		t.Fatalf("expected unmappable rune to be flagged, got %+v", resp.CodePage)
+	}
+
+	for _, bad := range []visualiseRequest{
+		{Input: "0x41", Mode: "codepage"},
+		{Input: "0x81", Mode: "codepage", CodePage: "windows-1252"},
+		{Input: "A", CodePage: "ebcdic"},
+	} {
+		buf, _ = json.Marshal(bad)
+		req = httptest.NewRequest(http.MethodPost, "/api/visualise", bytes.NewReader(buf))
+		w = httptest.NewRecorder()
+		mux.ServeHTTP(w, req)
+		if w.Code != http.StatusBadRequest {
+			t.Fatalf("expected 400 for %+v, got %d", bad, w.Code)
+		}
+	}
+}
+
+func TestVisualiseHandlerNormalize(t *testing.T) {
+	srv := NewServer()
+	mux := http.NewServeMux()
+	srv.Routes(mux)
+
+	payload := visualiseRequest{Input: "e\u0301", Normalize: true}
+	buf, _ := json.Marshal(payload)
+
+	req := httptest.NewRequest(http.MethodPost, "/api/visualise", bytes.NewReader(buf))
+	w := httptest.NewRecorder()
+
+	mux.ServeHTTP(w, req)
+	if w.Code != http.StatusOK {
+		t.Fatalf("expected status 200, got %d", w.Code)
+	}
+	var resp visualiseResponse
+	if err := json.Unmarshal(w.Body.Bytes(), &resp); err != nil {
+		t.Fatalf("failed to unmarshal response: %v", err)
+	}
+	if len(resp.Normalization) != 4 {
+		t.Fatalf("expected 4 normalization forms, got %d", len(resp.Normalization))
+	}
+	if nfc := resp.Normalization[0]; nfc.Form != "NFC" || nfc.Runes != 1 || nfc.Normalized {
+		t.Fatalf("unexpected NFC entry: %+v", nfc)
+	}
+}
+
+func TestVisualiseHandlerConfusables(t *testing.T) {
+	srv := NewServer()
+	mux := http.NewServeMux()
+	srv.Routes(mux)
+
+	payload := visualiseRequest{Input: "p\u0430ypal", CompareWith: "paypal"}
+	buf, _ := json.Marshal(payload)
+
+	req := httptest.NewRequest(http.MethodPost, "/api/visualise", bytes.NewReader(buf))
+	w := httptest.NewRecorder()
+
+	mux.ServeHTTP(w, req)
+	if w.Code != http.StatusOK {
+		t.Fatalf("expected status 200, got %d", w.Code)
+	}
+	var resp visualiseResponse
+	if err := json.Unmarshal(w.Body.Bytes(), &resp); err != nil {
+		t.Fatalf("failed to unmarshal response: %v", err)
+	}
+	report := resp.Confusables
+	if report == nil || report.Skeleton != "paypal" || !report.Equivalent {
+		t.Fatalf("unexpected confusables report: %+v", report)
+	}
+	if len(report.Flags) != 1 || report.Flags[0].Index != 1 || report.Flags[0].LooksLike != "a" {
+		t.Fatalf("unexpected flags: %+v", report.Flags)
+	}
+}
+
+func TestVisualiseHandlerHazards(t *testing.T) {
+	srv := NewServer()
+	mux := http.NewServeMux()
+	srv.Routes(mux)
+
+	payload := visualiseRequest{Input: "a\u202eb"}
+	buf, _ := json.Marshal(payload)
+
+	req := httptest.NewRequest(http.MethodPost, "/api/visualise", bytes.NewReader(buf))
+	w := httptest.NewRecorder()
+
+	mux.ServeHTTP(w, req)
+	if w.Code != http.StatusOK {
+		t.Fatalf("expected status 200, got %d", w.Code)
+	}
+	var resp visualiseResponse
+	if err := json.Unmarshal(w.Body.Bytes(), &resp); err != nil {
+		t.Fatalf("failed to unmarshal response: %v", err)
+	}
+	if resp.Items[0].Hazard != nil {
+		t.Fatalf("expected no hazard for 'a', got %+v", resp.Items[0].Hazard)
+	}
+	if h := resp.Items[1].Hazard; h == nil || h.Kind != "bidi-override" || h.Severity != "danger" {
+		t.Fatalf("unexpected hazard for U+202E: %+v", h)
+	}
+}
+
+func TestVisualiseHandlerInvalidUTF8(t *testing.T) {
+	srv := NewServer()
+	mux := http.NewServeMux()
+	srv.Routes(mux)
+
+	payload := visualiseRequest{Input: "0x41 0xC0 0xAF 0x42", Mode: "bytes", Graphemes: true}
+	buf, _ := json.Marshal(payload)
+
+	req := httptest.NewRequest(http.MethodPost, "/api/visualise", bytes.NewReader(buf))
+	w := httptest.NewRecorder()
+
+	mux.ServeHTTP(w, req)
+	if w.Code != http.StatusOK {
+		t.Fatalf("expected status 200, got %d: %s", w.Code, w.Body.String())
+	}
+	var resp visualiseResponse
+	if err := json.Unmarshal(w.Body.Bytes(), &resp); err != nil {
+		t.Fatalf("failed to unmarshal response: %v", err)
+	}
+	if len(resp.Items) != 2 || resp.Items[1].CodePointHex != "U+0042" {
+		t.Fatalf("unexpected items: %+v", resp.Items)
+	}
+	if len(resp.Invalid) != 1 {
+		t.Fatalf("expected 1 invalid sequence, got %+v", resp.Invalid)
+	}
+	if inv := resp.Invalid[0]; inv.Offset != 1 || inv.Index != 1 || inv.Reason != "overlong encoding" {
+		t.Fatalf("unexpected invalid sequence: %+v", inv)
+	}
+	if len(resp.Clusters) != 2 {
+		t.Fatalf("expected clusters for the decoded runes, got %+v", resp.Clusters)
+	}
+	if len(resp.Encoded) == 0 || resp.Encoded[0].Text != "QcCvQg==" {
+		t.Fatalf("expected the raw bytes encoded, got %+v", resp.Encoded)
+	}
+
+	payload = visualiseRequest{Input: "0xFF 0x41", Mode: "bytes"}
+	buf, _ = json.Marshal(payload)
+	req = httptest.NewRequest(http.MethodPost, "/api/visualise", bytes.NewReader(buf))
+	w = httptest.NewRecorder()
+	mux.ServeHTTP(w, req)
+	resp = visualiseResponse{}
+	if err := json.Unmarshal(w.Body.Bytes(), &resp); err != nil {
+		t.Fatalf("failed to unmarshal response: %v", err)
+	}
+	if len(resp.Encoded) == 0 || resp.Encoded[0].Encoding != "base64" || resp.Encoded[0].Text != "/0E=" {
+		t.Fatalf("expected base64 of the raw bytes 0xFF 0x41, got %+v", resp.Encoded)
+	}
+}
+
+func TestVisualiseHandlerEmoji(t *testing.T) {
+	srv := NewServer()
+	mux := http.NewServeMux()
+	srv.Routes(mux)
+
+	payload := visualiseRequest{Input: "a\U0001F1EE\U0001F1F3"}
+	buf, _ := json.Marshal(payload)
+
+	req := httptest.NewRequest(http.MethodPost, "/api/visualise", bytes.NewReader(buf))
+	w := httptest.NewRecorder()
+
+	mux.ServeHTTP(w, req)
+	if w.Code != http.StatusOK {
+		t.Fatalf("expected status 200, got %d", w.Code)
+	}
+	var resp visualiseResponse
+	if err := json.Unmarshal(w.Body.Bytes(), &resp); err != nil {
+		t.Fatalf("failed to unmarshal response: %v", err)
+	}
+	if len(resp.Emoji) != 1 {
+		t.Fatalf("expected 1 emoji sequence, got %+v", resp.Emoji)
+	}
+	seq := resp.Emoji[0]
+	if seq.Name != "flag: India" || seq.RuneIndex != 1 || len(seq.Parts) != 2 || seq.Parts[0].Role != "regional indicator I" {
+		t.Fatalf("unexpected emoji sequence: %+v", seq)
+	}
+}
+
+func TestDownloadHandlerEscapes(t *testing.T) {
+	srv := NewServer()
+	mux := http.NewServeMux()
+	srv.Routes(mux)
+
+	req := httptest.NewRequest(http.MethodGet, "/api/download?format=csv&input=%C3%A9&escapes=go,c%2B%2B", nil)
+	w := httptest.NewRecorder()
+	mux.ServeHTTP(w, req)
+	if w.Code != http.StatusOK {
+		t.Fatalf("expected status 200, got %d", w.Code)
+	}
+	body := w.Body.String()
+	if !strings.Contains(body, "GoEscape,CEscape") || strings.Contains(body, "JSONEscape") {
+		t.Fatalf("expected only Go and C escape columns, got %s", body)
+	}
+	if !strings.Contains(body, `\u00e9,\xc3\xa9`) {
+		t.Fatalf("expected escape values in CSV row, got %s", body)
+	}
+
+	req = httptest.NewRequest(http.MethodGet, "/api/download?format=json&input=%C3%A9&escapes=python", nil)
+	w = httptest.NewRecorder()
+	mux.ServeHTTP(w, req)
+	var resp visualiseResponse
+	if err := json.NewDecoder(w.Body).Decode(&resp); err != nil {
+		t.Fatalf("decode response: %v", err)
+	}
+	if len(resp.Escaped) != 1 || resp.Escaped[0].Literal != `"\xe9"` {
+		t.Fatalf("unexpected escaped strings: %+v", resp.Escaped)
+	}
+	if len(resp.Encoded) != 5 || resp.Encoded[0].Text != "w6k=" {
+		t.Fatalf("expected binary-to-text encodings in the JSON download, got %+v", resp.Encoded)
+	}
+	if resp.Items[0].Escapes["rust"] != `\u{e9}` {
+		t.Fatalf("expected per-rune escapes in items, got %v", resp.Items[0].Escapes)
+	}
+
+	req = httptest.NewRequest(http.MethodGet, "/api/download?format=csv&input=a&escapes=cobol", nil)
+	w = httptest.NewRecorder()
+	mux.ServeHTTP(w, req)
+	if w.Code != http.StatusBadRequest {
+		t.Fatalf("expected status 400 for unknown escape syntax, got %d", w.Code)
+	}
+}
+
+func TestVisualiseHandlerEscaped(t *testing.T) {
+	srv := NewServer()
+	mux := http.NewServeMux()
+	srv.Routes(mux)
+
+	payload := visualiseRequest{Input: `caf\u00e9 &#x1F600;%E2%82%AC`, Mode: "escaped"}
+	buf, _ := json.Marshal(payload)
+	req := httptest.NewRequest(http.MethodPost, "/api/visualise", bytes.NewReader(buf))
+	w := httptest.NewRecorder()
+	mux.ServeHTTP(w, req)
+	if w.Code != http.StatusOK {
+		t.Fatalf("expected status 200, got %d: %s", w.Code, w.Body.String())
+	}
+	var resp visualiseResponse
+	if err := json.Unmarshal(w.Body.Bytes(), &resp); err != nil {
+		t.Fatalf("failed to unmarshal response: %v", err)
+	}
+	var got []string
+	for _, item := range resp.Items {
+		got = append(got, item.CodePointHex)
+	}
+	want := "U+0063 U+0061 U+0066 U+00E9 U+0020 U+1F600 U+20AC"
+	if strings.Join(got, " ") != want {
+		t.Fatalf("want %s, got %v", want, got)
+	}
+
+	payload = visualiseRequest{Input: `\q`, Mode: "escaped"}
+	buf, _ = json.Marshal(payload)
+	req = httptest.NewRequest(http.MethodPost, "/api/visualise", bytes.NewReader(buf))
+	w = httptest.NewRecorder()
+	mux.ServeHTTP(w, req)
+	if w.Code != http.StatusBadRequest {
+		t.Fatalf("expected status 400 for unknown escape, got %d", w.Code)
+	}
+}
+
+func TestLookupHandler(t *testing.T) {
+	srv := NewServer()
+	mux := http.NewServeMux()
+	srv.Routes(mux)
+
+	req := httptest.NewRequest(http.MethodGet, "/api/lookup?q=snowman&limit=2", nil)
+	w := httptest.NewRecorder()
+	mux.ServeHTTP(w, req)
+	if w.Code != http.StatusOK {
+		t.Fatalf("expected status 200, got %d: %s", w.Code, w.Body.String())
+	}
+	var resp lookupResponse
+	if err := json.Unmarshal(w.Body.Bytes(), &resp); err != nil {
+		t.Fatalf("failed to unmarshal response: %v", err)
+	}
+	if len(resp.Matches) != 2 || resp.Matches[0].CodePoint != "U+2603" || resp.Matches[0].Name != "SNOWMAN" {
+		t.Fatalf("unexpected matches: %+v", resp.Matches)
+	}
+
+	for _, target := range []string{"/api/lookup", "/api/lookup?q=arrow&limit=0"} {
+		w = httptest.NewRecorder()
+		mux.ServeHTTP(w, httptest.NewRequest(http.MethodGet, target, nil))
+		if w.Code != http.StatusBadRequest {
+			t.Fatalf("%s: expected status 400, got %d", target, w.Code)
+		}
+	}
+}
+
+func TestVisualiseHandlerNames(t *testing.T) {
+	srv := NewServer()
+	mux := http.NewServeMux()
+	srv.Routes(mux)
+
+	payload := visualiseRequest{Input: "snowman; latin capital letter a", Mode: "names"}
+	buf, _ := json.Marshal(payload)
+	w := httptest.NewRecorder()
+	mux.ServeHTTP(w, httptest.NewRequest(http.MethodPost, "/api/visualise", bytes.NewReader(buf)))
+	if w.Code != http.StatusOK {
+		t.Fatalf("expected status 200, got %d: %s", w.Code, w.Body.String())
+	}
+	var resp visualiseResponse
+	if err := json.Unmarshal(w.Body.Bytes(), &resp); err != nil {
+		t.Fatalf("failed to unmarshal response: %v", err)
+	}
+	if len(resp.Items) != 2 || resp.Items[0].CodePointHex != "U+2603" || resp.Items[1].CodePointHex != "U+0041" {
+		t.Fatalf("unexpected items: %+v", resp.Items)
+	}
+}
+
+func TestVisualiseHandlerCodePointRange(t *testing.T) {
+	srv := NewServer()
+	mux := http.NewServeMux()
+	srv.Routes(mux)
+
+	payload := visualiseRequest{Input: "U+0041..U+005A", Mode: "codepoints"}
+	buf, _ := json.Marshal(payload)
+	w := httptest.NewRecorder()
+	mux.ServeHTTP(w, httptest.NewRequest(http.MethodPost, "/api/visualise", bytes.NewReader(buf)))
+	if w.Code != http.StatusOK {
+		t.Fatalf("expected status 200, got %d: %s", w.Code, w.Body.String())
+	}
+	var resp visualiseResponse
+	if err := json.Unmarshal(w.Body.Bytes(), &resp); err != nil {
+		t.Fatalf("failed to unmarshal response: %v", err)
+	}
+	if len(resp.Items) != 26 || resp.Items[25].CodePointHex != "U+005A" {
+		t.Fatalf("expected A-Z, got %d items", len(resp.Items))
+	}
+
+	payload = visualiseRequest{Input: "U+0000..U+10FFFF", Mode: "codepoints"}
+	buf, _ = json.Marshal(payload)
+	w = httptest.NewRecorder()
+	mux.ServeHTTP(w, httptest.NewRequest(http.MethodPost, "/api/visualise", bytes.NewReader(buf)))
+	if w.Code != http.StatusBadRequest {
+		t.Fatalf("expected status 400 past the code point cap, got %d", w.Code)
+	}
+}
+
+func TestVisualiseHandlerMaxCodePoints(t *testing.T) {
+	srv := NewServer()
+	mux := http.NewServeMux()
+	srv.Routes(mux)
+
+	// A raised cap lets the range through, but never past the server limit.
+	payload := visualiseRequest{Input: "U+0000..U+1FFF", Mode: "codepoints", MaxCodePoints: 10000}
+	buf, _ := json.Marshal(payload)
+	w := httptest.NewRecorder()
+	mux.ServeHTTP(w, httptest.NewRequest(http.MethodPost, "/api/visualise", bytes.NewReader(buf)))
+	if w.Code != http.StatusOK {
+		t.Fatalf("expected status 200 under a raised cap, got %d: %s", w.Code, w.Body.String())
+	}
+	if got := codePointCap(1 << 30); got != maxCodePointsLimit {
+		t.Fatalf("expected the cap to be clamped to %d, got %d", maxCodePointsLimit, got)
+	}
+	payload = visualiseRequest{Input: "U+0000..U+10FFFF", Mode: "codepoints", MaxCodePoints: 1 << 30}
+	buf, _ = json.Marshal(payload)
+	w = httptest.NewRecorder()
+	mux.ServeHTTP(w, httptest.NewRequest(http.MethodPost, "/api/visualise", bytes.NewReader(buf)))
+	if w.Code != http.StatusBadRequest || !strings.Contains(w.Body.String(), "limit of 65536 code points") {
+		t.Fatalf("expected the clamped cap to reject the full range, got %d: %s", w.Code, w.Body.String())
+	}
+
+	// A lowered cap fails with the same structured error as other parse errors.
+	payload = visualiseRequest{Input: "U+0041 U+0041..U+005A", Mode: "codepoints", MaxCodePoints: 10}
+	buf, _ = json.Marshal(payload)
+	w = httptest.NewRecorder()
+	mux.ServeHTTP(w, httptest.NewRequest(http.MethodPost, "/api/visualise", bytes.NewReader(buf)))
+	if w.Code != http.StatusBadRequest {
+		t.Fatalf("expected status 400 past a lowered cap, got %d: %s", w.Code, w.Body.String())
+	}
+	if ct := w.Header().Get("Content-Type"); ct != "application/json" {
+		t.Fatalf("expected a JSON error body, got Content-Type %q", ct)
+	}
+	var resp parseErrorResponse
+	if err := json.Unmarshal(w.Body.Bytes(), &resp); err != nil {
+		t.Fatalf("failed to unmarshal error: %v", err)
+	}
+	if resp.Index != 1 || resp.Token != "U+0041..U+005A" || resp.Start != 7 || resp.End != 21 ||
+		!strings.Contains(resp.Error, "limit of 10 code points") || resp.Suggestion == "" {
+		t.Fatalf("expected the range token to be located, got %+v", resp)
+	}
+
+	w = httptest.NewRecorder()
+	mux.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/api/download?format=json&mode=codepoints&maxCodePoints=10&input=U%2B0041..U%2B005A", nil))
+	if w.Code != http.StatusBadRequest || !strings.Contains(w.Body.String(), `"expected"`) {
+		t.Fatalf("expected a structured error from the download, got %d: %s", w.Code, w.Body.String())
+	}
+}
+
+func TestVisualiseHandlerUTF16(t *testing.T) {
+	srv := NewServer()
+	mux := http.NewServeMux()
+	srv.Routes(mux)
+
+	payload := visualiseRequest{Input: "0xD83D 0xDE0A", Mode: "utf16"}
+	buf, _ := json.Marshal(payload)
+	w := httptest.NewRecorder()
+	mux.ServeHTTP(w, httptest.NewRequest(http.MethodPost, "/api/visualise", bytes.NewReader(buf)))
+	if w.Code != http.StatusOK {
+		t.Fatalf("expected status 200, got %d: %s", w.Code, w.Body.String())
+	}
+	var resp visualiseResponse
+	if err := json.Unmarshal(w.Body.Bytes(), &resp); err != nil {
+		t.Fatalf("failed to unmarshal response: %v", err)
+	}
+	if len(resp.Items) != 1 || resp.Items[0].CodePointHex != "U+1F60A" {
+		t.Fatalf("expected one paired rune, got %+v", resp.Items)
+	}
+
+	payload = visualiseRequest{Input: "0xDE0A", Mode: "utf16"}
+	buf, _ = json.Marshal(payload)
+	w = httptest.NewRecorder()
+	mux.ServeHTTP(w, httptest.NewRequest(http.MethodPost, "/api/visualise", bytes.NewReader(buf)))
+	if w.Code != http.StatusBadRequest || !strings.Contains(w.Body.String(), "unpaired low surrogate") {
+		t.Fatalf("expected 400 for an unpaired surrogate, got %d: %s", w.Code, w.Body.String())
+	}
+}
+
+func TestVisualiseHandlerParseError(t *testing.T) {
+	srv := NewServer()
+	mux := http.NewServeMux()
+	srv.Routes(mux)
+
+	payload := visualiseRequest{Input: "é 1F60A", Mode: "codepoints"}
+	buf, _ := json.Marshal(payload)
+	w := httptest.NewRecorder()
+	mux.ServeHTTP(w, httptest.NewRequest(http.MethodPost, "/api/visualise", bytes.NewReader(buf)))
+	if w.Code != http.StatusBadRequest {
+		t.Fatalf("expected status 400, got %d: %s", w.Code, w.Body.String())
+	}
+	if ct := w.Header().Get("Content-Type"); ct != "application/json" {
+		t.Fatalf("expected a JSON error body, got Content-Type %q", ct)
+	}
+	var resp parseErrorResponse
+	if err := json.Unmarshal(w.Body.Bytes(), &resp); err != nil {
+		t.Fatalf("failed to unmarshal error: %v", err)
+	}
+	if resp.Index != 0 || resp.Token != "é" || resp.Start != 0 || resp.End != 2 || resp.EndUTF16 != 1 {
+		t.Fatalf("expected the first token to be located, got %+v", resp)
+	}
+	if resp.Expected == "" {
+		t.Fatalf("expected the expected format to be set, got %+v", resp)
+	}
+
+	payload = visualiseRequest{Input: "U+00E9 1F60A", Mode: "codepoints"}
+	buf, _ = json.Marshal(payload)
+	w = httptest.NewRecorder()
+	mux.ServeHTTP(w, httptest.NewRequest(http.MethodPost, "/api/visualise", bytes.NewReader(buf)))
+	resp = parseErrorResponse{}
+	if err := json.Unmarshal(w.Body.Bytes(), &resp); err != nil {
+		t.Fatalf("failed to unmarshal error: %v", err)
+	}
+	if resp.Index != 1 || resp.Start != 7 || resp.End != 12 || resp.StartUTF16 != 7 || resp.Suggestion != "did you mean U+1F60A?" {
+		t.Fatalf("expected the second token with a suggestion, got %+v", resp)
+	}
+}
+
+func TestVisualiseHandlerDump(t *testing.T) {
+	srv := NewServer()
+	mux := http.NewServeMux()
+	srv.Routes(mux)
+
+	payload := visualiseRequest{Input: "0000   63 61 66 c3 a9   caf..\n", Mode: "dump"}
+	buf, _ := json.Marshal(payload)
+	w := httptest.NewRecorder()
+	mux.ServeHTTP(w, httptest.NewRequest(http.MethodPost, "/api/visualise", bytes.NewReader(buf)))
+	if w.Code != http.StatusOK {
+		t.Fatalf("expected status 200, got %d: %s", w.Code, w.Body.String())
+	}
+	var resp visualiseResponse
+	if err := json.Unmarshal(w.Body.Bytes(), &resp); err != nil {
+		t.Fatalf("failed to unmarshal response: %v", err)
+	}
+	if len(resp.Items) != 4 || resp.Items[3].CodePointHex != "U+00E9" {
+		t.Fatalf("expected the dump to rebuild café, got %+v", resp.Items)
+	}
+
+	payload = visualiseRequest{Input: "0000   63 61\n0010   66\n", Mode: "dump"}
+	buf, _ = json.Marshal(payload)
+	w = httptest.NewRecorder()
+	mux.ServeHTTP(w, httptest.NewRequest(http.MethodPost, "/api/visualise", bytes.NewReader(buf)))
+	var perr parseErrorResponse
+	if err := json.Unmarshal(w.Body.Bytes(), &perr); err != nil || w.Code != http.StatusBadRequest {
+		t.Fatalf("expected a JSON parse error, got %d: %s", w.Code, w.Body.String())
+	}
+	if perr.Index != 0 || perr.Token != "61" {
+		t.Fatalf("expected the short first line to be reported, got %+v", perr)
+	}
+}
+
+func TestVisualiseHandlerBase64(t *testing.T) {
+	srv := NewServer()
+	mux := http.NewServeMux()
+	srv.Routes(mux)
+
+	payload := visualiseRequest{Input: "eyJzdWIiOiLDqSJ9", Mode: "base64"}
+	buf, _ := json.Marshal(payload)
+	w := httptest.NewRecorder()
+	mux.ServeHTTP(w, httptest.NewRequest(http.MethodPost, "/api/visualise", bytes.NewReader(buf)))
+	if w.Code != http.StatusOK {
+		t.Fatalf("expected status 200, got %d: %s", w.Code, w.Body.String())
+	}
+	var resp visualiseResponse
+	if err := json.Unmarshal(w.Body.Bytes(), &resp); err != nil {
+		t.Fatalf("failed to unmarshal response: %v", err)
+	}
+	if len(resp.Items) != 11 || resp.Items[8].CodePointHex != "U+00E9" {
+		t.Fatalf("expected the payload {\"sub\":\"\u00e9\"} to decode, got %d items", len(resp.Items))
+	}
+	if len(resp.Encoded) == 0 || resp.Encoded[1].Encoding != "base64url" || resp.Encoded[1].Text != "eyJzdWIiOiLDqSJ9" {
+		t.Fatalf("expected the base64url round trip, got %+v", resp.Encoded)
+	}
+}
+
+func TestVisualiseHandlerLiteral(t *testing.T) {
+	srv := NewServer()
+	mux := http.NewServeMux()
+	srv.Routes(mux)
+
+	payload := visualiseRequest{Input: "[]byte{0xE2, 0x82, 0xAC}", Mode: "literal"}
+	buf, _ := json.Marshal(payload)
+	w := httptest.NewRecorder()
+	mux.ServeHTTP(w, httptest.NewRequest(http.MethodPost, "/api/visualise", bytes.NewReader(buf)))
+	if w.Code != http.StatusOK {
+		t.Fatalf("expected status 200, got %d: %s", w.Code, w.Body.String())
+	}
+	var resp visualiseResponse
+	if err := json.Unmarshal(w.Body.Bytes(), &resp); err != nil {
+		t.Fatalf("failed to unmarshal response: %v", err)
+	}
+	if len(resp.Items) != 1 || resp.Items[0].CodePointHex != "U+20AC" {
+		t.Fatalf("expected the euro sign, got %+v", resp.Items)
+	}
+}
+
+func TestVisualiseHandlerView(t *testing.T) {
+	srv := NewServer()
+	mux := http.NewServeMux()
+	srv.Routes(mux)
+
+	payload := visualiseRequest{Input: "bé日aé", Sort: "codepoint", Unique: true, Filter: "non-ascii"}
+	buf, _ := json.Marshal(payload)
+
+	req := httptest.NewRequest(http.MethodPost, "/api/visualise", bytes.NewReader(buf))
+	w := httptest.NewRecorder()
+
+	mux.ServeHTTP(w, req)
+	if w.Code != http.StatusOK {
+		t.Fatalf("expected status 200, got %d", w.Code)
+	}
+	var resp visualiseResponse
+	if err := json.Unmarshal(w.Body.Bytes(), &resp); err != nil {
+		t.Fatalf("failed to unmarshal response: %v", err)
+	}
+	if len(resp.Items) != 5 {
+		t.Fatalf("expected items to keep every rune, got %d", len(resp.Items))
+	}
+	if resp.View == nil || !slices.Equal(resp.View.Rows, []int{1, 2}) || !slices.Equal(resp.View.Counts, []int{2, 1}) {
+		t.Fatalf("expected view rows [1 2] with counts [2 1], got %+v", resp.View)
+	}
+
+	for _, bad := range []visualiseRequest{
+		{Input: "A", Filter: "bytes>two"},
+		{Input: "A", Sort: "name"},
+		{Input: "A", Unique: true, Graphemes: true},
+	} {
+		buf, _ := json.Marshal(bad)
+		req := httptest.NewRequest(http.MethodPost, "/api/visualise", bytes.NewReader(buf))
+		w := httptest.NewRecorder()
+		mux.ServeHTTP(w, req)
+		if w.Code != http.StatusBadRequest {
+			t.Fatalf("expected 400 for %+v, got %d", bad, w.Code)
+		}
+	}
 }
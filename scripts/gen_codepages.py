@@ -0,0 +1,74 @@
+#!/usr/bin/env python3
+"""Regenerates internal/codepage/tables.go from Python's charmap codecs.
+
+Run from the repository root:
+
+    python3 scripts/gen_codepages.py
+"""
+import subprocess
+
+OUT = "internal/codepage/tables.go"
+
+# (canonical name, Python codec, aliases)
+CODEPAGES = [
+    ("iso-8859-1", "iso8859_1", ["latin1", "l1"]),
+    ("iso-8859-2", "iso8859_2", ["latin2", "l2"]),
+    ("iso-8859-3", "iso8859_3", ["latin3", "l3"]),
+    ("iso-8859-4", "iso8859_4", ["latin4", "l4"]),
+    ("iso-8859-5", "iso8859_5", ["cyrillic"]),
+    ("iso-8859-6", "iso8859_6", ["arabic"]),
+    ("iso-8859-7", "iso8859_7", ["greek"]),
+    ("iso-8859-8", "iso8859_8", ["hebrew"]),
+    ("iso-8859-9", "iso8859_9", ["latin5", "l5"]),
+    ("iso-8859-10", "iso8859_10", ["latin6", "l6"]),
+    ("iso-8859-11", "iso8859_11", ["thai"]),
+    ("iso-8859-13", "iso8859_13", ["latin7", "l7"]),
+    ("iso-8859-14", "iso8859_14", ["latin8", "l8"]),
+    ("iso-8859-15", "iso8859_15", ["latin9", "l9"]),
+    ("iso-8859-16", "iso8859_16", ["latin10", "l10"]),
+    ("windows-1250", "cp1250", ["cp1250"]),
+    ("windows-1251", "cp1251", ["cp1251"]),
+    ("windows-1252", "cp1252", ["cp1252"]),
+    ("windows-1253", "cp1253", ["cp1253"]),
+    ("windows-1254", "cp1254", ["cp1254"]),
+    ("windows-1255", "cp1255", ["cp1255"]),
+    ("windows-1256", "cp1256", ["cp1256"]),
+    ("windows-1257", "cp1257", ["cp1257"]),
+    ("windows-1258", "cp1258", ["cp1258"]),
+    ("cp437", "cp437", ["ibm437", "dos"]),
+    ("koi8-r", "koi8_r", ["koi8"]),
+]
+
+
+def decode_table(codec):
+    table = []
+    for b in range(256):
+        try:
+            table.append(ord(bytes([b]).decode(codec)))
+        except UnicodeDecodeError:
+            table.append(-1)
+    return table
+
+
+def main():
+    with open(OUT, "w") as f:
+        f.write("// Code generated by scripts/gen_codepages.py; DO NOT EDIT.\n\n")
+        f.write("package codepage\n\n")
+        f.write("var codePages = []*CodePage{\n")
+        for name, codec, aliases in CODEPAGES:
+            f.write("\t{\n")
+            f.write('\t\tName:    "%s",\n' % name)
+            f.write("\t\tAliases: []string{%s},\n" % ", ".join('"%s"' % a for a in aliases))
+            f.write("\t\ttable: [256]rune{\n")
+            table = decode_table(codec)
+            for row in range(0, 256, 8):
+                cells = ", ".join("-1" if r < 0 else "0x%04X" % r for r in table[row:row + 8])
+                f.write("\t\t\t%s,\n" % cells)
+            f.write("\t\t},\n")
+            f.write("\t},\n")
+        f.write("}\n")
+    subprocess.run(["gofmt", "-w", OUT], check=True)
+
+
+if __name__ == "__main__":
+    main()
@@ -0,0 +1,115 @@
+#!/usr/bin/env python3
+"""Regenerates internal/confusables/confusables.txt.
+
+The table is the single code point mappings of Unicode's confusables.txt
+(UTS #39), pinned to the Unicode version of internal/ucd. Each line reads
+SOURCE;PROTOTYPE with code points in hex. Download the data file and run
+from the repository root:
+
+    curl -O https://www.unicode.org/Public/security/14.0.0/confusables.txt
+    python3 scripts/gen_confusables.py confusables.txt
+
+Without the data file, --icu derives the same table from the system ICU's
+UTS #39 skeletons. It keeps only code points assigned in this Python's
+Unicode version, which must be 14.0.0, and records the ICU version used:
+
+    python3 scripts/gen_confusables.py --icu
+"""
+import ctypes
+import ctypes.util
+import re
+import sys
+import unicodedata
+
+OUT = "internal/confusables/confusables.txt"
+VERSION = "14.0.0"
+
+
+def from_data_file(path):
+    """Reads the SOURCE ; TARGET ; MA lines of confusables.txt."""
+    table = {}
+    with open(path, encoding="utf-8-sig") as f:
+        text = f.read()
+    match = re.search(r"^# Version: (\S+)", text, re.M)
+    if not match or match.group(1) != VERSION:
+        sys.exit("%s is not confusables.txt version %s" % (path, VERSION))
+    for line in text.splitlines():
+        line = line.split("#", 1)[0].strip()
+        if not line:
+            continue
+        source, target = [field.strip() for field in line.split(";")[:2]]
+        if " " in source:
+            continue  # UTS #39 skeletons map single code points only
+        table[int(source, 16)] = "".join(chr(int(cp, 16)) for cp in target.split())
+    return table, "confusables.txt version %s" % VERSION
+
+
+def from_icu():
+    """Asks ICU's spoof checker for the skeleton of every code point."""
+    if unicodedata.unidata_version != VERSION:
+        sys.exit("--icu needs Python's unicodedata at Unicode %s, not %s" % (VERSION, unicodedata.unidata_version))
+    path = ctypes.util.find_library("icui18n")
+    if not path:
+        sys.exit("--icu needs ICU's libicui18n")
+    lib = ctypes.CDLL(path)
+    # ICU exports its functions with the major version appended.
+    major = re.search(r"\.so\.(\d+)", path)
+    if not major:
+        sys.exit("cannot tell the ICU version of %s" % path)
+    suffix = "_" + major.group(1)
+
+    uspoof_open = getattr(lib, "uspoof_open" + suffix)
+    uspoof_open.restype = ctypes.c_void_p
+    get_skeleton = getattr(lib, "uspoof_getSkeleton" + suffix)
+    get_skeleton.argtypes = [ctypes.c_void_p, ctypes.c_uint32, ctypes.c_char_p, ctypes.c_int32,
+                             ctypes.c_char_p, ctypes.c_int32, ctypes.POINTER(ctypes.c_int)]
+    status = ctypes.c_int(0)
+    checker = uspoof_open(ctypes.byref(status))
+    if status.value > 0:
+        sys.exit("uspoof_open failed with ICU error %d" % status.value)
+
+    def skeleton(ch):
+        source = ch.encode("utf-16-le")
+        buf = ctypes.create_string_buffer(256)
+        status = ctypes.c_int(0)
+        n = get_skeleton(checker, 0, source, len(source) // 2, buf, len(buf) // 2, ctypes.byref(status))
+        if status.value > 0:
+            sys.exit("uspoof_getSkeleton failed with ICU error %d" % status.value)
+        return buf.raw[:2 * n].decode("utf-16-le")
+
+    def assigned(text):
+        return all(unicodedata.category(c) != "Cn" for c in text)
+
+    table = {}
+    for cp in range(0x110000):
+        ch = chr(cp)
+        # Surrogates cannot be encoded, and a skeleton decomposes its input
+        # first, so only code points that are their own NFD need a mapping.
+        if 0xD800 <= cp <= 0xDFFF or not assigned(ch) or unicodedata.normalize("NFD", ch) != ch:
+            continue
+        target = skeleton(ch)
+        if target != ch and assigned(target):
+            table[cp] = target
+    info = (ctypes.c_uint8 * 4)()
+    getattr(ctypes.CDLL(ctypes.util.find_library("icuuc")), "u_getVersion" + suffix)(info)
+    version = "%d.%d" % (info[0], info[1])
+    return table, "ICU %s skeletons of the Unicode %s code points" % (version, VERSION)
+
+
+def main():
+    if sys.argv[1:] == ["--icu"]:
+        table, source = from_icu()
+    elif len(sys.argv) == 2:
+        table, source = from_data_file(sys.argv[1])
+    else:
+        sys.exit("usage: gen_confusables.py confusables.txt | --icu")
+
+    with open(OUT, "w") as f:
+        f.write("# Generated by scripts/gen_confusables.py from %s.\n" % source)
+        for cp in sorted(table):
+            target = " ".join("%04X" % ord(t) for t in table[cp])
+            f.write("%04X;%s\n" % (cp, target))
+
+
+if __name__ == "__main__":
+    main()
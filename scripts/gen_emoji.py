@@ -0,0 +1,38 @@
+#!/usr/bin/env python3
+"""Regenerates internal/emoji/sequences.txt from emoji-test.txt (UTS #51).
+
+Each output line reads SEQUENCE;NAME where SEQUENCE lists the code points in
+hex with every U+FE0F removed, so fully-, minimally- and unqualified forms of
+an emoji share one entry. Run from the repository root:
+
+    python3 scripts/gen_emoji.py path/to/emoji-test.txt
+"""
+import re
+import sys
+
+OUT = "internal/emoji/sequences.txt"
+LINE = re.compile(r"^([0-9A-F ]+?)\s*;\s*[a-z-]+\s*#\s*\S+\s+E\d+\.\d+\s+(.+)$")
+
+
+def main():
+    if len(sys.argv) != 2:
+        sys.exit("usage: gen_emoji.py path/to/emoji-test.txt")
+    names = {}
+    version = ""
+    with open(sys.argv[1], encoding="utf-8") as f:
+        for line in f:
+            if line.startswith("# Version:"):
+                version = line.split(":", 1)[1].strip()
+            m = LINE.match(line.strip())
+            if not m:
+                continue
+            key = " ".join(cp for cp in m.group(1).split() if cp != "FE0F")
+            names.setdefault(key, m.group(2))
+    with open(OUT, "w", encoding="utf-8") as f:
+        f.write("# Emoji %s sequence names\n" % version)
+        for key, name in names.items():
+            f.write("%s;%s\n" % (key, name))
+
+
+if __name__ == "__main__":
+    main()
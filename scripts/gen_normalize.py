@@ -0,0 +1,38 @@
+#!/usr/bin/env python3
+"""Regenerates internal/normalize/decompositions.txt from Python's unicodedata.
+
+Each line is CODE;TYPE;MAPPING;FLAGS where TYPE is "canonical" or the
+compatibility tag (e.g. "font", "compat"), MAPPING is the single-level
+decomposition and FLAGS is "x" when the character is excluded from
+canonical composition. Run from the repository root:
+
+    python3 scripts/gen_normalize.py
+"""
+import unicodedata
+
+OUT = "internal/normalize/decompositions.txt"
+
+
+def main():
+    lines = []
+    for cp in range(0x110000):
+        if 0xAC00 <= cp <= 0xD7A3:
+            continue  # Hangul syllables decompose algorithmically
+        ch = chr(cp)
+        decomposition = unicodedata.decomposition(ch)
+        if not decomposition:
+            continue
+        parts = decomposition.split()
+        kind = "canonical"
+        if parts[0].startswith("<"):
+            kind = parts.pop(0).strip("<>")
+        flags = ""
+        if kind == "canonical" and unicodedata.normalize("NFC", ch) != ch:
+            flags = "x"
+        lines.append("%04X;%s;%s;%s\n" % (cp, kind, " ".join(parts), flags))
+    with open(OUT, "w") as f:
+        f.writelines(lines)
+
+
+if __name__ == "__main__":
+    main()
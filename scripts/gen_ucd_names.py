@@ -0,0 +1,102 @@
+#!/usr/bin/env python3
+"""Regenerates internal/ucd/names.txt and internal/ucd/names_tables.go.
+
+Character names come from Python's unicodedata module, which carries the
+official Name property. Run from the repository root:
+
+    python3 scripts/gen_ucd_names.py
+"""
+import subprocess
+import unicodedata
+
+OUT = "internal/ucd"
+
+# Names that are just a prefix plus the hex code point are stored as ranges
+# instead of being listed one by one in names.txt.
+DERIVED = (
+    "CJK UNIFIED IDEOGRAPH-",
+    "CJK COMPATIBILITY IDEOGRAPH-",
+    "TANGUT IDEOGRAPH-",
+    "KHITAN SMALL SCRIPT CHARACTER-",
+    "NUSHU CHARACTER-",
+)
+
+# Control characters have no Name property; use their Name_Alias of type
+# "control" from NameAliases.txt instead.
+CONTROL_ALIASES = [
+    "NULL", "START OF HEADING", "START OF TEXT", "END OF TEXT",
+    "END OF TRANSMISSION", "ENQUIRY", "ACKNOWLEDGE", "ALERT", "BACKSPACE",
+    "CHARACTER TABULATION", "LINE FEED", "LINE TABULATION", "FORM FEED",
+    "CARRIAGE RETURN", "SHIFT OUT", "SHIFT IN", "DATA LINK ESCAPE",
+    "DEVICE CONTROL ONE", "DEVICE CONTROL TWO", "DEVICE CONTROL THREE",
+    "DEVICE CONTROL FOUR", "NEGATIVE ACKNOWLEDGE", "SYNCHRONOUS IDLE",
+    "END OF TRANSMISSION BLOCK", "CANCEL", "END OF MEDIUM", "SUBSTITUTE",
+    "ESCAPE", "INFORMATION SEPARATOR FOUR", "INFORMATION SEPARATOR THREE",
+    "INFORMATION SEPARATOR TWO", "INFORMATION SEPARATOR ONE",
+]
+C1_ALIASES = {
+    0x7F: "DELETE", 0x80: "PADDING CHARACTER", 0x81: "HIGH OCTET PRESET",
+    0x82: "BREAK PERMITTED HERE", 0x83: "NO BREAK HERE", 0x84: "INDEX",
+    0x85: "NEXT LINE", 0x86: "START OF SELECTED AREA",
+    0x87: "END OF SELECTED AREA", 0x88: "CHARACTER TABULATION SET",
+    0x89: "CHARACTER TABULATION WITH JUSTIFICATION",
+    0x8A: "LINE TABULATION SET", 0x8B: "PARTIAL LINE FORWARD",
+    0x8C: "PARTIAL LINE BACKWARD", 0x8D: "REVERSE LINE FEED",
+    0x8E: "SINGLE SHIFT TWO", 0x8F: "SINGLE SHIFT THREE",
+    0x90: "DEVICE CONTROL STRING", 0x91: "PRIVATE USE ONE",
+    0x92: "PRIVATE USE TWO", 0x93: "SET TRANSMIT STATE",
+    0x94: "CANCEL CHARACTER", 0x95: "MESSAGE WAITING",
+    0x96: "START OF GUARDED AREA", 0x97: "END OF GUARDED AREA",
+    0x98: "START OF STRING", 0x99: "SINGLE GRAPHIC CHARACTER INTRODUCER",
+    0x9A: "SINGLE CHARACTER INTRODUCER", 0x9B: "CONTROL SEQUENCE INTRODUCER",
+    0x9C: "STRING TERMINATOR", 0x9D: "OPERATING SYSTEM COMMAND",
+    0x9E: "PRIVACY MESSAGE", 0x9F: "APPLICATION PROGRAM COMMAND",
+}
+CONTROLS = {**dict(enumerate(CONTROL_ALIASES)), **C1_ALIASES}
+
+
+def official_name(cp):
+    if cp in CONTROLS:
+        return CONTROLS[cp]
+    ch = chr(cp)
+    name = unicodedata.name(ch, None)
+    # unicodedata leaves Tangut ideographs unnamed; their names are derived.
+    if name is None and unicodedata.category(ch) == "Lo" and (
+        0x17000 <= cp <= 0x187FF or 0x18D00 <= cp <= 0x18D7F
+    ):
+        name = "TANGUT IDEOGRAPH-%04X" % cp
+    return name
+
+
+def main():
+    names, ranges = [], []
+    for cp in range(0x110000):
+        if 0xAC00 <= cp <= 0xD7A3:
+            continue  # Hangul syllable names are computed
+        name = official_name(cp)
+        if name is None:
+            continue
+        prefix = next((p for p in DERIVED if name == p + "%04X" % cp), None)
+        if prefix is None:
+            names.append("%04X;%s\n" % (cp, name))
+        elif ranges and ranges[-1][1] == cp - 1 and ranges[-1][2] == prefix:
+            ranges[-1][1] = cp
+        else:
+            ranges.append([cp, cp, prefix])
+
+    with open(OUT + "/names.txt", "w") as f:
+        f.writelines(names)
+
+    with open(OUT + "/names_tables.go", "w") as f:
+        f.write("// Code generated by scripts/gen_ucd_names.py from Unicode %s; DO NOT EDIT.\n\n"
+                % unicodedata.unidata_version)
+        f.write("package ucd\n\n")
+        f.write("var derivedNameRanges = []derivedNameRange{\n")
+        for lo, hi, prefix in ranges:
+            f.write('\t{0x%04X, 0x%04X, "%s"},\n' % (lo, hi, prefix))
+        f.write("}\n")
+    subprocess.run(["gofmt", "-w", OUT + "/names_tables.go"], check=True)
+
+
+if __name__ == "__main__":
+    main()
@@ -0,0 +1,121 @@
+#!/usr/bin/env perl
+# Regenerates internal/ucd/tables.go from the Unicode Character Database that
+# ships with Perl (Unicode::UCD). Run from the repository root:
+#
+#   perl scripts/gen_ucd_tables.pl
+use strict;
+use warnings;
+use Unicode::UCD qw(prop_invmap prop_invlist);
+
+my $version = Unicode::UCD::UnicodeVersion();
+my $out = 'internal/ucd';
+
+open(my $go, '>', "$out/tables.go") or die "tables.go: $!";
+select $go;
+
+print "// Code generated by scripts/gen_ucd_tables.pl from Unicode $version; DO NOT EDIT.\n\n";
+print "package ucd\n\n";
+print "// UnicodeVersion is the Unicode Character Database version the tables were built from.\n";
+print "const UnicodeVersion = \"$version\"\n\n";
+
+# Emits a sorted []rangeValue table from an inversion map, dropping values
+# that map to the zero constant so lookups fall through to it.
+sub emit_map {
+    my ($var, $prop, $names, @skip) = @_;
+    my %skip = map { $_ => 1 } @skip;
+    my ($list, $map) = prop_invmap($prop);
+    print "var $var = []rangeValue{\n";
+    for my $i (0 .. $#$list) {
+        my $value = $map->[$i];
+        next if $skip{$value};
+        my $hi = $i < $#$list ? $list->[$i + 1] - 1 : 0x10FFFF;
+        my $const = $names->{$value} // die "no constant for $prop=$value";
+        printf "\t{0x%04X, 0x%04X, uint16(%s)},\n", $list->[$i], $hi, $const;
+    }
+    print "}\n\n";
+}
+
+# Emits a sorted []rangeValue table from a binary property inversion list.
+sub emit_list {
+    my ($var, $prop) = @_;
+    my @list = prop_invlist($prop);
+    print "var $var = []rangeValue{\n";
+    for (my $i = 0; $i < @list; $i += 2) {
+        my $hi = $i + 1 < @list ? $list[$i + 1] - 1 : 0x10FFFF;
+        printf "\t{0x%04X, 0x%04X, 1},\n", $list[$i], $hi;
+    }
+    print "}\n\n";
+}
+
+# Emits a numeric property as a range table holding the values directly,
+# dropping the default value.
+sub emit_numeric {
+    my ($var, $prop) = @_;
+    my ($list, $map, undef, $default) = prop_invmap($prop);
+    print "var $var = []rangeValue{\n";
+    for my $i (0 .. $#$list) {
+        next if $map->[$i] == $default;
+        my $hi = $i < $#$list ? $list->[$i + 1] - 1 : 0x10FFFF;
+        printf "\t{0x%04X, 0x%04X, %d},\n", $list->[$i], $hi, $map->[$i];
+    }
+    print "}\n\n";
+}
+
+# Emits a string-valued property as a value list plus a range table whose
+# values index into it. Index 0 is reserved for the default value.
+sub emit_enum {
+    my ($var, $prop) = @_;
+    my ($list, $map, undef, $default) = prop_invmap($prop);
+    my @values = ($default);
+    my %index = ($default => 0);
+    for my $value (sort @$map) {
+        next if exists $index{$value};
+        $index{$value} = scalar @values;
+        push @values, $value;
+    }
+    print "var ${var}Values = []string{\n";
+    print "\t\"$_\",\n" for @values;
+    print "}\n\n";
+    print "var ${var}Table = []rangeValue{\n";
+    for my $i (0 .. $#$list) {
+        next if $map->[$i] eq $default;
+        my $hi = $i < $#$list ? $list->[$i + 1] - 1 : 0x10FFFF;
+        printf "\t{0x%04X, 0x%04X, %d},\n", $list->[$i], $hi, $index{$map->[$i]};
+    }
+    print "}\n\n";
+}
+
+emit_map('graphemeBreakTable', 'Grapheme_Cluster_Break', {
+    'CR'                 => 'GraphemeCR',
+    'LF'                 => 'GraphemeLF',
+    'Control'            => 'GraphemeControl',
+    'Extend'             => 'GraphemeExtend',
+    'ZWJ'                => 'GraphemeZWJ',
+    'Regional_Indicator' => 'GraphemeRegionalIndicator',
+    'Prepend'            => 'GraphemePrepend',
+    'SpacingMark'        => 'GraphemeSpacingMark',
+    'L'                  => 'GraphemeL',
+    'V'                  => 'GraphemeV',
+    'T'                  => 'GraphemeT',
+    'LV'                 => 'GraphemeLV',
+    'LVT'                => 'GraphemeLVT',
+}, 'Other', 'ExtPict_XX');
+
+emit_list('extendedPictographicTable', 'Extended_Pictographic');
+
+emit_map('eastAsianWidthTable', 'East_Asian_Width', {
+    'A'  => 'WidthAmbiguous',
+    'F'  => 'WidthFullwidth',
+    'H'  => 'WidthHalfwidth',
+    'Na' => 'WidthNarrow',
+    'W'  => 'WidthWide',
+}, 'Neutral');
+
+emit_numeric('combiningClassTable', 'Canonical_Combining_Class');
+
+emit_enum('category', 'General_Category');
+emit_enum('script', 'Script');
+emit_enum('block', 'Block');
+
+close $go;
+system('gofmt', '-w', "$out/tables.go") == 0 or die "gofmt failed";